@@ -1,14 +1,16 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
-	"sync"
+	"net/http"
 	"time"
 
 	scrapers "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
@@ -20,38 +22,40 @@ func init() {
 	log = logrus.New()
 }
 
-func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w *kafka.Writer, exchange string) {
-	lastTradeTime := time.Now()
-	watchdogDelay := scrapers.Exchanges[exchange].WatchdogDelay
-	t := time.NewTicker(time.Duration(watchdogDelay) * time.Second)
-	for {
-		select {
-		case <-t.C:
-			duration := time.Since(lastTradeTime)
-			if duration > time.Duration(watchdogDelay)*time.Second {
-				log.Error(duration)
-				panic("frozen? ")
-			}
-		case t, ok := <-c:
-			if !ok {
-				wg.Done()
-				log.Error("handleTrades")
-				return
-			}
-			lastTradeTime = time.Now()
-			if t.Time.Before(lastTradeTime) && t.Price >= 0 {
-				kafkaHelper.WriteMessage(w, t)
+// handleTrades forwards trades to kafka, dropping duplicates. Liveness of the scraper
+// is watched by the supervisor.
+func handleTrades(c chan *dia.Trade, w *kafka.Writer, dedup *deduplicator) {
+	for t := range c {
+		metrics.TradesReceived.WithLabelValues(t.Source, t.Pair).Inc()
+		if t.Time.Before(time.Now()) && t.Price >= 0 && !dedup.isDuplicate(t, time.Now()) {
+			if err := kafkaHelper.WriteMessage(w, t); err != nil {
+				continue
 			}
+			metrics.TradeKafkaLatency.WithLabelValues(t.Source).Observe(time.Since(t.Time).Seconds())
 		}
 	}
 }
 
+// handleOrderBooks forwards order book snapshots to kafka
+func handleOrderBooks(c chan *dia.OrderBookSnapshot, w *kafka.Writer) {
+	for ob := range c {
+		kafkaHelper.WriteMessage(w, ob)
+	}
+}
+
 var (
 	exchange         = flag.String("exchange", "", "which exchange")
 	onePairPerSymbol = flag.Bool("onePairPerSymbol", false, "one Pair max Per Symbol ?")
+	orderBooks       = flag.Bool("orderBooks", true, "capture order books if the exchange's scraper supports it")
+	statusAddr       = flag.String("statusAddr", "", "address to serve the restart counts and last errors of all pairs on, e.g. :8080")
+	metricsAddr      = flag.String("metricsAddr", ":9090", "address to serve the prometheus metrics on /metrics, empty to disable")
+	backfillFrom     = flag.Uint64("backfillFrom", 0, "first block of a historical range to backfill, for on-chain exchanges")
+	backfillTo       = flag.Uint64("backfillTo", 0, "last block of a historical range to backfill, for on-chain exchanges")
+	dedupWindow      = flag.Duration("dedupWindow", time.Hour, "time for which trades are remembered to drop duplicates")
 )
 
-func init() {
+// main manages all PairScrapers and handles incoming trade information
+func main() {
 	flag.Parse()
 	if *exchange == "" {
 		flag.Usage()
@@ -61,10 +65,6 @@ func init() {
 		}
 		// log.Fatal("exchange is required")
 	}
-}
-
-// main manages all PairScrapers and handles incoming trade information
-func main() {
 
 	ds, err := models.NewRedisDataStore()
 	if err != nil {
@@ -80,16 +80,18 @@ func main() {
 		pairsExchange = cc.AllPairs()
 	}
 
-	configApi, err := dia.GetConfig(*exchange)
-	if err != nil {
-		log.Warning("no config for exchange's api ", err)
+	configApi := &dia.ConfigApi{}
+	if scrapers.Exchanges[*exchange].RequiresAPIKey {
+		configApi, err = dia.GetConfig(*exchange)
+		if err != nil {
+			log.Warning("no config for exchange's api ", err)
+		}
 	}
-	es := scrapers.NewAPIScraper(*exchange, configApi.ApiKey, configApi.SecretKey)
-
-	w := kafkaHelper.NewWriter(kafkaHelper.TopicTrades)
-	defer w.Close()
-
-	wg := sync.WaitGroup{}
+	newScraper := func() scrapers.APIScraper {
+		return scrapers.NewAPIScraper(*exchange, configApi.ApiKey, configApi.SecretKey)
+	}
+	watchdogDelay := time.Duration(scrapers.Exchanges[*exchange].WatchdogDelay) * time.Second
+	sup := newSupervisor(*exchange, newScraper, watchdogDelay, *orderBooks)
 
 	pairs := make(map[string]string)
 
@@ -103,16 +105,64 @@ func main() {
 			log.Println("Skipping pair:", configPair.Symbol, configPair.ForeignName, "on exchange", *exchange)
 		} else {
 			log.Println("Adding pair:", configPair.Symbol, configPair.ForeignName, "on exchange", *exchange)
-			_, err := es.ScrapePair(dia.Pair{
+			sup.addPair(dia.Pair{
 				Symbol:      configPair.Symbol,
 				ForeignName: configPair.ForeignName})
-			if err != nil {
-				log.Println(err)
-			} else {
-				wg.Add(1)
-			}
 		}
-		defer wg.Wait()
 	}
-	go handleTrades(es.Channel(), &wg, w, *exchange)
+
+	if err := sup.start(time.Now()); err != nil {
+		log.Fatal(err)
+	}
+
+	var store tradeSeenStore
+	if ds != nil {
+		store = ds
+	}
+	dedup := newDeduplicator(*dedupWindow, maxLocalTrades, store)
+	go dedup.logDropped(10 * time.Minute)
+
+	w := kafkaHelper.NewWriter(kafkaHelper.TopicTrades)
+	defer w.Close()
+	go handleTrades(sup.trades, w, dedup)
+
+	wOrderBooks := kafkaHelper.NewWriter(kafkaHelper.TopicOrderBooks)
+	defer wOrderBooks.Close()
+	go handleOrderBooks(sup.orderBookC, wOrderBooks)
+
+	if *statusAddr != "" {
+		go serveStatus(*statusAddr, sup, dedup)
+	}
+	if *metricsAddr != "" {
+		go metrics.Serve(*metricsAddr)
+	}
+
+	if *backfillTo > 0 {
+		go func() {
+			if err := sup.backfill(*backfillFrom, *backfillTo); err != nil {
+				log.Error("backfill: ", err)
+			}
+		}()
+	}
+
+	sup.run()
+}
+
+// collectorStatus is served on /status.
+type collectorStatus struct {
+	SupervisorStatus
+	// number of dropped duplicate trades per exchange
+	DuplicatesDropped map[string]int64
+}
+
+// serveStatus serves the supervisor's status and the dropped duplicates as json on @addr.
+func serveStatus(addr string, sup *supervisor, dedup *deduplicator) {
+	http.HandleFunc("/status", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "application/json")
+		status := collectorStatus{SupervisorStatus: sup.Status(), DuplicatesDropped: dedup.Dropped()}
+		if err := json.NewEncoder(rw).Encode(status); err != nil {
+			log.Error("encode status: ", err)
+		}
+	})
+	log.Error(http.ListenAndServe(addr, nil))
 }
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// maximal number of trades remembered locally by the deduplicator
+const maxLocalTrades = 100000
+
+// tradeSeenStore records trades for all replicas of a collector.
+type tradeSeenStore interface {
+	MarkTradeSeen(t *dia.Trade, window time.Duration) (bool, error)
+}
+
+type seenTrade struct {
+	key  string
+	time time.Time
+}
+
+// deduplicator drops trades whose ForeignTradeID was seen within the window before,
+// be it by this collector or, through the shared store, by one of its replicas.
+// Trades without ForeignTradeID are always passed on.
+type deduplicator struct {
+	window   time.Duration
+	maxLocal int
+	store    tradeSeenStore
+
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	order   []seenTrade // seen trades in the order they arrived, for expiry
+	dropped map[string]int64
+}
+
+// newDeduplicator returns a deduplicator remembering trades for @window. @store may be nil.
+func newDeduplicator(window time.Duration, maxLocal int, store tradeSeenStore) *deduplicator {
+	return &deduplicator{
+		window:   window,
+		maxLocal: maxLocal,
+		store:    store,
+		seen:     make(map[string]time.Time),
+		dropped:  make(map[string]int64),
+	}
+}
+
+// isDuplicate records @t and reports whether it was seen before.
+func (d *deduplicator) isDuplicate(t *dia.Trade, now time.Time) bool {
+	if t.ForeignTradeID == "" {
+		return false
+	}
+	key := t.Source + "_" + t.Pair + "_" + t.ForeignTradeID
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.expire(now)
+	if _, ok := d.seen[key]; ok {
+		d.dropped[t.Source]++
+		return true
+	}
+	d.seen[key] = now
+	d.order = append(d.order, seenTrade{key: key, time: now})
+
+	if d.store != nil {
+		isNew, err := d.store.MarkTradeSeen(t, d.window)
+		if err != nil {
+			// rather forward a duplicate than lose a trade
+			log.Warn("mark trade as seen: ", err)
+			return false
+		}
+		if !isNew {
+			d.dropped[t.Source]++
+			return true
+		}
+	}
+	return false
+}
+
+// expire forgets trades older than the window and the oldest trades beyond maxLocal.
+// must be called with d.mu held
+func (d *deduplicator) expire(now time.Time) {
+	i := 0
+	for ; i < len(d.order); i++ {
+		if now.Sub(d.order[i].time) < d.window && len(d.order)-i < d.maxLocal {
+			break
+		}
+		if d.seen[d.order[i].key] == d.order[i].time {
+			delete(d.seen, d.order[i].key)
+		}
+	}
+	d.order = d.order[i:]
+}
+
+// Dropped returns the number of dropped duplicates per exchange.
+func (d *deduplicator) Dropped() map[string]int64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	dropped := make(map[string]int64, len(d.dropped))
+	for exchange, n := range d.dropped {
+		dropped[exchange] = n
+	}
+	return dropped
+}
+
+// logDropped logs the number of dropped duplicates per exchange every @interval.
+func (d *deduplicator) logDropped(interval time.Duration) {
+	for range time.Tick(interval) {
+		for exchange, n := range d.Dropped() {
+			log.Infof("dropped %d duplicate trades from %s", n, exchange)
+		}
+	}
+}
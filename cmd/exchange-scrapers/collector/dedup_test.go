@@ -0,0 +1,56 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// fakeSeenStore stands in for the redis store shared by collector replicas.
+type fakeSeenStore map[string]bool
+
+func (s fakeSeenStore) MarkTradeSeen(t *dia.Trade, window time.Duration) (bool, error) {
+	key := t.Source + t.Pair + t.ForeignTradeID
+	if s[key] {
+		return false, nil
+	}
+	s[key] = true
+	return true, nil
+}
+
+func TestDeduplicator(t *testing.T) {
+	store := fakeSeenStore{}
+	replica := newDeduplicator(time.Minute, 10, store)
+	d := newDeduplicator(time.Minute, 2, store)
+	t0 := time.Now()
+
+	trade := &dia.Trade{Source: "BitBay", Pair: "BTC-PLN", ForeignTradeID: "1"}
+	if d.isDuplicate(trade, t0) {
+		t.Fatal("first trade reported as duplicate")
+	}
+	if !d.isDuplicate(trade, t0) {
+		t.Error("repeated trade passed")
+	}
+	if !replica.isDuplicate(trade, t0) {
+		t.Error("trade seen by another replica passed")
+	}
+	if d.isDuplicate(&dia.Trade{Source: "BitBay", Pair: "ETH-PLN", ForeignTradeID: "1"}, t0) {
+		t.Error("same ForeignTradeID on another pair reported as duplicate")
+	}
+	if d.isDuplicate(&dia.Trade{Source: "BitBay", Pair: "BTC-PLN"}, t0) || d.isDuplicate(&dia.Trade{Source: "BitBay", Pair: "BTC-PLN"}, t0) {
+		t.Error("trade without ForeignTradeID reported as duplicate")
+	}
+
+	// the local memory is bounded by size and window
+	d.isDuplicate(&dia.Trade{Source: "BitBay", Pair: "BTC-PLN", ForeignTradeID: "2"}, t0)
+	d.isDuplicate(&dia.Trade{Source: "BitBay", Pair: "BTC-PLN", ForeignTradeID: "3"}, t0.Add(2*time.Minute))
+	if len(d.seen) > 2 {
+		t.Errorf("%d trades remembered locally, want at most 2", len(d.seen))
+	}
+
+	dropped := d.Dropped()
+	if dropped["BitBay"] != 1 || replica.Dropped()["BitBay"] != 1 {
+		t.Errorf("unexpected drop counts %v and %v", dropped, replica.Dropped())
+	}
+}
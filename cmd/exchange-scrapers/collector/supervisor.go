@@ -0,0 +1,463 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	scrapers "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
+)
+
+const (
+	minRestartBackoff = 10 * time.Second
+	maxRestartBackoff = 2 * time.Hour
+	// time granted to a scraper for closing before it is abandoned
+	closeTimeout = time.Minute
+)
+
+// pairState keeps track of the liveness of a single pair.
+type pairState struct {
+	pair        dia.Pair
+	scraper     scrapers.PairScraper
+	lastTrade   time.Time
+	failures    int // consecutive restarts without a trade in between
+	restarts    int
+	lastError   error
+	nextRestart time.Time
+	stale       bool // stopped or silent at the last check
+}
+
+// PairStatus is the state of a supervised pair.
+type PairStatus struct {
+	Pair      string
+	Symbol    string
+	Restarts  int
+	LastError string
+	LastTrade time.Time
+	Stale     bool
+}
+
+// SupervisorStatus is the state of the exchange connection and of all supervised pairs.
+type SupervisorStatus struct {
+	Exchange  string
+	Restarts  int
+	LastError string
+	LastTrade time.Time
+	Pairs     []PairStatus
+}
+
+// supervisor keeps the pairs of an exchange alive. Pairs which stopped or did not
+// trade within the watchdog delay are restarted individually if they are not subscribed
+// or their scraper can end their subscription. Other stale pairs are only reported, as
+// a quiet pair may just be illiquid. If the whole exchange falls silent or all pairs
+// report an error, the connection to the exchange is replaced by a new scraper.
+// Restarts are delayed by an exponential backoff.
+type supervisor struct {
+	exchange      string
+	newScraper    func() scrapers.APIScraper
+	watchdogDelay time.Duration
+	orderBooks    bool
+
+	mu                  sync.Mutex
+	es                  scrapers.APIScraper
+	done                chan struct{}         // stops the forwarders of the current connection
+	pairs               map[string]*pairState // pair.ForeignName -> state
+	aliases             map[string]string     // trade.Pair -> pair.ForeignName
+	lastTrade           time.Time
+	failures            int
+	restarts            int
+	lastError           error
+	nextExchangeRestart time.Time
+
+	trades     chan *dia.Trade
+	orderBookC chan *dia.OrderBookSnapshot
+}
+
+func newSupervisor(exchange string, newScraper func() scrapers.APIScraper, watchdogDelay time.Duration, orderBooks bool) *supervisor {
+	return &supervisor{
+		exchange:      exchange,
+		newScraper:    newScraper,
+		watchdogDelay: watchdogDelay,
+		orderBooks:    orderBooks,
+		pairs:         make(map[string]*pairState),
+		aliases:       make(map[string]string),
+		trades:        make(chan *dia.Trade),
+		orderBookC:    make(chan *dia.OrderBookSnapshot),
+	}
+}
+
+// backoff returns the time to wait before the next restart after @failures consecutive failures.
+func backoff(failures int) time.Duration {
+	d := minRestartBackoff
+	for i := 1; i < failures && d < maxRestartBackoff; i++ {
+		d *= 2
+	}
+	if d > maxRestartBackoff {
+		d = maxRestartBackoff
+	}
+	return d
+}
+
+// addPair adds @pair to the supervised pairs. Must be called before start.
+func (s *supervisor) addPair(pair dia.Pair) {
+	s.pairs[pair.ForeignName] = &pairState{pair: pair}
+}
+
+// start connects to the exchange and subscribes to all pairs.
+func (s *supervisor) start(now time.Time) error {
+	s.mu.Lock()
+	s.lastTrade = now
+	for _, p := range s.pairs {
+		p.lastTrade = now
+	}
+	s.mu.Unlock()
+	return s.connect(now)
+}
+
+// connect creates a new scraper and subscribes to all pairs on it.
+// Must be called without s.mu held, as subscribing blocks on network I/O
+// which must not stall the forwarding of trades.
+func (s *supervisor) connect(now time.Time) error {
+	es := s.newScraper()
+	if es == nil {
+		return errors.New("no scraper registered for exchange " + s.exchange)
+	}
+	done := make(chan struct{})
+
+	obs, captureOrderBooks := es.(scrapers.OrderBookScraper)
+	captureOrderBooks = captureOrderBooks && s.orderBooks
+
+	s.mu.Lock()
+	s.es = es
+	s.done = done
+	pairs := make(map[string]dia.Pair, len(s.pairs))
+	for key, p := range s.pairs {
+		if normalized, err := es.NormalizePair(p.pair); err == nil {
+			s.aliases[normalized.ForeignName] = key
+		}
+		pairs[key] = p.pair
+	}
+	s.mu.Unlock()
+
+	go s.forwardTrades(es, done)
+	if captureOrderBooks {
+		go s.forwardOrderBooks(obs, done)
+	}
+
+	for key, pair := range pairs {
+		ps, err := es.ScrapePair(pair)
+		if err != nil {
+			log.Errorf("ScrapePair %s on %s: %v", key, s.exchange, err)
+			ps = nil
+		}
+		if captureOrderBooks {
+			if err := obs.ScrapeOrderBook(pair); err != nil {
+				log.Errorln("ScrapeOrderBook", err)
+			}
+		}
+
+		s.mu.Lock()
+		p := s.pairs[key]
+		p.scraper, p.lastError = ps, err
+		if p.nextRestart.Before(now) {
+			p.nextRestart = now.Add(backoff(p.failures))
+		}
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (s *supervisor) forwardTrades(es scrapers.APIScraper, done chan struct{}) {
+	for {
+		select {
+		case t, ok := <-es.Channel():
+			if !ok {
+				return
+			}
+			s.registerTrade(t, time.Now())
+			select {
+			case s.trades <- t:
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+func (s *supervisor) forwardOrderBooks(obs scrapers.OrderBookScraper, done chan struct{}) {
+	for {
+		select {
+		case ob, ok := <-obs.OrderBookChannel():
+			if !ok {
+				return
+			}
+			select {
+			case s.orderBookC <- ob:
+			case <-done:
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// registerTrade marks the exchange and the pair of @t as alive.
+func (s *supervisor) registerTrade(t *dia.Trade, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastTrade = now
+	s.failures = 0
+	key, ok := s.aliases[t.Pair]
+	if !ok {
+		key = t.Pair
+	}
+	if p, ok := s.pairs[key]; ok {
+		p.lastTrade = now
+		p.failures = 0
+		p.stale = false
+	}
+}
+
+// check restarts the exchange connection or single pairs where needed.
+// A subscribed pair is only restarted if its subscription can be ended, otherwise
+// it is reported as stale until it trades again or the connection is replaced.
+func (s *supervisor) check(now time.Time) {
+	s.mu.Lock()
+
+	// find the pairs which stopped or fell silent
+	stale := make(map[*pairState]error)
+	errored := 0
+	for _, p := range s.pairs {
+		if p.scraper == nil {
+			stale[p] = errors.New("not subscribed")
+			if p.lastError != nil {
+				stale[p] = p.lastError
+			}
+			errored++
+			continue
+		}
+		if err := p.scraper.Error(); err != nil {
+			stale[p] = err
+			errored++
+			continue
+		}
+		if silence := now.Sub(p.lastTrade); silence > s.watchdogDelay {
+			stale[p] = fmt.Errorf("no trade for %v", silence.Round(time.Second))
+		}
+	}
+
+	var reason error
+	if silence := now.Sub(s.lastTrade); silence > s.watchdogDelay {
+		reason = fmt.Errorf("no trade on exchange for %v", silence.Round(time.Second))
+	} else if len(s.pairs) > 0 && errored == len(s.pairs) {
+		for _, err := range stale {
+			reason = err
+			break
+		}
+	}
+
+	// pairs which fell stale since the last check
+	newlyStale := make(map[*pairState]bool)
+	for _, p := range s.pairs {
+		_, isStale := stale[p]
+		newlyStale[p] = isStale && !p.stale
+		p.stale = isStale
+	}
+	metrics.StalePairs.WithLabelValues(s.exchange).Set(float64(len(stale)))
+
+	var restart []*pairState
+	if reason == nil {
+		for p, err := range stale {
+			if _, ok := p.scraper.(scrapers.UnsubscribingPairScraper); p.scraper != nil && !ok {
+				// the pair stays subscribed until the connection is replaced
+				if newlyStale[p] {
+					log.Warnf("pair %s on %s is stale: %v", p.pair.ForeignName, s.exchange, err)
+				}
+				p.lastError = err
+				continue
+			}
+			if now.Before(p.nextRestart) {
+				continue
+			}
+			restart = append(restart, p)
+			s.markPairRestart(p, err, now)
+		}
+	}
+
+	if reason != nil {
+		if now.Before(s.nextExchangeRestart) {
+			s.mu.Unlock()
+			return
+		}
+		old := s.markExchangeRestart(reason, now)
+		s.mu.Unlock()
+		if old != nil {
+			go closeScraper(old)
+		}
+		if err := s.connect(now); err != nil {
+			log.Error(err)
+			s.mu.Lock()
+			s.lastError = err
+			s.mu.Unlock()
+		}
+		return
+	}
+
+	es := s.es
+	s.mu.Unlock()
+	for _, p := range restart {
+		s.restartPair(es, p)
+	}
+}
+
+// markPairRestart accounts a restart of @p due to @reason.
+// must be called with s.mu held
+func (s *supervisor) markPairRestart(p *pairState, reason error, now time.Time) {
+	p.restarts++
+	p.failures++
+	metrics.WatchdogRestarts.WithLabelValues(s.exchange, "pair").Inc()
+	p.lastError = reason
+	p.nextRestart = now.Add(backoff(p.failures))
+	log.Warnf("restarting pair %s on %s (restart %d, next earliest in %v): %v", p.pair.ForeignName, s.exchange, p.restarts, backoff(p.failures), reason)
+}
+
+// restartPair ends the subscription of @p, if any, and subscribes it again on @es.
+// Must be called without s.mu held.
+func (s *supervisor) restartPair(es scrapers.APIScraper, p *pairState) {
+	s.mu.Lock()
+	old := p.scraper
+	p.scraper = nil
+	s.mu.Unlock()
+
+	if ups, ok := old.(scrapers.UnsubscribingPairScraper); ok {
+		if err := ups.Unsubscribe(); err != nil {
+			log.Warnf("unsubscribe pair %s: %v", p.pair.ForeignName, err)
+		}
+	}
+	if es == nil {
+		return
+	}
+	ps, err := es.ScrapePair(p.pair)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		log.Errorf("ScrapePair %s on %s: %v", p.pair.ForeignName, s.exchange, err)
+		p.lastError = err
+		return
+	}
+	p.scraper = ps
+}
+
+// markExchangeRestart accounts a restart of the exchange connection due to @reason
+// and detaches the current scraper, which is returned for closing.
+// must be called with s.mu held
+func (s *supervisor) markExchangeRestart(reason error, now time.Time) scrapers.APIScraper {
+	s.restarts++
+	s.failures++
+	metrics.WatchdogRestarts.WithLabelValues(s.exchange, "exchange").Inc()
+	s.lastError = reason
+	s.nextExchangeRestart = now.Add(backoff(s.failures))
+	log.Warnf("restarting connection to %s (restart %d, next earliest in %v): %v", s.exchange, s.restarts, backoff(s.failures), reason)
+
+	old := s.es
+	if old != nil {
+		close(s.done)
+		s.es = nil
+	}
+	for _, p := range s.pairs {
+		p.scraper = nil
+	}
+	return old
+}
+
+// closeScraper closes @es in the background. Its channels are drained meanwhile,
+// as scrapers blocked on sending a trade might never notice the shutdown.
+func closeScraper(es scrapers.APIScraper) {
+	closed := make(chan error, 1)
+	go func() {
+		closed <- es.Close()
+	}()
+	var orderBooks chan *dia.OrderBookSnapshot
+	if obs, ok := es.(scrapers.OrderBookScraper); ok {
+		orderBooks = obs.OrderBookChannel()
+	}
+	timeout := time.After(closeTimeout)
+	for {
+		select {
+		case <-es.Channel():
+		case <-orderBooks:
+		case err := <-closed:
+			if err != nil {
+				log.Warn("close scraper: ", err)
+			}
+			return
+		case <-timeout:
+			log.Warn("close scraper: timeout, abandoning it")
+			return
+		}
+	}
+}
+
+// backfill emits the trades of the blocks in [@startBlock, @endBlock] on the current connection.
+func (s *supervisor) backfill(startBlock uint64, endBlock uint64) error {
+	s.mu.Lock()
+	es := s.es
+	s.mu.Unlock()
+	bs, ok := es.(scrapers.BackfillScraper)
+	if !ok {
+		return errors.New("no backfill available for exchange " + s.exchange)
+	}
+	return bs.Backfill(startBlock, endBlock)
+}
+
+// run checks the liveness of the exchange and its pairs until the process ends.
+func (s *supervisor) run() {
+	interval := s.watchdogDelay / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		s.check(now)
+	}
+}
+
+// Status returns the restart count and last error of the exchange connection and of each pair.
+func (s *supervisor) Status() SupervisorStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := SupervisorStatus{
+		Exchange:  s.exchange,
+		Restarts:  s.restarts,
+		LastError: errorString(s.lastError),
+		LastTrade: s.lastTrade,
+	}
+	for _, p := range s.pairs {
+		status.Pairs = append(status.Pairs, PairStatus{
+			Pair:      p.pair.ForeignName,
+			Symbol:    p.pair.Symbol,
+			Restarts:  p.restarts,
+			LastError: errorString(p.lastError),
+			LastTrade: p.lastTrade,
+			Stale:     p.stale,
+		})
+	}
+	sort.Slice(status.Pairs, func(i, j int) bool { return status.Pairs[i].Pair < status.Pairs[j].Pair })
+	return status
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
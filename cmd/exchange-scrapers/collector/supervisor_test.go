@@ -0,0 +1,197 @@
+package main
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	scrapers "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type fakePairScraper struct {
+	pair         dia.Pair
+	err          error
+	closed       bool
+	unsubscribed bool
+}
+
+func (ps *fakePairScraper) Close() error   { ps.closed = true; return nil }
+func (ps *fakePairScraper) Error() error   { return ps.err }
+func (ps *fakePairScraper) Pair() dia.Pair { return ps.pair }
+
+type fakeUnsubscribingPairScraper struct {
+	*fakePairScraper
+}
+
+func (ps fakeUnsubscribingPairScraper) Unsubscribe() error { ps.unsubscribed = true; return nil }
+
+type fakeScraper struct {
+	trades      chan *dia.Trade
+	scraped     map[string][]*fakePairScraper
+	closed      chan struct{}
+	unsubscribe bool // pair scrapers can end their subscription
+}
+
+func newFakeScraper() *fakeScraper {
+	return &fakeScraper{
+		trades:  make(chan *dia.Trade),
+		scraped: make(map[string][]*fakePairScraper),
+		closed:  make(chan struct{}),
+	}
+}
+
+func (s *fakeScraper) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func (s *fakeScraper) ScrapePair(pair dia.Pair) (scrapers.PairScraper, error) {
+	ps := &fakePairScraper{pair: pair}
+	s.scraped[pair.ForeignName] = append(s.scraped[pair.ForeignName], ps)
+	if s.unsubscribe {
+		return fakeUnsubscribingPairScraper{ps}, nil
+	}
+	return ps, nil
+}
+
+func (s *fakeScraper) FetchAvailablePairs() ([]dia.Pair, error)      { return nil, nil }
+func (s *fakeScraper) NormalizePair(pair dia.Pair) (dia.Pair, error) { return pair, nil }
+func (s *fakeScraper) Channel() chan *dia.Trade                      { return s.trades }
+
+func TestBackoff(t *testing.T) {
+	cases := map[int]time.Duration{
+		0:  minRestartBackoff,
+		1:  minRestartBackoff,
+		2:  2 * minRestartBackoff,
+		4:  8 * minRestartBackoff,
+		50: maxRestartBackoff,
+	}
+	for failures, want := range cases {
+		if got := backoff(failures); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", failures, got, want)
+		}
+	}
+}
+
+func TestSupervisorRestartsSilentPair(t *testing.T) {
+	es := newFakeScraper()
+	es.unsubscribe = true
+	sup := newSupervisor("Fake", func() scrapers.APIScraper { return es }, time.Minute, false)
+	sup.addPair(dia.Pair{Symbol: "BTC", ForeignName: "BTCUSD"})
+	sup.addPair(dia.Pair{Symbol: "ETH", ForeignName: "ETHUSD"})
+
+	t0 := time.Now()
+	if err := sup.start(t0); err != nil {
+		t.Fatal(err)
+	}
+	sup.registerTrade(&dia.Trade{Pair: "BTCUSD"}, t0.Add(50*time.Second))
+
+	sup.check(t0.Add(70 * time.Second))
+	if n := len(es.scraped["ETHUSD"]); n != 2 {
+		t.Errorf("silent pair scraped %d times, want 2", n)
+	}
+	if !es.scraped["ETHUSD"][0].unsubscribed {
+		t.Error("silent pair not unsubscribed before restart")
+	}
+	if n := len(es.scraped["BTCUSD"]); n != 1 {
+		t.Errorf("live pair scraped %d times, want 1", n)
+	}
+
+	// the next restart is delayed by the backoff
+	sup.check(t0.Add(75 * time.Second))
+	if n := len(es.scraped["ETHUSD"]); n != 2 {
+		t.Errorf("silent pair restarted within backoff")
+	}
+
+	status := sup.Status()
+	if status.Restarts != 0 || len(status.Pairs) != 2 {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	eth := status.Pairs[1]
+	if eth.Pair != "ETHUSD" || eth.Restarts != 1 || !strings.Contains(eth.LastError, "no trade") {
+		t.Errorf("unexpected pair status %+v", eth)
+	}
+}
+
+func TestSupervisorReportsPairWithoutUnsubscribe(t *testing.T) {
+	var connections []*fakeScraper
+	newScraper := func() scrapers.APIScraper {
+		es := newFakeScraper()
+		connections = append(connections, es)
+		return es
+	}
+	sup := newSupervisor("Fake", newScraper, time.Minute, false)
+	sup.addPair(dia.Pair{Symbol: "BTC", ForeignName: "BTCUSD"})
+	sup.addPair(dia.Pair{Symbol: "ETH", ForeignName: "ETHUSD"})
+
+	t0 := time.Now()
+	if err := sup.start(t0); err != nil {
+		t.Fatal(err)
+	}
+	sup.registerTrade(&dia.Trade{Pair: "BTCUSD"}, t0.Add(50*time.Second))
+
+	// a quiet pair which cannot be restarted on its own is only reported
+	sup.check(t0.Add(70 * time.Second))
+	if len(connections) != 1 || len(connections[0].scraped["ETHUSD"]) != 1 {
+		t.Fatalf("quiet pair restarted, %d connections", len(connections))
+	}
+	if n := testutil.ToFloat64(metrics.StalePairs.WithLabelValues("Fake")); n != 1 {
+		t.Errorf("stale pairs metric %v, want 1", n)
+	}
+	status := sup.Status()
+	eth := status.Pairs[1]
+	if status.Restarts != 0 || eth.Restarts != 0 || !eth.Stale || !strings.Contains(eth.LastError, "no trade") {
+		t.Errorf("unexpected status %+v", status)
+	}
+
+	// the silence of the whole exchange still restarts the connection
+	sup.check(t0.Add(120 * time.Second))
+	if len(connections) != 2 {
+		t.Fatalf("exchange connected %d times, want 2", len(connections))
+	}
+}
+
+func TestSupervisorRestartsExchange(t *testing.T) {
+	var connections []*fakeScraper
+	newScraper := func() scrapers.APIScraper {
+		es := newFakeScraper()
+		connections = append(connections, es)
+		return es
+	}
+	sup := newSupervisor("Fake", newScraper, time.Minute, false)
+	sup.addPair(dia.Pair{Symbol: "BTC", ForeignName: "BTCUSD"})
+
+	t0 := time.Now()
+	if err := sup.start(t0); err != nil {
+		t.Fatal(err)
+	}
+	sup.registerTrade(&dia.Trade{Pair: "BTCUSD"}, t0)
+	connections[0].scraped["BTCUSD"][0].err = errors.New("connection lost")
+	restarts := metrics.WatchdogRestarts.WithLabelValues("Fake", "exchange")
+	restartsBefore := testutil.ToFloat64(restarts)
+
+	sup.check(t0.Add(time.Second))
+	if len(connections) != 2 {
+		t.Fatalf("exchange connected %d times, want 2", len(connections))
+	}
+	select {
+	case <-connections[0].closed:
+	case <-time.After(time.Second):
+		t.Error("old connection not closed")
+	}
+	if n := len(connections[1].scraped["BTCUSD"]); n != 1 {
+		t.Errorf("pair scraped %d times on new connection, want 1", n)
+	}
+
+	status := sup.Status()
+	if status.Restarts != 1 || status.LastError != "connection lost" {
+		t.Errorf("unexpected status %+v", status)
+	}
+	if n := testutil.ToFloat64(restarts) - restartsBefore; n != 1 {
+		t.Errorf("restart metric increased by %v, want 1", n)
+	}
+}
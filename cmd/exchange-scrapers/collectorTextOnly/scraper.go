@@ -1,11 +1,17 @@
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 
 	scrapers "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
+	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
 	log "github.com/sirupsen/logrus"
@@ -13,20 +19,43 @@ import (
 
 // pairs contains all pairs currently supported by the DIA scrapers
 
-// handleTrades delegates trade information to Kafka
-func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup) {
+// handleTrades logs the trades or, if a writer is given, writes them to files
+func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w writers.Writer) {
 	for {
 		t, ok := <-c
 		if !ok {
 			log.Error("error")
 			return
 		}
-		log.Printf("handleTrades: %v\n", t)
+		if w == nil {
+			log.Printf("handleTrades: %v\n", t)
+			continue
+		}
+		err := writeTrade(w, t)
+		if err != nil {
+			log.Error("write trade: ", err)
+		}
 	}
 }
 
+// writeTrade writes @t in the schema of archives, or as json line to text files.
+func writeTrade(w writers.Writer, t *dia.Trade) error {
+	if archive, ok := w.(*writers.ArchiveWriter); ok {
+		return archive.WriteTrade(t)
+	}
+	line, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(string(line)+"\n", w.GetWriteFileName(t.Source, t.Pair))
+	return err
+}
+
 var (
-	exchange = flag.String("exchange", "", "which exchange")
+	exchange        = flag.String("exchange", "", "which exchange")
+	writer          = flag.String("writer", "", "write trades to files instead of the log: text or archive (gzip-compressed csv)")
+	writerDir       = flag.String("writerDir", ".", "directory of the files written")
+	archiveMaxBytes = flag.Int64("archiveMaxBytes", 256<<20, "size at which archive files are rotated")
 )
 
 func init() {
@@ -43,6 +72,16 @@ func main() {
 
 	s := map[string]scrapers.APIScraper{}
 
+	var w writers.Writer
+	if *writer != "" {
+		var err error
+		w, err = writers.NewWriter(*writer, *writerDir, *archiveMaxBytes)
+		if err != nil {
+			log.Fatal(err)
+		}
+		go closeOnSignal(w)
+	}
+
 	cc := configCollectors.NewConfigCollectors(*exchange, ".json")
 
 	wg := sync.WaitGroup{}
@@ -61,7 +100,7 @@ func main() {
 			aPIScraper := scrapers.NewAPIScraper(configPair.Exchange, configExchangeApi.ApiKey, configExchangeApi.SecretKey)
 			if s != nil {
 				s[configPair.Exchange] = aPIScraper
-				go handleTrades(aPIScraper.Channel(), &wg)
+				go handleTrades(aPIScraper.Channel(), &wg, w)
 			} else {
 				fmt.Println("Couldn't create APIScraper for ", configPair.Exchange)
 			}
@@ -80,3 +119,17 @@ func main() {
 	}
 	defer wg.Wait()
 }
+
+// closeOnSignal completes the files of @w and exits on SIGINT or SIGTERM.
+func closeOnSignal(w writers.Writer) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	<-sigs
+	if closer, ok := w.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil {
+			log.Error("close files: ", err)
+		}
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	scrapers "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
+	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+var log *logrus.Logger
+
+func init() {
+	log = logrus.New()
+}
+
+var (
+	exchange        = flag.String("exchange", "", "which exchange: Bitflyer, Bitmex, Coinflex, Deribit, FTX or Huobi")
+	markets         = flag.String("markets", "", "comma separated list of the futures markets to scrape")
+	writer          = flag.String("writer", "text", "where raw messages are written: text or archive (gzip-compressed csv)")
+	writerDir       = flag.String("writerDir", ".", "directory of the files written")
+	archiveMaxBytes = flag.Int64("archiveMaxBytes", 256<<20, "size at which archive files are rotated")
+)
+
+func init() {
+	flag.Parse()
+	if *exchange == "" || *markets == "" {
+		flag.Usage()
+		log.Fatal("exchange and markets are required")
+	}
+}
+
+// handleTrades forwards the futures trades to kafka
+func handleTrades(c chan *dia.FuturesTrade, w *kafka.Writer) {
+	for t := range c {
+		kafkaHelper.WriteMessage(w, t)
+	}
+}
+
+// main scrapes the futures markets of an exchange, writes the raw messages to files
+// and publishes the trades on the futuresTrades topic
+func main() {
+	w, err := writers.NewWriter(*writer, *writerDir, *archiveMaxBytes)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go closeOnSignal(w)
+
+	configApi, err := dia.GetConfig(*exchange)
+	if err != nil {
+		configApi = &dia.ConfigApi{}
+		if *exchange == dia.Deribit {
+			log.Warning("no config for exchange's api ", err)
+		}
+	}
+	s, err := scrapers.NewFuturesScraper(*exchange, strings.Split(*markets, ","), configApi.ApiKey, configApi.SecretKey, w)
+	if err != nil {
+		log.Fatal(err)
+	}
+	kw := kafkaHelper.NewWriter(kafkaHelper.TopicFuturesTrades)
+	defer kw.Close()
+	go handleTrades(s.Channel(), kw)
+	s.ScrapeMarkets()
+}
+
+// closeOnSignal completes the files of @w and exits on SIGINT or SIGTERM.
+func closeOnSignal(w writers.Writer) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	<-sigs
+	if closer, ok := w.(io.Closer); ok {
+		err := closer.Close()
+		if err != nil {
+			log.Error("close files: ", err)
+		}
+	}
+	os.Exit(0)
+}
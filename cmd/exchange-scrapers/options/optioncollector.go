@@ -1,13 +1,15 @@
 package main
 
 import (
+	"errors"
 	"flag"
-	"sync"
+	"fmt"
 	"time"
 
 	options "github.com/diadata-org/diadata/internal/pkg/option-scrapers"
 	"github.com/diadata-org/diadata/pkg/dia"
-	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	"github.com/segmentio/kafka-go"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,36 +19,50 @@ func init() {
 	log = logrus.New()
 }
 
-const (
-	watchdogDelay = 60 * 60
-)
+// time to wait before a stopped or silent scraper is replaced
+const restartDelay = 10 * time.Second
 
-func handleorderBook(datastore *models.DB, c chan *dia.OptionOrderbookDatum, wg *sync.WaitGroup, exchange string) {
-	lastTradeTime := time.Now()
-	t := time.NewTicker(time.Duration(watchdogDelay) * time.Second)
+// forwardOrderbooks publishes the order book data of @es on @w and logs its errors. It returns
+// once the scraper stops or has not sent any order book datum within @watchdogDelay.
+func forwardOrderbooks(es options.OptionsScraper, w *kafka.Writer, watchdogDelay time.Duration) error {
+	lastDatumTime := time.Now()
+	interval := watchdogDelay / 10
+	if interval < time.Second {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
 	for {
 		select {
 		case <-t.C:
-			duration := time.Since(lastTradeTime)
-			if duration > time.Duration(watchdogDelay)*time.Second {
-				log.Error(duration)
-				panic("frozen? ")
+			duration := time.Since(lastDatumTime)
+			if duration > watchdogDelay {
+				return fmt.Errorf("no order book data for %v", duration)
 			}
-		case t, ok := <-c:
+		case err := <-es.Errors():
+			log.Warn("scraper error: ", err)
+		case datum, ok := <-es.Channel():
 			if !ok {
-				wg.Done()
-				log.Error("handleTrades")
-				return
+				return errors.New("order book channel closed")
 			}
-			lastTradeTime = time.Now()
-			datastore.SaveOptionOrderbookDatumInflux(*t)
+			lastDatumTime = time.Now()
+			kafkaHelper.WriteMessage(w, datum)
 		}
 	}
 }
 
+// closeScraper closes @es and logs its last error.
+func closeScraper(es options.OptionsScraper) {
+	err := es.Close()
+	if err != nil {
+		log.Warn("close scraper: ", err)
+	}
+}
+
 var (
 	exchange         = flag.String("exchange", "", "which exchange")
 	onePairPerSymbol = flag.Bool("onePairPerSymbol", false, "one Pair max Per Symbol ?")
+	watchdogDelay    = flag.Duration("watchdogDelay", time.Hour, "time without order book data after which the scraper is restarted")
 )
 
 func init() {
@@ -61,26 +77,29 @@ func init() {
 	}
 }
 
-// main manages all PairScrapers and handles incoming trade information
+// main scrapes the option order books of an exchange and publishes them on the optionOrderBook topic.
+// The scraper is replaced by a new one whenever it stops or falls silent.
 func main() {
-
-	ds, err := models.NewDataStore()
-	if err != nil {
-		log.Errorln("NewDataStore:", err)
-	}
-
 	configApi, err := dia.GetConfig(*exchange)
 	if err != nil {
 		log.Warning("no config for exchange's api ", err)
+		configApi = &dia.ConfigApi{}
 	}
-	es := options.New(*exchange, configApi.ApiKey, configApi.SecretKey)
 
-	es.FetchInstruments()
-	es.Scrape()
+	w := kafkaHelper.NewWriter(kafkaHelper.TopicOptionOrderBook)
+	defer w.Close()
 
-	wg := sync.WaitGroup{}
-	wg.Add(1)
+	for {
+		es := options.New(*exchange, configApi.ApiKey, configApi.SecretKey)
+		if es == nil {
+			log.Fatal("no options scraper for exchange ", *exchange)
+		}
+		es.FetchInstruments()
+		es.Scrape()
 
-	go handleorderBook(ds, es.Channel(), &wg, *exchange)
-	wg.Wait()
+		err = forwardOrderbooks(es, w, *watchdogDelay)
+		log.Warnf("restarting scraper of %s in %v: %v", *exchange, restartDelay, err)
+		go closeScraper(es)
+		time.Sleep(restartDelay)
+	}
 }
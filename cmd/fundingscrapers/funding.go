@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"sync"
+
+	fundingscrapers "github.com/diadata-org/diadata/internal/pkg/funding-scrapers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// handleFundingRate writes the funding rates to influx
+func handleFundingRate(c chan *dia.FundingRate, wg *sync.WaitGroup, ds models.Datastore) {
+	defer wg.Done()
+	for {
+		f, ok := <-c
+		if !ok {
+			log.Error("error")
+			return
+		}
+		ds.SaveFundingRateInflux(f)
+	}
+}
+
+// main polls the funding rates and open interest of the perpetuals of an exchange
+func main() {
+	exchange := flag.String("exchange", "", "which exchange: Bitmex, Deribit, FTX or Huobi")
+	contracts := flag.String("contracts", "", "comma separated list of the perpetual contracts, e.g. XBTUSD,ETHUSD")
+	flag.Parse()
+
+	ds, err := models.NewInfluxDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+	s, err := fundingscrapers.SpawnFundingScraper(*exchange, strings.Split(*contracts, ","))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go handleFundingRate(s.Channel(), &wg, ds)
+	wg.Wait()
+}
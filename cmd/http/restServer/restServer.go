@@ -7,6 +7,8 @@ import (
 	jwt "github.com/appleboy/gin-jwt/v2"
 	//jwt "github.com/blockstatecom/gin-jwt"
 	_ "github.com/diadata-org/diadata/api/docs"
+	// Registers the exchanges served by /v1/exchanges and /v1/symbols.
+	_ "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
 	"github.com/diadata-org/diadata/pkg/http/restServer/diaApi"
@@ -206,6 +208,7 @@ func main() {
 	{
 		// Endpoints for cryptocurrencies/exchanges
 		dia.GET("/quotation/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetQuotation))
+		dia.GET("/assetQuotation/:blockchain/:address", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetAssetQuotation))
 		dia.GET("/lastTrades/:symbol", diaApiEnv.GetLastTrades)
 		dia.GET("/lastPriceBefore/:filter/:exchange/:symbol/:timestamp", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetLastPriceBefore))
 		dia.GET("/lastPriceBeforeAllExchanges/:filter/:symbol/:timestamp", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetLastPriceBeforeAllExchanges))
@@ -252,6 +255,16 @@ func main() {
 		dia.GET("/stockQuotation/:source/:symbol", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetStockQuotation))
 		dia.GET("/stockQuotation/:source/:symbol/:time", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetStockQuotation))
 
+		// Endpoints for order books
+		dia.GET("/orderbook/:exchange/:pair", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOrderBook))
+		dia.GET("/futuresTrades/:exchange/:contract", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFuturesTrades))
+		dia.GET("/funding/:exchange/:contract", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFundingRates))
+		dia.GET("/fundingIndex/:underlying", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetFundingIndex))
+		dia.GET("/optionGreeks/:baseCurrency", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetLatestOptionGreeks))
+		dia.GET("/optionGreeks/:baseCurrency/:instrument", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetOptionGreeks))
+		dia.GET("/volSurface/:baseCurrency", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetVolSurface))
+		dia.GET("/volatilityIndex/:underlying", cache.CachePage(memoryStore, cachingTimeShort, diaApiEnv.GetVolatilityIndex))
+
 		// Endpoints for foreign sources
 		dia.GET("/foreignQuotation/:source/:symbol", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetForeignQuotation))
 		dia.GET("/foreignQuotation/:source/:symbol/:time", cache.CachePage(memoryStore, cachingTimeLong, diaApiEnv.GetForeignQuotation))
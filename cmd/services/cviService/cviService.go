@@ -1,74 +1,100 @@
 package main
 
 import (
+	"flag"
+	"strings"
 	"time"
+
 	filters "github.com/diadata-org/diadata/internal/pkg/filtersOptionService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
 	log "github.com/sirupsen/logrus"
 )
 
-func main() {
-	for {
-		optionMetaNext, err := filters.GetNextTermOptionMeta("BTC")
-		if err != nil {
-			log.Error(err)
-		}
-		optionMetaNear, err := filters.GetNearTermOptionMeta("BTC", optionMetaNext[0].ExpirationTime)
-		if err != nil {
-			log.Error(err)
-		}
+var (
+	underlying   = flag.String("underlying", "BTC", "base currency of the options")
+	exchanges    = flag.String("exchanges", dia.Deribit, "comma separated list of the option venues, all venues if empty")
+	maturity     = flag.Int("maturity", 30, "target maturity of the index in days")
+	riskFreeRate = flag.Float64("riskFreeRate", 0.0054, "annual, continuously compounded risk-free rate")
+	label        = flag.String("label", "default", "label of the methodology the results are stored with")
+	interval     = flag.Duration("interval", 5*time.Minute, "time between two computations, also the step of a historical recomputation")
+	legacy       = flag.Bool("legacy", true, "also write BTC and ETH results to the tables served by /cviIndex")
+	from         = flag.Int64("from", 0, "unix time to start a historical recomputation at; live computation if 0")
+	to           = flag.Int64("to", 0, "unix time to end a historical recomputation at; now if 0")
+)
 
-		miyNear, err := filters.MinutesInYear(optionMetaNear[0].ExpirationTime.Year())
-		if err != nil {
-			log.Error(err)
-		}
-		miyNext, err := filters.MinutesInYear(optionMetaNext[0].ExpirationTime.Year())
-		if err != nil {
-			log.Error(err)
-		}
+// cviService computes the volatility index of an underlying from the order books of its options,
+// either live every interval or as a batch over a historical range of stored order book data.
+func main() {
+	flag.Parse()
 
-		tNear := filters.TimeToMaturity(optionMetaNear[0]) / miyNear
-		tNext := filters.TimeToMaturity(optionMetaNext[0]) / miyNext
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+	var venues []string
+	if *exchanges != "" {
+		venues = strings.Split(*exchanges, ",")
+	}
+	vi := filters.NewVolatilityIndex(*underlying, venues, time.Duration(*maturity)*24*time.Hour, *riskFreeRate)
 
-		neartermFwdIndexLevel, err := filters.ForwardIndexLevel(optionMetaNear, 0.0054, tNear)
-		if err != nil {
-			log.Error(err)
-		}
-		nexttermFwdIndexLevel, err := filters.ForwardIndexLevel(optionMetaNext, 0.0054, tNext)
+	if *from != 0 {
+		recompute(ds, vi)
+		return
+	}
+	for {
+		now := time.Now()
+		value, err := vi.Value(ds, now)
 		if err != nil {
-			log.Error(err)
-		}
-
-		if optionMetaNear[0].GeneralizedInstrumentName != "" &&
-			 optionMetaNext[0].GeneralizedInstrumentName != "" {
-			omINear, err := filters.GetOptionMetaIndex("BTC", optionMetaNear[0].GeneralizedInstrumentName[4:11])
-			if err != nil {
-				log.Error(err)
-			}
-
-			vindNear, err := filters.VarianceIndex(omINear, 0.0054, tNear, neartermFwdIndexLevel, 13000.0)
-			if err != nil {
-				log.Error(err)
+			log.Errorf("volatility index of %s: %v", *underlying, err)
+		} else {
+			saveIndex(ds, now, value)
+			if *legacy {
+				saveLegacy(ds, now, value)
 			}
+		}
+		time.Sleep(*interval)
+	}
+}
 
-			omINext, err := filters.GetOptionMetaIndex("BTC", optionMetaNext[0].GeneralizedInstrumentName[4:11])
-			if err != nil {
-				log.Error(err)
-			}
+func recompute(ds *models.DB, vi *filters.VolatilityIndex) {
+	endtime := time.Now()
+	if *to != 0 {
+		endtime = time.Unix(*to, 0)
+	}
+	points, err := vi.Recompute(ds, time.Unix(*from, 0), endtime, *interval)
+	if err != nil {
+		log.Fatal("recompute volatility index: ", err)
+	}
+	for _, point := range points {
+		saveIndex(ds, point.Timestamp, point.Value)
+	}
+	log.Infof("recomputed %d values of the volatility index of %s", len(points), *underlying)
+}
 
-			vindNext, err := filters.VarianceIndex(omINext, 0.0054, tNext, nexttermFwdIndexLevel, 13000.0)
-			if err != nil {
-				log.Error(err)
-			}
+func saveIndex(ds *models.DB, t time.Time, value float64) {
+	err := ds.SaveVolatilityIndexInflux(dia.VolatilityIndexPoint{
+		Underlying: *underlying,
+		Maturity:   *maturity,
+		Label:      *label,
+		Value:      value,
+		Timestamp:  t,
+	})
+	if err != nil {
+		log.Error("save volatility index: ", err)
+	}
+}
 
-			CVI, err := filters.CVI(vindNear, vindNext, tNear, tNext, 2020)
-			if err != nil {
-				log.Error(err)
-			}
-			err = filters.CVIToDatastore(CVI)
-			if err != nil {
-				log.Error(err)
-			}
-		}
-		time.Sleep(5 * time.Minute)
+// saveLegacy writes @value to the fixed CVI tables of BTC and ETH.
+func saveLegacy(ds *models.DB, t time.Time, value float64) {
+	var err error
+	switch *underlying {
+	case "BTC":
+		err = ds.SaveCVIInflux(value, t)
+	case "ETH":
+		err = ds.SaveETHCVIInflux(value, t)
+	}
+	if err != nil {
+		log.Error("save cvi: ", err)
 	}
 }
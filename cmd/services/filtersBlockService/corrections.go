@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
+	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// correctionsRun is the run the filter values of corrected blocks are stored under in the
+// recomputed filters table.
+const correctionsRun = "corrections"
+
+// correctBlock recomputes the filters of the block corrected by @correction and saves their values
+// under correctionsRun in the recomputed filters table. The block is rebuilt from the stored trades
+// of the symbols of the correction, which include its late trades. The filters are warmed up on the
+// stored trades of @warmup before the block, whose values are not saved. Production values and
+// prices are left unchanged, as the filters of the live service have moved on since.
+func correctBlock(db models.Datastore, filtersConfig filters.FiltersConfig, correction *dia.TradesBlock, warmup time.Duration) (*dia.FiltersBlock, error) {
+	begin := correction.TradesBlockData.BeginTime
+	symbols := []string{}
+	seen := make(map[string]bool)
+	for _, t := range correction.TradesBlockData.Trades {
+		if !seen[t.Symbol] {
+			seen[t.Symbol] = true
+			symbols = append(symbols, t.Symbol)
+		}
+	}
+	sort.Strings(symbols)
+	trades, err := db.GetTradesInflux(symbols, begin.Add(-warmup), correction.TradesBlockData.EndTime)
+	if err != nil {
+		return nil, err
+	}
+	begins, byBlock := tradesByBlock(trades)
+	if len(byBlock[begin.Unix()]) == 0 {
+		return nil, fmt.Errorf("no stored trades of block %v", begin)
+	}
+
+	store := &recomputeStore{db: db, run: correctionsRun}
+	f := filters.NewFiltersBlockService(nil, store, nil, filtersConfig)
+	defer f.Close()
+	var fb *dia.FiltersBlock
+	for _, b := range begins {
+		store.discard = b < begin.Unix()
+		fb = f.ComputeTradesBlock(tradesBlockService.BuildTradesBlock(byBlock[b], time.Unix(b, 0), dia.BlockSizeSeconds))
+	}
+	return fb, nil
+}
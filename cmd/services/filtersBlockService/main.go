@@ -3,26 +3,32 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
+	"os"
+	"strings"
 	"sync"
 	"time"
 
 	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/segmentio/kafka-go"
 	log "github.com/sirupsen/logrus"
 )
 
 var (
-	replayInflux = flag.Bool("replayInflux", false, "replayInflux ?")
+	metricsAddr      = flag.String("metricsAddr", ":9090", "address to serve the prometheus metrics on /metrics, empty to disable")
+	filtersName      = flag.String("filters", "filters", "name of the filters configuration in the config directory, default filters if not found")
+	from             = flag.Int64("from", 0, "unix time to start a recomputation from the stored trades at; live computation if 0")
+	to               = flag.Int64("to", 0, "unix time to end a recomputation at; now if 0")
+	symbols          = flag.String("symbols", "", "comma separated list of the symbols to recompute, all symbols if empty")
+	run              = flag.String("run", "", "name the recomputed filter values are stored under; derived from the configuration and time range if empty")
+	report           = flag.String("report", "", "file to write the comparison of a recomputation with the production values to; stdout if empty")
+	correctionWarmup = flag.Duration("correctionWarmup", 10*time.Minute, "time of stored trades before a corrected block the filters are warmed up on")
 )
 
-func init() {
-	flag.Parse()
-	log.Println("replayInflux=", *replayInflux)
-}
-
 func handler(channel chan *dia.FiltersBlock, wg *sync.WaitGroup, w *kafka.Writer) {
 	var block int
 	for {
@@ -52,76 +58,93 @@ func loadFilterPointsFromPreviousBlock() []dia.FilterPoint {
 	return lastFilterPoints
 }
 
-//  docker exec -it <cointainer> filtersBlockService -replayInflux
+// loadFiltersConfig returns the configured filters, or the default filters if there is no configuration.
+func loadFiltersConfig() filters.FiltersConfig {
+	config, err := filters.LoadFiltersConfig(*filtersName)
+	if os.IsNotExist(err) {
+		log.Warnf("no filters configuration %s, using default filters", *filtersName)
+		return filters.DefaultFiltersConfig()
+	}
+	if err != nil {
+		log.Fatalf("load filters configuration %s: %v", *filtersName, err)
+	}
+	return config
+}
 
-func createTradeBlockFromInflux(d models.Datastore, f *filters.FiltersBlockService) {
-	//now := time.Now()
-	//then := now.AddDate(0, -1, 0)
-	then := time.Unix(1539475200, 0)
-	//"1405544146"
+// recomputeRange recomputes the filters of the time range given by the flags, e.g.
+// docker exec -it <container> filtersBlockService -from=1622505600 -to=1622592000 -filters=filters-test -report=/tmp/report.tsv
+func recomputeRange(filtersConfig filters.FiltersConfig) {
+	starttime := time.Unix(*from, 0)
+	endtime := time.Now()
+	if *to != 0 {
+		endtime = time.Unix(*to, 0)
+	}
+	var symbolList []string
+	if *symbols != "" {
+		symbolList = strings.Split(*symbols, ",")
+	}
+	runName := *run
+	if runName == "" {
+		runName = fmt.Sprintf("%s-%d-%d", *filtersName, starttime.Unix(), endtime.Unix())
+	}
+	db, err := models.NewInfluxDataStore()
+	if err != nil {
+		log.Fatal("NewInfluxDataStore: ", err)
+	}
+	w, err := reportWriter(*report)
+	if err != nil {
+		log.Fatal("create report: ", err)
+	}
+	defer w.Close()
+	err = recompute(db, filtersConfig, symbolList, starttime, endtime, runName, w)
+	if err != nil {
+		log.Fatal("recompute: ", err)
+	}
+	log.Infof("recomputed filters stored as run %s", runName)
+}
+
+// correctBlocks recomputes the blocks corrected by the late trades the tradesBlockService publishes
+// and stores their filter values under correctionsRun.
+func correctBlocks(filtersConfig filters.FiltersConfig) {
+	db, err := models.NewInfluxDataStore()
+	if err != nil {
+		log.Errorln("NewInfluxDataStore: corrections are not recomputed", err)
+		return
+	}
+	r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicTradesBlockCorrections)
+	defer r.Close()
 
-	log.Info("createTradeBlockFromInflux")
-	var currentBlock int64
-	trades := []dia.Trade{}
 	for {
-		log.Info("sleeping")
-		time.Sleep(1 * time.Second)
-		r, err := d.GetAllTrades(then, 1000)
+		m, err := r.ReadMessage(context.Background())
 		if err != nil {
-			log.Errorln("createTradeBlockFromInflux", r)
+			log.Printf(err.Error())
 			continue
 		}
-		if len(r) == 0 {
-			log.Info("no new trades...")
-			break
-		} else {
-			then = r[len(r)-1].Time
-			log.Infoln("x got", len(r), "trades", then)
-			for _, v := range r {
-				if v.Source == "Simex" {
-					continue
-				}
-				block := (v.Time.Unix() / dia.BlockSizeSeconds)
-				if block != currentBlock {
-					var t1 time.Time
-					var t2 time.Time
-					currentBlock = block
-					if len(trades) > 0 {
-						t1 = trades[0].Time
-						t2 = trades[len(trades)-1].Time
-					}
-					b := &dia.TradesBlock{
-						TradesBlockData: dia.TradesBlockData{
-							Trades:    trades,
-							BeginTime: t1,
-							EndTime:   t2,
-						},
-					}
-					if len(trades) > 5 {
-						log.Infoln("calling ProcessTradesBlock", len(trades), "trades blocknumber:", currentBlock, t1, t2)
-						f.ProcessTradesBlock(b)
-						log.Infoln("bang", currentBlock)
-					} else {
-						log.Info("not enough trades in block ignoring...", len(trades), currentBlock, t1, t2)
-					}
-					trades = []dia.Trade{}
-				} else {
-					trades = append(trades, v)
-				}
-			}
+		var correction dia.TradesBlock
+		err = correction.UnmarshalBinary(m.Value)
+		if err != nil {
+			log.Error("error unmarshalling correction")
+			continue
+		}
+		fb, err := correctBlock(db, filtersConfig, &correction, *correctionWarmup)
+		if err != nil {
+			log.Errorf("correct block beginTime: %v: %v", correction.TradesBlockData.BeginTime, err)
+			continue
 		}
+		log.Infof("corrected block beginTime: %v with %d late trades, %d filter points", correction.TradesBlockData.BeginTime, correction.TradesBlockData.TradesNumber, fb.FiltersBlockData.FiltersNumber)
 	}
 }
 
 func main() {
+	flag.Parse()
 
-	if *replayInflux {
-		s, err := models.NewInfluxDataStore()
-		if err != nil {
-			log.Errorln("NewDataStore", err)
-		}
-		f := filters.NewFiltersBlockService(nil, s, nil)
-		createTradeBlockFromInflux(s, f)
+	if *metricsAddr != "" {
+		go metrics.Serve(*metricsAddr)
+	}
+	filtersConfig := loadFiltersConfig()
+
+	if *from != 0 {
+		recomputeRange(filtersConfig)
 	} else {
 		s, err := models.NewDataStore()
 		if err != nil {
@@ -129,7 +152,7 @@ func main() {
 		}
 		channel := make(chan *dia.FiltersBlock)
 
-		f := filters.NewFiltersBlockService(loadFilterPointsFromPreviousBlock(), s, channel)
+		f := filters.NewFiltersBlockService(loadFilterPointsFromPreviousBlock(), s, channel, filtersConfig)
 
 		w := kafkaHelper.NewSyncWriter(kafkaHelper.TopicFiltersBlock)
 
@@ -139,6 +162,8 @@ func main() {
 
 		go handler(channel, &wg, w)
 
+		go correctBlocks(filtersConfig)
+
 		r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicTradesBlock)
 		defer r.Close()
 
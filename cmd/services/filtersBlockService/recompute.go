@@ -0,0 +1,195 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
+	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// recomputeChunk is the time range of the trades loaded and compared at once.
+const recomputeChunk = time.Hour
+
+// recomputeStore saves the values of the filters of a recomputation in the recomputed filters
+// table of its run instead of the production tables. Any other use of the datastore panics,
+// as it would touch production data.
+type recomputeStore struct {
+	models.Datastore
+	db  models.Datastore
+	run string
+	// values on all exchanges since the last reset, compared with production
+	points []dia.FilterPoint
+	// discard drops the values, e.g. of the blocks warming up the filters of a correction
+	discard bool
+}
+
+func (s *recomputeStore) SetFilter(filter string, symbol string, exchange string, value float64, t time.Time) error {
+	if s.discard {
+		return nil
+	}
+	if exchange == "" {
+		s.points = append(s.points, dia.FilterPoint{Name: filter, Symbol: symbol, Value: value, Time: t})
+	}
+	return s.db.SaveRecomputedFilterInflux(s.run, filter, symbol, exchange, value, t)
+}
+
+func (s *recomputeStore) SetPriceZSET(symbol string, exchange string, price float64, t time.Time) error {
+	return s.SetFilter(dia.FilterKing, symbol, exchange, price, t)
+}
+
+func (s *recomputeStore) SetAssetPriceUSD(asset dia.Asset, price float64) error {
+	return nil
+}
+
+func (s *recomputeStore) SetLastTradeTimeForExchange(symbol string, exchange string, t time.Time) error {
+	return nil
+}
+
+func (s *recomputeStore) Flush() error {
+	return s.db.Flush()
+}
+
+// diffStats compares the recomputed values of a filter and symbol with the production values.
+type diffStats struct {
+	points     int
+	matched    int
+	sumRelDiff float64
+	maxRelDiff float64
+}
+
+// diffReport holds the diffStats by filter and symbol.
+type diffReport map[[2]string]*diffStats
+
+// add compares the @recomputed values with the latest @production values of the same filter and
+// symbol within a block before them. @production must be ordered by time per filter and symbol.
+func (r diffReport) add(recomputed []dia.FilterPoint, production []dia.FilterPoint) {
+	series := make(map[[2]string][]dia.FilterPoint)
+	for _, fp := range production {
+		key := [2]string{fp.Name, fp.Symbol}
+		series[key] = append(series[key], fp)
+	}
+	for _, fp := range recomputed {
+		key := [2]string{fp.Name, fp.Symbol}
+		stats, ok := r[key]
+		if !ok {
+			stats = &diffStats{}
+			r[key] = stats
+		}
+		stats.points++
+		prod := series[key]
+		i := sort.Search(len(prod), func(i int) bool { return prod[i].Time.After(fp.Time) }) - 1
+		if i < 0 || fp.Time.Sub(prod[i].Time) >= dia.BlockSizeSeconds*time.Second || prod[i].Value == 0 {
+			continue
+		}
+		stats.matched++
+		diff := math.Abs(fp.Value-prod[i].Value) / math.Abs(prod[i].Value)
+		stats.sumRelDiff += diff
+		stats.maxRelDiff = math.Max(stats.maxRelDiff, diff)
+	}
+}
+
+// write writes the report as tab separated values ordered by filter and symbol.
+func (r diffReport) write(w io.Writer) {
+	keys := [][2]string{}
+	for key := range r {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	fmt.Fprintln(w, "filter\tsymbol\tpoints\tmatched\tmeanRelDiff\tmaxRelDiff")
+	for _, key := range keys {
+		stats := r[key]
+		mean := 0.0
+		if stats.matched > 0 {
+			mean = stats.sumRelDiff / float64(stats.matched)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%g\t%g\n", key[0], key[1], stats.points, stats.matched, mean, stats.maxRelDiff)
+	}
+}
+
+// recompute rebuilds the trades and filters blocks of the stored trades of @symbols in the time range
+// [@starttime, @endtime) with the filters of @filtersConfig. The filter values are saved under @run in
+// the recomputed filters table, and compared with the production values in the report written to @w.
+// The same trades and configuration always give the same blocks, summarized by the digest of the report.
+// Trades the tradesBlockService dropped as late are quarantined, so the blocks are those of production
+// including their corrections. Trades stored before late trades were quarantined are included in their blocks.
+func recompute(db models.Datastore, filtersConfig filters.FiltersConfig, symbols []string, starttime time.Time, endtime time.Time, run string, w io.Writer) error {
+	store := &recomputeStore{db: db, run: run}
+	f := filters.NewFiltersBlockService(nil, store, nil, filtersConfig)
+	defer f.Close()
+
+	report := make(diffReport)
+	digest := sha256.New()
+	var blocks, trades int
+	blockDuration := time.Duration(dia.BlockSizeSeconds) * time.Second
+	for chunkStart := starttime.Truncate(blockDuration); chunkStart.Before(endtime); chunkStart = chunkStart.Add(recomputeChunk) {
+		chunkEnd := chunkStart.Add(recomputeChunk)
+		if chunkEnd.After(endtime) {
+			chunkEnd = endtime
+		}
+		chunkTrades, err := db.GetTradesInflux(symbols, chunkStart, chunkEnd)
+		if err != nil {
+			return err
+		}
+		begins, byBlock := tradesByBlock(chunkTrades)
+
+		store.points = nil
+		for _, begin := range begins {
+			tb := tradesBlockService.BuildTradesBlock(byBlock[begin], time.Unix(begin, 0), dia.BlockSizeSeconds)
+			fb := f.ComputeTradesBlock(tb)
+			fmt.Fprintln(digest, tb.BlockHash, fb.BlockHash)
+			blocks++
+			trades += tb.TradesBlockData.TradesNumber
+		}
+
+		production, err := db.GetFilterValuesInflux("", chunkStart.Add(-blockDuration), chunkEnd.Add(time.Second))
+		if err != nil {
+			return err
+		}
+		report.add(store.points, production)
+		log.Infof("recomputed %d blocks of %d trades until %v", blocks, trades, chunkEnd)
+	}
+
+	fmt.Fprintf(w, "# run %s from %v to %v\n", run, starttime.UTC(), endtime.UTC())
+	fmt.Fprintf(w, "# %d trades blocks, %d trades, digest %s\n", blocks, trades, hex.EncodeToString(digest.Sum(nil)))
+	fmt.Fprintln(w, "# trades blocks of the stored trades with the late trades of their corrections, without the quarantined trades and the late trades dropped by the tradesBlockService")
+	report.write(w)
+	return nil
+}
+
+// tradesByBlock groups @trades by the begin time of their block and returns the sorted begin times.
+func tradesByBlock(trades []dia.Trade) ([]int64, map[int64][]dia.Trade) {
+	byBlock := make(map[int64][]dia.Trade)
+	for _, t := range trades {
+		begin := (t.Time.Unix() / dia.BlockSizeSeconds) * dia.BlockSizeSeconds
+		byBlock[begin] = append(byBlock[begin], t)
+	}
+	begins := []int64{}
+	for begin := range byBlock {
+		begins = append(begins, begin)
+	}
+	sort.Slice(begins, func(i, j int) bool { return begins[i] < begins[j] })
+	return begins, byBlock
+}
+
+// reportWriter returns the file the report is written to, or stdout if @path is empty.
+func reportWriter(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return os.Stdout, nil
+	}
+	return os.Create(path)
+}
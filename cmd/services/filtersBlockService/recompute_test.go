@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
+	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// fakeInflux serves stored trades and production filter values.
+type fakeInflux struct {
+	models.Datastore
+	trades     []dia.Trade
+	production []dia.FilterPoint
+	// recomputed values on all exchanges by run
+	saved map[string][]dia.FilterPoint
+}
+
+func (f *fakeInflux) GetTradesInflux(symbols []string, starttime time.Time, endtime time.Time) ([]dia.Trade, error) {
+	result := []dia.Trade{}
+	for _, t := range f.trades {
+		if !t.Time.Before(starttime) && t.Time.Before(endtime) {
+			result = append(result, t)
+		}
+	}
+	return result, nil
+}
+
+func (f *fakeInflux) GetFilterValuesInflux(exchange string, starttime time.Time, endtime time.Time) ([]dia.FilterPoint, error) {
+	return f.production, nil
+}
+
+func (f *fakeInflux) SaveRecomputedFilterInflux(run string, filter string, symbol string, exchange string, value float64, t time.Time) error {
+	if f.saved != nil && exchange == "" {
+		f.saved[run] = append(f.saved[run], dia.FilterPoint{Name: filter, Symbol: symbol, Value: value, Time: t})
+	}
+	return nil
+}
+
+func (f *fakeInflux) Flush() error {
+	return nil
+}
+
+func TestRecompute(t *testing.T) {
+	start := time.Unix(1622505600, 0)
+	trades := []dia.Trade{}
+	for i := 0; i < 600; i++ {
+		trades = append(trades, dia.Trade{
+			Symbol:            "BTC",
+			Pair:              "BTC-USDT",
+			Source:            []string{"Binance", "Kraken", "CoinBase"}[i%3],
+			Time:              start.Add(time.Duration(i/3) * 3 * time.Second),
+			EstimatedUSDPrice: 35000 + float64(i%7),
+			Volume:            1,
+			ForeignTradeID:    string(rune('a' + i%26)),
+		})
+	}
+	production := []dia.FilterPoint{{Name: "MAIR120", Symbol: "BTC", Value: 35000, Time: start.Add(119 * time.Second)}}
+	config := filters.DefaultFiltersConfig()
+
+	reports := []string{}
+	for run := 0; run < 2; run++ {
+		shuffled := append([]dia.Trade{}, trades...)
+		rand.New(rand.NewSource(int64(run))).Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+		var report bytes.Buffer
+		err := recompute(&fakeInflux{trades: shuffled, production: production}, config, nil, start, start.Add(10*time.Minute), "test", &report)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reports = append(reports, report.String())
+	}
+	if reports[0] != reports[1] {
+		t.Errorf("recomputation depends on the order of the trades:\n%s\n%s", reports[0], reports[1])
+	}
+	if !strings.Contains(reports[0], "# 5 trades blocks, 600 trades") {
+		t.Errorf("unexpected report %s", reports[0])
+	}
+	if !strings.Contains(reports[0], "MAIR120\tBTC\t5\t1\t") {
+		t.Errorf("expected one MAIR120 value to be compared with production, got %s", reports[0])
+	}
+}
+
+func TestCorrectBlock(t *testing.T) {
+	begin := time.Unix(1622505600, 0)
+	trade := func(price float64, seconds int) dia.Trade {
+		return dia.Trade{Symbol: "BTC", Pair: "BTC-USDT", Source: "Uniswap", EstimatedUSDPrice: price, Volume: 1, Time: begin.Add(time.Duration(seconds) * time.Second)}
+	}
+	late := trade(36000, 230)
+	db := &fakeInflux{
+		// the stored trades include the late trade of the correction
+		trades: []dia.Trade{trade(35000, 10), trade(35000, 130), trade(35000, 200), late},
+		saved:  make(map[string][]dia.FilterPoint),
+	}
+	correction := tradesBlockService.BuildTradesBlock([]dia.Trade{late}, begin.Add(120*time.Second), dia.BlockSizeSeconds)
+
+	fb, err := correctBlock(db, filters.DefaultFiltersConfig(), correction, 10*time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fb.FiltersBlockData.BeginTime.Equal(correction.TradesBlockData.BeginTime) {
+		t.Errorf("expected the filters block of the corrected block, got %v", fb.FiltersBlockData.BeginTime)
+	}
+	var king float64
+	for _, fp := range db.saved[correctionsRun] {
+		if fp.Time.Before(correction.TradesBlockData.BeginTime) {
+			t.Errorf("value of a warm-up block saved: %v", fp)
+		}
+		if fp.Name == dia.FilterKing {
+			king = fp.Value
+		}
+	}
+	if king <= 35000 || king >= 36000 {
+		t.Errorf("expected corrected price between 35000 and 36000, got %v", king)
+	}
+
+	if _, err := correctBlock(db, filters.DefaultFiltersConfig(), tradesBlockService.BuildTradesBlock([]dia.Trade{trade(1, 1000)}, begin.Add(960*time.Second), dia.BlockSizeSeconds), 0); err == nil {
+		t.Error("expected an error for a correction whose trades are not stored")
+	}
+}
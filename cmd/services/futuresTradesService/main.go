@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var flushInterval = flag.Duration("flushInterval", 10*time.Second, "maximal time a futures trade is buffered before it is written to influx")
+
+// futuresTradesService stores the futures trades published by the futures collectors in influx.
+func main() {
+	flag.Parse()
+
+	r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicFuturesTrades)
+	defer r.Close()
+
+	ds, err := models.NewInfluxDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+
+	log.Printf("starting...")
+
+	lastFlush := time.Now()
+	for {
+		// wait for messages until the buffered trades are due to be written
+		ctx, cancel := context.WithDeadline(context.Background(), lastFlush.Add(*flushInterval))
+		m, err := r.ReadMessage(ctx)
+		cancel()
+		if err == nil {
+			var t dia.FuturesTrade
+			err = t.UnmarshalBinary(m.Value)
+			if err != nil {
+				log.Printf("ignored message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+			} else {
+				ds.SaveFuturesTradeInflux(&t)
+			}
+		} else if err != context.DeadlineExceeded {
+			log.Printf(err.Error())
+		}
+		if time.Since(lastFlush) >= *flushInterval {
+			ds.Flush()
+			lastFlush = time.Now()
+		}
+	}
+}
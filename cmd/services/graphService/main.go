@@ -1,6 +1,8 @@
 package main
 
 import (
+	// Registers the exchanges enumerated by GetAllSymbols.
+	_ "github.com/diadata-org/diadata/internal/pkg/exchange-scrapers"
 	"github.com/diadata-org/diadata/internal/pkg/graphService"
 	"github.com/diadata-org/diadata/pkg/model"
 	log "github.com/sirupsen/logrus"
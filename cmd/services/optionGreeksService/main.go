@@ -0,0 +1,88 @@
+package main
+
+import (
+	"flag"
+	"strings"
+	"time"
+
+	greeks "github.com/diadata-org/diadata/internal/pkg/optionGreeksService"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	symbols      = flag.String("symbols", "BTC,ETH", "comma separated list of the base currencies of the options")
+	riskFreeRate = flag.Float64("riskFreeRate", 0.01, "annual, continuously compounded risk-free rate, used if rateSymbol is empty or unavailable")
+	rateSymbol   = flag.String("rateSymbol", "", "DIA interest rate used as risk-free rate, e.g. SOFR")
+	interval     = flag.Duration("interval", 5*time.Minute, "time between two computations")
+	maxAge       = flag.Duration("maxAge", time.Hour, "maximal age of an order book to be used")
+)
+
+// optionGreeksService computes the implied volatilities and greeks of all options from their
+// latest order books and the DIA spot quotation of their base currency.
+func main() {
+	flag.Parse()
+
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+
+	for {
+		rate := getRiskFreeRate(ds)
+		for _, symbol := range strings.Split(*symbols, ",") {
+			computeGreeks(ds, symbol, rate)
+		}
+		err = ds.Flush()
+		if err != nil {
+			log.Error("flush option greeks: ", err)
+		}
+		time.Sleep(*interval)
+	}
+}
+
+// getRiskFreeRate returns the DIA interest rate rateSymbol, published in percent, or the flag riskFreeRate.
+func getRiskFreeRate(ds models.Datastore) float64 {
+	if *rateSymbol == "" {
+		return *riskFreeRate
+	}
+	ir, err := ds.GetInterestRate(*rateSymbol, "")
+	if err != nil {
+		log.Errorf("get interest rate %s, using %v: %v", *rateSymbol, *riskFreeRate, err)
+		return *riskFreeRate
+	}
+	return ir.Value / 100
+}
+
+func computeGreeks(ds *models.DB, symbol string, rate float64) {
+	quotation, err := ds.GetQuotation(symbol)
+	if err != nil {
+		log.Errorf("get quotation of %s: %v", symbol, err)
+		return
+	}
+	optionsMeta, err := ds.GetOptionMeta(symbol)
+	if err != nil {
+		log.Errorf("get options of %s: %v", symbol, err)
+		return
+	}
+	now := time.Now()
+	var computed int
+	for _, meta := range optionsMeta {
+		if !meta.ExpirationTime.After(now) {
+			continue
+		}
+		ob, err := ds.GetOptionOrderbookDataInflux(meta)
+		if err != nil || ob.ObservationTime.IsZero() || now.Sub(ob.ObservationTime) > *maxAge {
+			continue
+		}
+		g, err := greeks.Compute(meta, ob, quotation.Price, rate)
+		if err != nil {
+			log.Debugf("compute greeks of %s: %v", meta.InstrumentName, err)
+			continue
+		}
+		if ds.SaveOptionGreeksInflux(g) == nil {
+			computed++
+		}
+	}
+	log.Infof("computed greeks of %d of %d options on %s", computed, len(optionsMeta), symbol)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// optionOrderBookService stores the option order book data published by the option collectors in influx.
+func main() {
+	r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicOptionOrderBook)
+	defer r.Close()
+
+	ds, err := models.NewInfluxDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+
+	log.Printf("starting...")
+
+	for {
+		m, err := r.ReadMessage(context.Background())
+		if err != nil {
+			log.Printf(err.Error())
+			continue
+		}
+		var datum dia.OptionOrderbookDatum
+		err = datum.UnmarshalBinary(m.Value)
+		if err != nil {
+			log.Printf("ignored message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+			continue
+		}
+		err = ds.SaveOptionOrderbookDatumInflux(datum)
+		if err != nil {
+			log.Error("save option order book datum: ", err)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+var sampleInterval = flag.Duration("sampleInterval", time.Minute, "minimal time between two persisted snapshots of the same pair on an exchange")
+
+// orderBookService samples the order book snapshots published by the collectors
+// and stores at most one snapshot per exchange and pair each sampleInterval in influx.
+func main() {
+	flag.Parse()
+
+	r := kafkaHelper.NewReaderNextMessage(kafkaHelper.TopicOrderBooks)
+	defer r.Close()
+
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+
+	// exchange+pair -> time of the last persisted snapshot
+	lastSample := make(map[string]time.Time)
+
+	log.Printf("starting...")
+
+	for {
+		m, err := r.ReadMessage(context.Background())
+		if err != nil {
+			log.Printf(err.Error())
+			continue
+		}
+		var ob dia.OrderBookSnapshot
+		err = ob.UnmarshalBinary(m.Value)
+		if err != nil {
+			log.Printf("ignored message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+			continue
+		}
+
+		key := ob.Source + ob.Pair
+		if ob.Time.Sub(lastSample[key]) < *sampleInterval {
+			continue
+		}
+		err = ds.SaveOrderBookSnapshotInflux(&ob)
+		if err != nil {
+			log.Errorf("save order book of %s on %s: %v", ob.Pair, ob.Source, err)
+			continue
+		}
+		lastSample[key] = ob.Time
+	}
+}
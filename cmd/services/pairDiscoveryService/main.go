@@ -84,14 +84,15 @@ func updateExchangePairs() {
 			}
 			log.Info("Updating exchange ", exchange)
 			var scraper scrapers.APIScraper
-			config, err := dia.GetConfig(exchange)
-			if err == nil { //TODO: APIs with no need for a key
-				scraper = scrapers.NewAPIScraper(exchange, config.ApiKey, config.SecretKey)
-			} else {
-				log.Info("No valid API config for exchange: ", exchange, " Error: ", err.Error())
-				log.Info("Proceeding with no API secrets")
-				scraper = scrapers.NewAPIScraper(exchange, "", "")
+			config := &dia.ConfigApi{}
+			if scrapers.Exchanges[exchange].RequiresAPIKey {
+				config, err = dia.GetConfig(exchange)
+				if err != nil {
+					log.Info("No valid API config for exchange: ", exchange, " Error: ", err.Error())
+					log.Info("Proceeding with no API secrets")
+				}
 			}
+			scraper = scrapers.NewAPIScraper(exchange, config.ApiKey, config.SecretKey)
 			if scraper != nil {
 				pairs, err := scraper.FetchAvailablePairs()
 				if err == nil {
@@ -2,18 +2,32 @@ package main
 
 import (
 	"context"
+	"flag"
 	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
 	"github.com/diadata-org/diadata/pkg/model"
 	"github.com/segmentio/kafka-go"
 	log "github.com/sirupsen/logrus"
 	"sync"
+	"time"
 )
 
-func handleBlocks(blockMaker *tradesBlockService.TradesBlockService, wg *sync.WaitGroup, w *kafka.Writer) {
+var (
+	metricsAddr      = flag.String("metricsAddr", ":9090", "address to serve the prometheus metrics on /metrics, empty to disable")
+	maxDeviation     = flag.Float64("maxDeviation", 0, "maximal relative deviation of a trade's price from the cross-exchange median and the reference price, 0 to disable")
+	medianWindow     = flag.Duration("medianWindow", 10*time.Minute, "time the latest price of an exchange counts in the cross-exchange median")
+	minExchanges     = flag.Int("minExchanges", 2, "minimal number of other exchanges for the cross-exchange median")
+	referenceSource  = flag.String("referenceSource", "", "source of the foreign quotations trades are compared with, e.g. Coingecko, none if empty")
+	referenceMaxAge  = flag.Duration("referenceMaxAge", time.Hour, "maximal age of a foreign quotation used as reference")
+	allowedLateness  = flag.Duration("allowedLateness", 30*time.Second, "time a block waits for late trades after its end; every block and price is delayed by it, later trades go into corrections")
+	correctionWindow = flag.Duration("correctionWindow", time.Hour, "time late trades of finalized blocks are published as corrections")
+)
+
+func handleBlocks(blocks chan *dia.TradesBlock, wg *sync.WaitGroup, w *kafka.Writer) {
 	for {
-		t, ok := <-blockMaker.Channel()
+		t, ok := <-blocks
 		if !ok {
 			log.Printf("handleBlocks: finishing channel")
 			wg.Done()
@@ -27,6 +41,10 @@ func handleBlocks(blockMaker *tradesBlockService.TradesBlockService, wg *sync.Wa
 }
 
 func main() {
+	flag.Parse()
+	if *metricsAddr != "" {
+		go metrics.Serve(*metricsAddr)
+	}
 
 	w := kafkaHelper.NewSyncWriter(kafkaHelper.TopicTradesBlock)
 	defer w.Close()
@@ -39,10 +57,23 @@ func main() {
 		log.Errorln("NewDataStore", err)
 	}
 
-	tradesBlockService := tradesBlockService.NewTradesBlockService(s, dia.BlockSizeSeconds)
+	tradesBlockService := tradesBlockService.NewTradesBlockService(s, dia.BlockSizeSeconds, tradesBlockService.OutlierConfig{
+		MaxDeviation:    *maxDeviation,
+		MedianWindow:    *medianWindow,
+		MinExchanges:    *minExchanges,
+		ReferenceSource: *referenceSource,
+		ReferenceMaxAge: *referenceMaxAge,
+	}, tradesBlockService.LatenessConfig{
+		AllowedLateness:  *allowedLateness,
+		CorrectionWindow: *correctionWindow,
+	})
 
 	wg := sync.WaitGroup{}
-	go handleBlocks(tradesBlockService, &wg, w)
+	go handleBlocks(tradesBlockService.Channel(), &wg, w)
+
+	wc := kafkaHelper.NewSyncWriter(kafkaHelper.TopicTradesBlockCorrections)
+	defer wc.Close()
+	go handleBlocks(tradesBlockService.Corrections(), &wg, wc)
 
 	log.Printf("starting...")
 
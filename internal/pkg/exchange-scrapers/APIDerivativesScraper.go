@@ -3,6 +3,8 @@ package scrapers
 import (
 	"sync"
 
+	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/model"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
@@ -25,6 +27,8 @@ type DeribitScraper struct {
 	Logger					*zap.SugaredLogger
 	DataStore       *models.DB
 	WsConnection    *websocket.Conn
+	// raw messages of futures markets are written by Writer
+	Writer          writers.Writer
 
 	// required for deribit to:
 	// 1. authenticate (trades is a private channel)
@@ -34,4 +38,7 @@ type DeribitScraper struct {
 
 	RefreshTokenEvery int16 // how often we refresh the token (in seconds)
 	MarketKind        DeribitScraperKind
+
+	// trades of futures markets, see Channel
+	chanTrades chan *dia.FuturesTrade
 }
@@ -1,11 +1,78 @@
 package scrapers
 
+import (
+	"errors"
+	"strings"
+	"time"
+
+	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
 // FuturesScraper is an interface for all of the Futures Contracts scrapers
 type FuturesScraper interface {
 	Scrape(market string) // a self-sustained goroutine that scrapes a single market
 	ScrapeMarkets()       // will scrape the futures markets defined during instantiation of the scraper
 	ScraperClose(market string, websocketConnection interface{}) error
 	//Authenticate(market string, websocketConnection interface{}) error
+	// Channel returns the channel on which the trades of all markets are sent. It has to be read.
+	Channel() chan *dia.FuturesTrade
 }
 
 const retryIn uint8 = 5 // how long to wait in seconds before restarting a failed websocket
+
+// futuresContract describes the contract traded on a futures market.
+type futuresContract struct {
+	Underlying string
+	Expiry     time.Time
+	Perpetual  bool
+}
+
+// trade returns a trade of @c, which is traded as @market on @exchange.
+func (c futuresContract) trade(exchange string, market string, price float64, size float64, side string, t time.Time, foreignTradeID string) *dia.FuturesTrade {
+	return &dia.FuturesTrade{
+		Exchange:       exchange,
+		Contract:       market,
+		Underlying:     c.Underlying,
+		Expiry:         c.Expiry,
+		Perpetual:      c.Perpetual,
+		Price:          price,
+		Size:           size,
+		Side:           futuresSide(side),
+		Time:           t,
+		ForeignTradeID: foreignTradeID,
+	}
+}
+
+// futuresSide maps the taker side of a trade as given by an exchange, e.g. Buy or SELL, to dia.FuturesBuy
+// or dia.FuturesSell. Unknown sides are logged and left empty, so they are not counted as either.
+func futuresSide(side string) string {
+	switch {
+	case strings.EqualFold(side, dia.FuturesBuy):
+		return dia.FuturesBuy
+	case strings.EqualFold(side, dia.FuturesSell):
+		return dia.FuturesSell
+	}
+	log.Warnf("unknown side %q of futures trade", side)
+	return ""
+}
+
+// NewFuturesScraper returns the futures scraper of @exchange for @markets. Scrapers archiving
+// raw messages write them with @writer, to text files if nil. Deribit needs an API key.
+func NewFuturesScraper(exchange string, markets []string, key string, secret string, writer writers.Writer) (FuturesScraper, error) {
+	switch exchange {
+	case "Bitflyer":
+		return NewBitflyerFuturesScraper(markets, writer), nil
+	case "Bitmex":
+		return NewBitmexFuturesScraper(markets, writer), nil
+	case "Coinflex":
+		return NewCoinflexFuturesScraper(markets, writer), nil
+	case dia.Deribit:
+		return NewDeribitFuturesScraper(markets, key, secret, writer), nil
+	case dia.FTX:
+		return NewFTXFuturesScraper(markets, writer), nil
+	case dia.HuobiExchange:
+		return NewHuobiFuturesScraper(markets, writer), nil
+	}
+	return nil, errors.New("no futures scraper for " + exchange)
+}
@@ -0,0 +1,17 @@
+package scrapers
+
+import (
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// Number of price levels per side captured in an order book snapshot
+const orderBookLevels = 10
+
+// OrderBookScraper is implemented by APIScrapers which can capture the top levels
+// of an order book alongside trades.
+type OrderBookScraper interface {
+	// ScrapeOrderBook continuously captures snapshots of the order book of @pair
+	ScrapeOrderBook(pair dia.Pair) error
+	// OrderBookChannel returns a channel that can be used to receive order book snapshots
+	OrderBookChannel() chan *dia.OrderBookSnapshot
+}
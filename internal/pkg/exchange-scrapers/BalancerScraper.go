@@ -22,13 +22,17 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BalancerExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], Contract: common.HexToAddress("0x9424B1412450D0f8Fc2255FAf6046b98213B76Bd"), WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBalancerScraper(exchange)
+	})
+}
+
 const (
 	BalancerApiDelay       = 20
 	BalancerBatchDelay     = 60 * 1
 	BalancerLookBackBlocks = 6 * 60 * 24 * 20
 	factoryContract        = "0x9424B1412450D0f8Fc2255FAf6046b98213B76Bd"
-	balancerRestDial       = "http://159.69.120.42:8545/"
-	balancerWsDial         = "ws://159.69.120.42:8546/"
 )
 
 type BalancerSwap struct {
@@ -67,9 +71,12 @@ type BalancerScraper struct {
 	RestClient  *ethclient.Client
 	resubscribe chan string
 	pools       map[string]struct{}
+	// holds trades until their block is final
+	confirmations *confirmationBuffer
 }
 
 func NewBalancerScraper(exchange dia.Exchange) *BalancerScraper {
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 	scraper := &BalancerScraper{
 		exchangeName:      exchange.Name,
 		initDone:          make(chan nothing),
@@ -83,17 +90,11 @@ func NewBalancerScraper(exchange dia.Exchange) *BalancerScraper {
 		pools:             make(map[string]struct{}),
 	}
 
-	wsClient, err := ethclient.Dial(balancerWsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.WsClient = wsClient
-	restClient, err := ethclient.Dial(balancerRestDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.RestClient = restClient
+	scraper.confirmations = newConfirmationBuffer(exchange.BlockChain, restClient, connectRelDB(), func(t *dia.Trade) { scraper.chanTrades <- t })
 
+	go scraper.confirmations.run(scraper.shutdown)
 	go scraper.mainLoop()
 	return scraper
 }
@@ -213,6 +214,10 @@ func (scraper *BalancerScraper) subscribeToNewSwaps(poolToSub string) error {
 					scraper.resubscribe <- poolToSub
 				}
 			case vLog := <-sink:
+				if vLog.Raw.Removed {
+					scraper.confirmations.retract(vLog.Raw)
+					continue
+				}
 
 				decimalsIn := int(scraper.balancerTokensMap[vLog.TokenIn.Hex()].Decimals)
 				decimalsOut := int(scraper.balancerTokensMap[vLog.TokenOut.Hex()].Decimals)
@@ -249,7 +254,7 @@ func (scraper *BalancerScraper) subscribeToNewSwaps(poolToSub string) error {
 					ForeignTradeID: swap.ID,
 					Source:         scraper.exchangeName,
 				}
-				pairScraper.parent.chanTrades <- trade
+				scraper.confirmations.add(trade, vLog.Raw)
 				fmt.Println("got trade: ", trade)
 
 			}
@@ -337,14 +342,14 @@ func (scraper *BalancerScraper) getAllTokensMap() (map[string]*BalancerToken, er
 		}
 		symbol, err := tokenCaller.Symbol(&bind.CallOpts{})
 		if err != nil {
-			log.Error("Error: %v", err)
+			log.Errorf("Error: %v", err)
 		}
 		if helpers.SymbolIsBlackListed(symbol) {
 			continue
 		}
 		decimals, err := tokenCaller.Decimals(&bind.CallOpts{})
 		if err != nil {
-			log.Error("Error: %v", token, err)
+			log.Errorf("Error on decimals of %s: %v", token, err)
 		}
 		if symbol != "" {
 			tokenMap[token] = &BalancerToken{
@@ -24,6 +24,12 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BancorExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], WatchdogDelay: watchdogDelayLong}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBancorScraper(exchange)
+	})
+}
+
 type BancorPool struct {
 	Reserves []struct {
 		DltID   string `json:"dlt_id"`
@@ -101,20 +107,12 @@ type BancorScraper struct {
 	pairScrapers   map[string]*BancorPairScraper
 	productPairIds map[string]int
 	chanTrades     chan *dia.Trade
+	// holds trades until their block is final
+	confirmations *confirmationBuffer
 }
 
 func NewBancorScraper(exchange dia.Exchange) *BancorScraper {
-	var wsClient, restClient *ethclient.Client
-
-	wsClient, err := ethclient.Dial(wsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	restClient, err = ethclient.Dial(restDial)
-	if err != nil {
-		log.Fatal(err)
-	}
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 
 	scraper := &BancorScraper{
 		exchangeName:   exchange.Name,
@@ -127,7 +125,9 @@ func NewBancorScraper(exchange dia.Exchange) *BancorScraper {
 		pairScrapers:   make(map[string]*BancorPairScraper),
 		chanTrades:     make(chan *dia.Trade),
 	}
+	scraper.confirmations = newConfirmationBuffer(exchange.BlockChain, restClient, connectRelDB(), func(t *dia.Trade) { scraper.chanTrades <- t })
 
+	go scraper.confirmations.run(scraper.shutdown)
 	go scraper.mainLoop()
 	return scraper
 }
@@ -145,6 +145,10 @@ func (scraper *BancorScraper) mainLoop() {
 		for {
 
 			rawSwap := <-sink
+			if rawSwap.Raw.Removed {
+				scraper.confirmations.retract(rawSwap.Raw)
+				continue
+			}
 			revRawSwap := reverseBNTSwap(*rawSwap)
 
 			var address []common.Address
@@ -166,12 +170,12 @@ func (scraper *BancorScraper) mainLoop() {
 				Price:          price,
 				Volume:         volume,
 				Time:           time.Now(),
-				ForeignTradeID: revRawSwap.Raw.TxHash.String(),
+				ForeignTradeID: revRawSwap.Raw.TxHash.String() + "-" + fmt.Sprint(revRawSwap.Raw.Index),
 				Source:         scraper.exchangeName,
 			}
 
 			log.Info("Got Trade: ", trade)
-			scraper.chanTrades <- trade
+			scraper.confirmations.add(trade, rawSwap.Raw)
 
 		}
 	}()
@@ -13,6 +13,12 @@ import (
 	utils "github.com/diadata-org/diadata/pkg/utils"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BinanceExchange, Centralized: true, WatchdogDelay: watchdogDelay, RequiresAPIKey: true}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBinanceScraper(key, secret, exchange)
+	})
+}
+
 type binancePairScraperSet map[*BinancePairScraper]nothing
 
 // BinanceScraper is a Scraper for collecting trades from the Binance websocket API
@@ -34,19 +40,21 @@ type BinanceScraper struct {
 	pairLocks         sync.Map // dia.Pair -> sync.Mutex
 	exchangeName      string
 	chanTrades        chan *dia.Trade
+	chanOrderBooks    chan *dia.OrderBookSnapshot
 }
 
 // NewBinanceScraper returns a new BinanceScraper for the given pair
 func NewBinanceScraper(apiKey string, secretKey string, exchange dia.Exchange) *BinanceScraper {
 
 	s := &BinanceScraper{
-		client:       binance.NewClient(apiKey, secretKey),
-		initDone:     make(chan nothing),
-		shutdown:     make(chan nothing),
-		shutdownDone: make(chan nothing),
-		exchangeName: exchange.Name,
-		error:        nil,
-		chanTrades:   make(chan *dia.Trade),
+		client:         binance.NewClient(apiKey, secretKey),
+		initDone:       make(chan nothing),
+		shutdown:       make(chan nothing),
+		shutdownDone:   make(chan nothing),
+		exchangeName:   exchange.Name,
+		error:          nil,
+		chanTrades:     make(chan *dia.Trade),
+		chanOrderBooks: make(chan *dia.OrderBookSnapshot),
 	}
 
 	// establish connection in the background
@@ -135,7 +143,8 @@ func (s *BinanceScraper) ScrapePair(pair dia.Pair) (PairScraper, error) {
 		price, err2 := strconv.ParseFloat(event.Price, 64)
 
 		if err == nil && err2 == nil && event.Event == "aggTrade" {
-			if !event.IsBuyerMaker {
+			// The buyer being the maker means the taker sold.
+			if event.IsBuyerMaker {
 				volume = -volume
 			}
 			pairNormalized, _ := s.NormalizePair(pair)
@@ -166,6 +175,60 @@ func (s *BinanceScraper) ScrapePair(pair dia.Pair) (PairScraper, error) {
 	return ps, err
 }
 
+// ScrapeOrderBook subscribes to the partial depth stream of @pair and emits
+// a snapshot of the top levels with every update.
+func (s *BinanceScraper) ScrapeOrderBook(pair dia.Pair) error {
+	<-s.initDone
+
+	if s.closed {
+		return errors.New("BinanceScraper: Call ScrapeOrderBook on closed scraper")
+	}
+
+	wsPartialDepthHandler := func(event *binance.WsPartialDepthEvent) {
+		ob := &dia.OrderBookSnapshot{
+			Pair:   pair.ForeignName,
+			Symbol: pair.Symbol,
+			Time:   time.Now(),
+			Source: s.exchangeName,
+		}
+		for _, bid := range event.Bids {
+			level, err := parseBinanceLevel(bid.Price, bid.Quantity)
+			if err != nil {
+				log.Errorf("parse bid of %s: %v", pair.ForeignName, err)
+				return
+			}
+			ob.Bids = append(ob.Bids, level)
+		}
+		for _, ask := range event.Asks {
+			level, err := parseBinanceLevel(ask.Price, ask.Quantity)
+			if err != nil {
+				log.Errorf("parse ask of %s: %v", pair.ForeignName, err)
+				return
+			}
+			ob.Asks = append(ob.Asks, level)
+		}
+		s.chanOrderBooks <- ob
+	}
+	errHandler := func(err error) {
+		log.Error(err)
+	}
+
+	_, _, err := binance.WsPartialDepthServe(pair.ForeignName, strconv.Itoa(orderBookLevels), wsPartialDepthHandler, errHandler)
+	if err != nil {
+		log.Errorf("serving order book of pair %s", pair.ForeignName)
+	}
+	return err
+}
+
+func parseBinanceLevel(price string, quantity string) (level dia.OrderBookLevel, err error) {
+	level.Price, err = strconv.ParseFloat(price, 64)
+	if err != nil {
+		return
+	}
+	level.Volume, err = strconv.ParseFloat(quantity, 64)
+	return
+}
+
 func (s *BinanceScraper) normalizeSymbol(p dia.Pair, foreignName string, params ...string) (pair dia.Pair, err error) {
 	symbol := p.Symbol
 	status := params[0]
@@ -251,6 +314,11 @@ func (ps *BinanceScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
 }
 
+// OrderBookChannel returns a channel that can be used to receive order book snapshots
+func (s *BinanceScraper) OrderBookChannel() chan *dia.OrderBookSnapshot {
+	return s.chanOrderBooks
+}
+
 // Error returns an error when the channel Channel() is closed
 // and nil otherwise
 func (ps *BinancePairScraper) Error() error {
@@ -0,0 +1,31 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestBinanceScraperReplay(t *testing.T) {
+	rs := newReplayServer(t, "binance")
+	rs.redirect()
+	start := time.Now()
+
+	s := NewBinanceScraper("", "", Exchanges[dia.BinanceExchange])
+	defer s.Close()
+	for _, pair := range []dia.Pair{
+		{Symbol: "BTC", ForeignName: "BTCUSDT"},
+		{Symbol: "MIOTA", ForeignName: "IOTABTC"},
+	} {
+		if _, err := s.ScrapePair(pair); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	checkTrades(t, start, collectTrades(t, s.Channel(), 3), []dia.Trade{
+		{Symbol: "BTC", Pair: "BTCUSDT", Price: 47012.34, Volume: 0.00213, Time: time.Unix(1630050001, 123*int64(time.Millisecond)), ForeignTradeID: "357ae07f", Source: dia.BinanceExchange},
+		{Symbol: "BTC", Pair: "BTCUSDT", Price: 47012.33, Volume: -0.15, Time: time.Unix(1630050001, 456*int64(time.Millisecond)), ForeignTradeID: "357ae080", Source: dia.BinanceExchange},
+		{Symbol: "MIOTA", Pair: "MIOTABTC", Price: 0.00002051, Volume: -1250, Time: time.Unix(1630050002, 1*int64(time.Millisecond)), ForeignTradeID: "1454c92", Source: dia.BinanceExchange},
+	})
+}
@@ -14,6 +14,12 @@ import (
 	"github.com/diadata-org/diadata/pkg/utils"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BitBayExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBitBayScraper(exchange)
+	})
+}
+
 // API base url
 const apiURL string = "https://bitbay.net/API/Public/"
 
@@ -15,6 +15,12 @@ import (
 	utils "github.com/diadata-org/diadata/pkg/utils"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BitfinexExchange, Centralized: true, WatchdogDelay: watchdogDelay, RequiresAPIKey: true}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBitfinexScraper(key, secret, exchange)
+	})
+}
+
 type pairScraperSet map[*BitfinexPairScraper]nothing
 
 // BitfinexScraper is a Scraper for collecting trades from the Bitfinex websocket API
@@ -15,6 +15,12 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BitMaxExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBitMaxScraper(exchange)
+	})
+}
+
 type BitMaxPairResponse struct {
 	Code int          `json:"code"`
 	Data []BitMaxPair `json:"data"`
@@ -184,7 +190,7 @@ func (s *BitMaxScraper) ScrapePair(pair dia.Pair) (PairScraper, error) {
 	a := &BitMaxRequest{
 		Op: "sub",
 		Ch: "trades:" + pair.ForeignName,
-		ID: string(time.Now().Unix()),
+		ID: strconv.FormatInt(time.Now().Unix(), 10),
 	}
 
 	if err := s.wsClient.WriteJSON(a); err != nil {
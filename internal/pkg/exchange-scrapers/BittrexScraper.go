@@ -14,6 +14,12 @@ import (
 	utils "github.com/diadata-org/diadata/pkg/utils"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.BittrexExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewBittrexScraper(exchange)
+	})
+}
+
 type ConfirmData struct {
 	Result  []interface{} `json:"result"`
 	Success bool          `json:"success"`
@@ -14,6 +14,12 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.CREX24Exchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewCREX24Scraper(exchange)
+	})
+}
+
 type CREX24ApiInstrument struct {
 	Symbol       string `json:"symbol"`
 	BaseCurrency string `json:"baseCurrency"`
@@ -215,6 +221,11 @@ type CREX24PairScraper struct {
 }
 
 func (ps *CREX24PairScraper) Close() error {
+	return ps.Unsubscribe()
+}
+
+// Unsubscribe leaves the trade history of the pair
+func (ps *CREX24PairScraper) Unsubscribe() error {
 	s := ps.parent
 	if s.closed {
 		return errors.New("CREX24Scraper: Scraper already closed")
@@ -3,9 +3,11 @@ package scrapers
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
@@ -14,6 +16,12 @@ import (
 	gdax "github.com/preichenberger/go-coinbasepro/v2"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.CoinBaseExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewCoinBaseScraper(exchange)
+	})
+}
+
 type CoinBaseScraper struct {
 	// signaling channels
 	shutdown     chan nothing
@@ -27,6 +35,18 @@ type CoinBaseScraper struct {
 	wsConn       *ws.Conn
 	exchangeName string
 	chanTrades   chan *dia.Trade
+	// level2 books are only accessed from mainLoop
+	orderBooks     map[string]*coinBaseOrderBook // pair.ForeignName -> book
+	orderBookPairs sync.Map                      // pair.ForeignName -> dia.Pair
+	chanOrderBooks chan *dia.OrderBookSnapshot
+}
+
+// coinBaseOrderBook is the local copy of a level2 book, kept up to date by
+// applying l2update messages to the initial snapshot.
+type coinBaseOrderBook struct {
+	bids         map[string]float64 // price -> size
+	asks         map[string]float64
+	lastSnapshot time.Time
 }
 
 const (
@@ -36,6 +56,9 @@ const (
 	ChannelUser      = "user"
 	ChannelMatches   = "matches"
 	ChannelFull      = "full"
+
+	// minimal time between two order book snapshots of a pair
+	coinBaseOrderBookDelay = time.Second * 10
 )
 
 // NewCoinBaseScraper returns a new CoinBaseScraper initialized with default values.
@@ -48,6 +71,9 @@ func NewCoinBaseScraper(exchange dia.Exchange) *CoinBaseScraper {
 		exchangeName: exchange.Name,
 		error:        nil,
 		chanTrades:   make(chan *dia.Trade),
+
+		orderBooks:     make(map[string]*coinBaseOrderBook),
+		chanOrderBooks: make(chan *dia.OrderBookSnapshot),
 	}
 	var wsDialer ws.Dialer
 	SwConn, _, err := wsDialer.Dial("wss://ws-feed.pro.coinbase.com", nil)
@@ -68,6 +94,10 @@ func (s *CoinBaseScraper) mainLoop() {
 			println(err.Error())
 			break
 		}
+		if message.Type == "snapshot" || message.Type == "l2update" {
+			s.handleLevel2(message)
+			continue
+		}
 		if message.Type == ChannelTicker {
 			ps, ok := s.pairScrapers[message.ProductID]
 			if ok {
@@ -105,6 +135,96 @@ func (s *CoinBaseScraper) mainLoop() {
 	s.cleanup(err)
 }
 
+// handleLevel2 applies a level2 message to the local book of its product and
+// emits a snapshot at most every coinBaseOrderBookDelay.
+// must only be called from mainLoop
+func (s *CoinBaseScraper) handleLevel2(message gdax.Message) {
+	v, ok := s.orderBookPairs.Load(message.ProductID)
+	if !ok {
+		log.Error("unknown order book product " + message.ProductID)
+		return
+	}
+	pair := v.(dia.Pair)
+
+	if message.Type == "snapshot" {
+		book := &coinBaseOrderBook{
+			bids: make(map[string]float64),
+			asks: make(map[string]float64),
+		}
+		for _, entry := range message.Bids {
+			book.update(book.bids, entry.Price, entry.Size)
+		}
+		for _, entry := range message.Asks {
+			book.update(book.asks, entry.Price, entry.Size)
+		}
+		s.orderBooks[message.ProductID] = book
+		return
+	}
+
+	book, ok := s.orderBooks[message.ProductID]
+	if !ok {
+		return
+	}
+	for _, change := range message.Changes {
+		if change.Side == "buy" {
+			book.update(book.bids, change.Price, change.Size)
+		} else {
+			book.update(book.asks, change.Price, change.Size)
+		}
+	}
+
+	timestamp := message.Time.Time()
+	if timestamp.Sub(book.lastSnapshot) < coinBaseOrderBookDelay {
+		return
+	}
+	book.lastSnapshot = timestamp
+	s.chanOrderBooks <- &dia.OrderBookSnapshot{
+		Pair:   pair.ForeignName,
+		Symbol: pair.Symbol,
+		Bids:   topLevels(book.bids, true),
+		Asks:   topLevels(book.asks, false),
+		Time:   timestamp,
+		Source: s.exchangeName,
+	}
+}
+
+func (book *coinBaseOrderBook) update(side map[string]float64, price string, size string) {
+	f64Size, err := strconv.ParseFloat(size, 64)
+	if err != nil {
+		log.Error("error parsing size " + size)
+		return
+	}
+	if f64Size == 0 {
+		delete(side, price)
+		return
+	}
+	side[price] = f64Size
+}
+
+// topLevels returns the orderBookLevels best levels of @side, which are the
+// highest prices for bids and the lowest for asks.
+func topLevels(side map[string]float64, descending bool) []dia.OrderBookLevel {
+	levels := make([]dia.OrderBookLevel, 0, len(side))
+	for price, size := range side {
+		f64Price, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			log.Error("error parsing price " + price)
+			continue
+		}
+		levels = append(levels, dia.OrderBookLevel{Price: f64Price, Volume: size})
+	}
+	sort.Slice(levels, func(i, j int) bool {
+		if descending {
+			return levels[i].Price > levels[j].Price
+		}
+		return levels[i].Price < levels[j].Price
+	})
+	if len(levels) > orderBookLevels {
+		levels = levels[:orderBookLevels]
+	}
+	return levels
+}
+
 // closes all connected PairScrapers
 // must only be called from mainLoop
 func (s *CoinBaseScraper) cleanup(err error) {
@@ -235,6 +355,36 @@ func (s *CoinBaseScraper) ScrapePair(pair dia.Pair) (PairScraper, error) {
 	return ps, nil
 }
 
+// ScrapeOrderBook subscribes to the level2 channel of @pair.
+func (s *CoinBaseScraper) ScrapeOrderBook(pair dia.Pair) error {
+	s.errorLock.RLock()
+	defer s.errorLock.RUnlock()
+	if s.error != nil {
+		return s.error
+	}
+	if s.closed {
+		return errors.New("CoinBaseScraper: Call ScrapeOrderBook on closed scraper")
+	}
+
+	s.orderBookPairs.Store(pair.ForeignName, pair)
+
+	subscribe := gdax.Message{
+		Type: "subscribe",
+		Channels: []gdax.MessageChannel{
+			{
+				Name:       ChannelLevel2,
+				ProductIds: []string{pair.ForeignName},
+			},
+		},
+	}
+	return s.wsConn.WriteJSON(subscribe)
+}
+
+// OrderBookChannel returns a channel that can be used to receive order book snapshots
+func (s *CoinBaseScraper) OrderBookChannel() chan *dia.OrderBookSnapshot {
+	return s.chanOrderBooks
+}
+
 // Channel returns a channel that can be used to receive trades/pricing information
 func (ps *CoinBaseScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
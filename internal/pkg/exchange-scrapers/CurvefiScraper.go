@@ -19,11 +19,15 @@ import (
 	"github.com/diadata-org/diadata/internal/pkg/exchange-scrapers/curvefi/token"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.CurveFIExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], Contract: common.HexToAddress("0x7002B727Ef8F5571Cb5F9D70D13DBEEb4dFAe9d1"), WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewCurveFIScraper(exchange)
+	})
+}
+
 const (
 	curveFiContract       = "0x7002B727Ef8F5571Cb5F9D70D13DBEEb4dFAe9d1"
 	curveFiLookBackBlocks = 6 * 60 * 24 * 20
-	curveWsDial           = "ws://159.69.120.42:8546/"
-	curveRestDial         = "http://159.69.120.42:8545/"
 )
 
 type CurveCoin struct {
@@ -88,9 +92,12 @@ type CurveFIScraper struct {
 	resubscribe chan string
 	pools       *Pools
 	contract    common.Address
+	// holds trades until their block is final
+	confirmations *confirmationBuffer
 }
 
 func NewCurveFIScraper(exchange dia.Exchange) *CurveFIScraper {
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 	scraper := &CurveFIScraper{
 		exchangeName:   exchange.Name,
 		contract:       exchange.Contract,
@@ -107,18 +114,12 @@ func NewCurveFIScraper(exchange dia.Exchange) *CurveFIScraper {
 		},
 	}
 
-	wsClient, err := ethclient.Dial(curveWsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.WsClient = wsClient
-	restClient, err := ethclient.Dial(curveRestDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.RestClient = restClient
+	scraper.confirmations = newConfirmationBuffer(exchange.BlockChain, restClient, connectRelDB(), func(t *dia.Trade) { scraper.chanTrades <- t })
 
 	scraper.loadPoolsAndCoins()
+	go scraper.confirmations.run(scraper.shutdown)
 
 	go scraper.mainLoop()
 	return scraper
@@ -279,6 +280,10 @@ func (scraper *CurveFIScraper) loadPoolData(pool string) error {
 }
 
 func (scraper *CurveFIScraper) processSwap(pool string, swp *curvepool.CurvepoolTokenExchange) {
+	if swp.Raw.Removed {
+		scraper.confirmations.retract(swp.Raw)
+		return
+	}
 
 	foreignName, volume, price, err := scraper.getSwapDataCurve(pool, swp)
 	if err != nil {
@@ -299,7 +304,7 @@ func (scraper *CurveFIScraper) processSwap(pool string, swp *curvepool.Curvepool
 		}
 		log.Infoln("Got Trade  ", trade)
 
-		scraper.chanTrades <- trade
+		scraper.confirmations.add(trade, swp.Raw)
 	}
 }
 
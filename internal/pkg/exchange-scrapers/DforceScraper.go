@@ -19,9 +19,13 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.DforceExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], Contract: common.HexToAddress("0x03eF3f37856bD08eb47E2dE7ABc4Ddd2c19B60F2"), WatchdogDelay: watchdogDelayLong}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewDforceScraper(exchange)
+	})
+}
+
 const (
-	dforceWsDial         = "ws://159.69.120.42:8546/"
-	dforceRestDial       = "http://159.69.120.42:8545/"
 	dforceLookBackBlocks = 6 * 60 * 24 * 20
 )
 
@@ -55,6 +59,7 @@ type DforceScraper struct {
 }
 
 func NewDforceScraper(exchange dia.Exchange) *DforceScraper {
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 	scraper := &DforceScraper{
 		contract:       exchange.Contract,
 		exchangeName:   exchange.Name,
@@ -68,15 +73,7 @@ func NewDforceScraper(exchange dia.Exchange) *DforceScraper {
 		tokens:         make(map[string]*DforceToken),
 	}
 
-	wsClient, err := ethclient.Dial(dforceWsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.WsClient = wsClient
-	restClient, err := ethclient.Dial(dforceRestDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.RestClient = restClient
 
 	scraper.loadTokens()
@@ -1,10 +1,13 @@
 package scrapers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
 	"os/signal"
+	"regexp"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -12,31 +15,56 @@ import (
 	zap "go.uber.org/zap"
 
 	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/gorilla/websocket"
 )
 
 const scrapeDataSaveLocationBitflyer = ""
 
+// futures markets are named like BTCJPY27MAR2020, the FX markets like FX_BTC_JPY have no expiry
+var bitflyerFuturesMarket = regexp.MustCompile(`^([A-Z]{3})[A-Z]{3}(\d{2}[A-Z]{3}\d{4})$`)
+
+// bitflyerExecutionsMessage is a message of a lightning_executions channel
+type bitflyerExecutionsMessage struct {
+	Method string `json:"method"`
+	Params struct {
+		Channel string `json:"channel"`
+		Message []struct {
+			ID       int64     `json:"id"`
+			Side     string    `json:"side"`
+			Price    float64   `json:"price"`
+			Size     float64   `json:"size"`
+			ExecDate time.Time `json:"exec_date"`
+		} `json:"message"`
+	} `json:"params"`
+}
+
 // BitflyerScraper - use the NewBitflyerFuturesScraper function to create an instance
 type BitflyerScraper struct {
 	Markets   []string
 	WaitGroup *sync.WaitGroup
 	Writer    writers.Writer
 	Logger    *zap.SugaredLogger
+
+	chanTrades chan *dia.FuturesTrade
 }
 
-// NewBitflyerFuturesScraper - returns an instance of an options scraper.
-func NewBitflyerFuturesScraper(markets []string) FuturesScraper {
+// NewBitflyerFuturesScraper - returns an instance of an options scraper. Messages are written by @writer, to text files if nil.
+func NewBitflyerFuturesScraper(markets []string, writer writers.Writer) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
+	if writer == nil {
+		writer = &writers.FileWriter{}
+	}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &BitflyerScraper{
 		WaitGroup: &wg,
 		Markets:   markets,
-		Writer:    &writer,
+		Writer:    writer,
 		Logger:    logger,
+
+		chanTrades: make(chan *dia.FuturesTrade),
 	}
 
 	return scraper
@@ -63,14 +91,16 @@ func (s *BitflyerScraper) Authenticate(market string, connection interface{}) er
 func (s *BitflyerScraper) ScraperClose(market string, connection interface{}) error {
 	switch c := connection.(type) {
 	case *websocket.Conn:
-		// unsubscribe from the channel
-		err := s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "unsubscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, c)
-		if err != nil {
-			s.Logger.Errorf("could not send a channel unsubscription message, err: %s", err)
-			return err
+		// unsubscribe from the channels
+		for _, channel := range []string{"lightning_ticker_", "lightning_executions_"} {
+			err := s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "unsubscribe", "params": &map[string]interface{}{"channel": channel + market}}, market, c)
+			if err != nil {
+				s.Logger.Errorf("could not send a channel unsubscription message, err: %s", err)
+				return err
+			}
 		}
 		// close the websocket connection
-		err = s.write(websocket.CloseMessage, []byte{}, c)
+		err := s.write(websocket.CloseMessage, []byte{}, c)
 		if err != nil {
 			return err
 		}
@@ -114,10 +144,12 @@ func (s *BitflyerScraper) Scrape(market string) {
 				s.Logger.Debugf("received a pong frame")
 				return nil
 			})
-			err = s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "subscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, ws)
-			if err != nil {
-				s.Logger.Errorf("could not send a channel subscription message. retrying, err: %s", err)
-				return
+			for _, channel := range []string{"lightning_ticker_", "lightning_executions_"} {
+				err = s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "subscribe", "params": &map[string]interface{}{"channel": channel + market}}, market, ws)
+				if err != nil {
+					s.Logger.Errorf("could not send a channel subscription message. retrying, err: %s", err)
+					return
+				}
 			}
 			tick := time.NewTicker(15 * time.Second)
 			defer tick.Stop()
@@ -152,6 +184,14 @@ func (s *BitflyerScraper) Scrape(market string) {
 						s.Logger.Errorf("could not write to file, err: %s", err)
 						return
 					}
+					trades, err := parseBitflyerFuturesTrades(message, market)
+					if err != nil {
+						s.Logger.Errorf("could not parse the trades of [%s], err: %s", market, err)
+						continue
+					}
+					for _, trade := range trades {
+						s.chanTrades <- trade
+					}
 				}
 			}
 		}()
@@ -164,6 +204,42 @@ func (s *BitflyerScraper) write(mt int, payload []byte, ws *websocket.Conn) erro
 	return ws.WriteMessage(mt, payload)
 }
 
+// parseBitflyerFuturesTrades returns the trades of a lightning_executions message of @market, nil for other messages.
+func parseBitflyerFuturesTrades(message []byte, market string) ([]*dia.FuturesTrade, error) {
+	var msg bitflyerExecutionsMessage
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Method != "channelMessage" || msg.Params.Channel != "lightning_executions_"+market {
+		return nil, nil
+	}
+	contract, err := bitflyerContract(market)
+	if err != nil {
+		return nil, err
+	}
+	var trades []*dia.FuturesTrade
+	for _, t := range msg.Params.Message {
+		trades = append(trades, contract.trade("Bitflyer", market, t.Price, t.Size, t.Side, t.ExecDate, fmt.Sprint(t.ID)))
+	}
+	return trades, nil
+}
+
+// bitflyerContract derives the contract from the name of @market.
+func bitflyerContract(market string) (futuresContract, error) {
+	if m := bitflyerFuturesMarket.FindStringSubmatch(market); m != nil {
+		expiry, err := time.Parse("02Jan2006", m[2])
+		if err != nil {
+			return futuresContract{}, err
+		}
+		return futuresContract{Underlying: m[1], Expiry: expiry}, nil
+	}
+	if parts := strings.Split(market, "_"); len(parts) == 3 && parts[0] == "FX" {
+		return futuresContract{Underlying: parts[1], Perpetual: true}, nil
+	}
+	return futuresContract{}, fmt.Errorf("unknown market %s", market)
+}
+
 // ScrapeMarkets - will scrape the markets specified during instantiation
 func (s *BitflyerScraper) ScrapeMarkets() {
 	for _, market := range s.Markets {
@@ -173,10 +249,15 @@ func (s *BitflyerScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// Channel returns the channel on which the trades of all markets are sent
+func (s *BitflyerScraper) Channel() chan *dia.FuturesTrade {
+	return s.chanTrades
+}
+
 // usage example
 // func main() {
 // 	wg := sync.WaitGroup{}
-// 	futuresBitflyer := scrapers.NewBitflyerFuturesScraper([]string{"BTCJPY27DEC2019", "BTCJPY03JAN2020", "BTCJPY27MAR2020"})
+// 	futuresBitflyer := scrapers.NewBitflyerFuturesScraper([]string{"BTCJPY27DEC2019", "BTCJPY03JAN2020", "BTCJPY27MAR2020"}, nil)
 // 	futuresBitflyer.ScrapeMarkets()
 // 	wg.Wait()
 // }
@@ -1,6 +1,7 @@
 package scrapers
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -13,30 +14,58 @@ import (
 
 	"github.com/gorilla/websocket"
 	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
+	utils "github.com/diadata-org/diadata/pkg/utils"
 )
 
 const scrapeDataSaveLocationBitmex = ""
 
 // BitmexScraper - use the NewBitmexFuturesScraper function to create an instance
 type BitmexScraper struct {
-	Markets   []string
-	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer
-	Logger    *zap.SugaredLogger
+	Markets    []string
+	WaitGroup  *sync.WaitGroup
+	Writer     writers.Writer
+	Logger     *zap.SugaredLogger
+	chanTrades chan *dia.FuturesTrade
 }
 
-// NewBitmexFuturesScraper - returns an instance of an options scraper.
-func NewBitmexFuturesScraper(markets []string) FuturesScraper {
+// bitmexInstrument is an entry of https://www.bitmex.com/api/v1/instrument
+type bitmexInstrument struct {
+	Symbol     string     `json:"symbol"`
+	Underlying string     `json:"underlying"`
+	Typ        string     `json:"typ"` // FFWCSX for perpetual swaps, FFCCSX for dated futures
+	Expiry     *time.Time `json:"expiry"`
+}
+
+// bitmexTradeMessage is a message of the trade channel
+type bitmexTradeMessage struct {
+	Table  string `json:"table"`
+	Action string `json:"action"`
+	Data   []struct {
+		Timestamp  time.Time `json:"timestamp"`
+		Symbol     string    `json:"symbol"`
+		Side       string    `json:"side"`
+		Size       float64   `json:"size"`
+		Price      float64   `json:"price"`
+		TrdMatchID string    `json:"trdMatchID"`
+	} `json:"data"`
+}
+
+// NewBitmexFuturesScraper - returns an instance of an options scraper. Messages are written by @writer, to text files if nil.
+func NewBitmexFuturesScraper(markets []string, writer writers.Writer) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
+	if writer == nil {
+		writer = &writers.FileWriter{}
+	}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &BitmexScraper{
-		WaitGroup: &wg,
-		Markets:   markets,
-		Writer:    &writer,
-		Logger:    logger,
+		WaitGroup:  &wg,
+		Markets:    markets,
+		Writer:     writer,
+		Logger:     logger,
+		chanTrades: make(chan *dia.FuturesTrade),
 	}
 
 	return scraper
@@ -98,9 +127,19 @@ func (s *BitmexScraper) Scrape(market string) {
 		userCancelled <- true
 	}()
 
+	var contract *futuresContract
 	for {
 		// immediately invoked function expression for easy clenup with defer
 		func() {
+			if contract == nil {
+				c, err := bitmexContract(market)
+				if err != nil {
+					s.Logger.Errorf("could not get the contract of %s: %s", market, err)
+					time.Sleep(time.Duration(retryIn) * time.Second)
+					return
+				}
+				contract = &c
+			}
 			u := url.URL{Scheme: "wss", Host: "www.bitmex.com", Path: "/realtime"}
 			s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
 			ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
@@ -152,6 +191,14 @@ func (s *BitmexScraper) Scrape(market string) {
 						s.Logger.Errorf("could not write to file, err: %s", err)
 						return
 					}
+					trades, err := parseBitmexFuturesTrades(message, *contract)
+					if err != nil {
+						s.Logger.Errorf("could not parse bitmex message on [%s], err: %s", market, err)
+						continue
+					}
+					for _, trade := range trades {
+						s.chanTrades <- trade
+					}
 				}
 			}
 		}()
@@ -173,10 +220,61 @@ func (s *BitmexScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// Channel returns the channel on which the trades of all markets are sent
+func (s *BitmexScraper) Channel() chan *dia.FuturesTrade {
+	return s.chanTrades
+}
+
+// bitmexContract returns the contract of @market
+func bitmexContract(market string) (futuresContract, error) {
+	body, err := utils.GetRequest("https://www.bitmex.com/api/v1/instrument?symbol=" + market)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	var instruments []bitmexInstrument
+	err = json.Unmarshal(body, &instruments)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	if len(instruments) == 0 {
+		return futuresContract{}, fmt.Errorf("unknown market %s", market)
+	}
+	return instruments[0].contract(), nil
+}
+
+func (i bitmexInstrument) contract() futuresContract {
+	c := futuresContract{Underlying: i.Underlying, Perpetual: i.Typ == "FFWCSX"}
+	if c.Underlying == "XBT" {
+		c.Underlying = "BTC"
+	}
+	if i.Expiry != nil && !c.Perpetual {
+		c.Expiry = *i.Expiry
+	}
+	return c
+}
+
+// parseBitmexFuturesTrades returns the trades of a message of the trade channel. Other messages,
+// as well as the snapshot of recent trades sent on subscription, contain no trades.
+func parseBitmexFuturesTrades(message []byte, contract futuresContract) ([]*dia.FuturesTrade, error) {
+	var msg bitmexTradeMessage
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Table != "trade" || msg.Action != "insert" {
+		return nil, nil
+	}
+	var trades []*dia.FuturesTrade
+	for _, t := range msg.Data {
+		trades = append(trades, contract.trade("Bitmex", t.Symbol, t.Price, t.Size, t.Side, t.Timestamp, t.TrdMatchID))
+	}
+	return trades, nil
+}
+
 // usage example
 // func main() {
 // 	wg := sync.WaitGroup{}
-// 	futuresBitmex := scrapers.NewBitmexFuturesScraper([]string{"XBTUSD", "XBTZ19", "XBTH20", "XBTM20", "ETHUSD", "ETHZ19", "ETHH20"})
+// 	futuresBitmex := scrapers.NewBitmexFuturesScraper([]string{"XBTUSD", "XBTZ19", "XBTH20", "XBTM20", "ETHUSD", "ETHZ19", "ETHH20"}, nil)
 // 	futuresBitmex.ScrapeMarkets()
 // 	wg.Wait()
 // }
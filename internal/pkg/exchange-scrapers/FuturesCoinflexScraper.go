@@ -12,6 +12,7 @@ import (
 	"time"
 
 	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
@@ -25,6 +26,16 @@ type CoinflexFuturesScraper struct {
 	WaitGroup *sync.WaitGroup
 	Writer    writers.Writer
 	Logger    *zap.SugaredLogger
+
+	chanTrades chan *dia.FuturesTrade
+}
+
+// contractCoinflex is the contract of a market and the scales of its assets, which
+// divide the integer prices and quantities of the market's messages.
+type contractCoinflex struct {
+	futuresContract
+	BaseScale    int64
+	CounterScale int64
 }
 
 type tradeMessageCoinflex struct {
@@ -82,18 +93,22 @@ type ordersMatchedCoinflex struct {
 	AskCounterFee int64  `json:"ask_counter_fee"`
 }
 
-// NewCoinflexFuturesScraper - returns an instance of the coinflex scraper
-func NewCoinflexFuturesScraper(markets []string) FuturesScraper {
+// NewCoinflexFuturesScraper - returns an instance of the coinflex scraper. Messages are written by @writer, to text files if nil.
+func NewCoinflexFuturesScraper(markets []string, writer writers.Writer) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
+	if writer == nil {
+		writer = &writers.FileWriter{}
+	}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &CoinflexFuturesScraper{
 		WaitGroup: &wg,
 		Markets:   markets,
-		Writer:    &writer,
+		Writer:    writer,
 		Logger:    logger,
+
+		chanTrades: make(chan *dia.FuturesTrade),
 	}
 
 	return scraper
@@ -151,6 +166,11 @@ func (s *CoinflexFuturesScraper) Scrape(market string) {
 		s.Logger.Errorf("issue with getting an id for base and quote: %s", err)
 		return
 	}
+	contract, err := s.contract(market)
+	if err != nil {
+		s.Logger.Errorf("issue with getting the contract of %s: %s", market, err)
+		return
+	}
 
 	// this block is for listening to sigterms and interupts
 	sigs := make(chan os.Signal, 1)
@@ -233,6 +253,7 @@ func (s *CoinflexFuturesScraper) Scrape(market string) {
 							s.Logger.Errorf("could not save to file: %s, on market: [%s], err: %s", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market), market, err)
 							return
 						}
+						s.chanTrades <- msg.trade(market, contract)
 					}
 				}
 			}
@@ -255,6 +276,59 @@ func (s *CoinflexFuturesScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// Channel returns the channel on which the trades of all markets are sent
+func (s *CoinflexFuturesScraper) Channel() chan *dia.FuturesTrade {
+	return s.chanTrades
+}
+
+// trade returns the trade of an OrdersMatched message of @market. The side is the one of the order
+// which was filled completely, as coinflex does not tell the taker.
+func (msg ordersMatchedCoinflex) trade(market string, contract contractCoinflex) *dia.FuturesTrade {
+	side := dia.FuturesBuy
+	if msg.BidRem == 0 && msg.AskRem != 0 {
+		side = dia.FuturesSell
+	}
+	price := float64(msg.Price) / float64(contract.CounterScale)
+	quantity := float64(msg.Quantity) / float64(contract.BaseScale)
+	return contract.trade("Coinflex", market, price, quantity, side, time.Unix(0, msg.Time*1e3), fmt.Sprintf("%d-%d", msg.Bid, msg.Ask))
+}
+
+// contract returns the contract of @market, e.g. BTCDEC/USDTDEC, and the scales of its assets
+func (s *CoinflexFuturesScraper) contract(market string) (contractCoinflex, error) {
+	var contract contractCoinflex
+	markets, err := s.availableMarketsCoinflex()
+	if err != nil {
+		return contract, err
+	}
+	for _, m := range markets {
+		if m.Name == market && m.Expires > 0 {
+			contract.Expiry = time.Unix(0, m.Expires*1e6).UTC()
+		}
+	}
+	contract.Perpetual = contract.Expiry.IsZero()
+	assets, err := s.getAllAssets()
+	if err != nil {
+		return contract, err
+	}
+	names := strings.Split(market, "/")
+	for _, asset := range assets {
+		switch asset.Name {
+		case names[0]:
+			contract.BaseScale = asset.Scale
+			contract.Underlying = asset.SpotName
+			if contract.Underlying == "" {
+				contract.Underlying = asset.Name
+			}
+		case names[len(names)-1]:
+			contract.CounterScale = asset.Scale
+		}
+	}
+	if contract.BaseScale <= 0 || contract.CounterScale <= 0 {
+		return contract, fmt.Errorf("no scale for the assets of %s", market)
+	}
+	return contract, nil
+}
+
 func (s *CoinflexFuturesScraper) getBaseAndCounterID(market string) (int64, int64, error) {
 	assets := strings.Split(market, "/")
 	var baseID int64 = 0
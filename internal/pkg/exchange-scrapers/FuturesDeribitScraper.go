@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
 
+	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
@@ -38,6 +42,23 @@ type ParsedDeribitResponseParams struct {
 	Data    ParsedDeribitOptionOrderbookEntry `json:"data"`
 }
 
+// deribitTradesMessage is a message of a trades channel, or a heartbeat
+type deribitTradesMessage struct {
+	Method string `json:"method"`
+	Params struct {
+		Type    string `json:"type"`
+		Channel string `json:"channel"`
+		Data    []struct {
+			TradeID        string  `json:"trade_id"`
+			Timestamp      int64   `json:"timestamp"`
+			Price          float64 `json:"price"`
+			InstrumentName string  `json:"instrument_name"`
+			Direction      string  `json:"direction"`
+			Amount         float64 `json:"amount"`
+		} `json:"data"`
+	} `json:"params"`
+}
+
 type ParsedDeribitOptionOrderbookEntry struct {
 	Timestamp      int64       `json:"timestamp"`
 	InstrumentName string      `json:"instrument_name"`
@@ -47,8 +68,12 @@ type ParsedDeribitOptionOrderbookEntry struct {
 }
 
 // NewDeribitFuturesScraper - creates a deribit futures scraper for you for the markets that you supply. Some of the markets available are: "BTC-PERPETUAL" and "ETH-PERPETUAL".
-func NewDeribitFuturesScraper(markets []string, accessKey string, accessSecret string) FuturesScraper {
+// Messages are written by @writer, to text files if nil.
+func NewDeribitFuturesScraper(markets []string, accessKey string, accessSecret string, writer writers.Writer) FuturesScraper {
 	wg := sync.WaitGroup{}
+	if writer == nil {
+		writer = &writers.FileWriter{}
+	}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
@@ -56,6 +81,7 @@ func NewDeribitFuturesScraper(markets []string, accessKey string, accessSecret s
 		WaitGroup: &wg,
 		Markets:   markets, // e.g. []string{"BTC-PERPETUAL", "ETH-PERPETUAL"}
 		Logger:    logger,
+		Writer:    writer,
 
 		AccessKey:    accessKey,
 		AccessSecret: accessSecret,
@@ -63,6 +89,7 @@ func NewDeribitFuturesScraper(markets []string, accessKey string, accessSecret s
 		// expiry is 900 seconds
 		RefreshTokenEvery: 800,
 		MarketKind:        DeribitFuture, // DO NOT change this.
+		chanTrades:        make(chan *dia.FuturesTrade),
 	}
 
 	return &scraper
@@ -100,6 +127,11 @@ func (s *DeribitScraper) Scrape(market string) {
 	}
 	s.validateRefreshEveryToken()
 
+	if s.MarketKind == DeribitFuture {
+		s.scrapeFutures(market)
+		return
+	}
+
 	optionRequest := &map[string]interface{}{
 		"method": "public/subscribe",
 		"params": &map[string]interface{}{
@@ -108,18 +140,8 @@ func (s *DeribitScraper) Scrape(market string) {
 		"jsonrpc": "2.0",
 		"id":      0,
 	}
-	futureRequest := &map[string]interface{}{
-		"method": "private/subscribe",
-		"params": &map[string]interface{}{
-			"channels": []string{"trades." + market + ".raw"},
-		},
-		"jsonrpc": "2.0",
-		"id":      0,
-	}
 
 	switch s.MarketKind {
-	case DeribitFuture:
-		err = s.send(futureRequest, s.WsConnection)
 	case DeribitOption:
 		err = s.send(optionRequest, s.WsConnection)
 	default:
@@ -132,6 +154,123 @@ func (s *DeribitScraper) Scrape(market string) {
 	}
 }
 
+// scrapeFutures sends the public trades of the futures @market on the trades channel. Each market
+// has its own connection, which is reestablished on errors.
+func (s *DeribitScraper) scrapeFutures(market string) {
+	var contract *futuresContract
+	var contractSize float64
+	for {
+		// immediately invoked function expression for easy clenup with defer
+		func() {
+			if contract == nil {
+				c, size, err := deribitContract(market)
+				if err != nil {
+					s.Logger.Errorf("could not get the contract of %s: %s", market, err)
+					time.Sleep(time.Duration(retryIn) * time.Second)
+					return
+				}
+				contract, contractSize = &c, size
+			}
+			u := url.URL{Scheme: "wss", Host: "www.deribit.com", Path: "/ws/api/v2/"}
+			ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
+			if err != nil {
+				s.Logger.Errorf("could not dial deribit websocket: %s", err)
+				time.Sleep(time.Duration(retryIn) * time.Second)
+				return
+			}
+			defer ws.Close()
+			err = s.send(&map[string]interface{}{
+				"method":  "public/set_heartbeat",
+				"params":  &map[string]interface{}{"interval": 30},
+				"jsonrpc": "2.0",
+				"id":      1,
+			}, ws)
+			if err == nil {
+				err = s.send(&map[string]interface{}{
+					"method":  "public/subscribe",
+					"params":  &map[string]interface{}{"channels": []string{"trades." + market + ".100ms"}},
+					"jsonrpc": "2.0",
+					"id":      2,
+				}, ws)
+			}
+			if err != nil {
+				s.Logger.Errorf("could not subscribe to the trades of %s, err: %s", market, err)
+				return
+			}
+			for {
+				_, message, err := ws.ReadMessage()
+				if err != nil {
+					s.Logger.Errorf("problem reading deribit on [%s], err: %s", market, err)
+					return
+				}
+				_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationDeribit+s.Writer.GetWriteFileName("deribit", market))
+				if err != nil {
+					s.Logger.Errorf("could not write to file, err: %s", err)
+					return
+				}
+				var msg deribitTradesMessage
+				err = json.Unmarshal(message, &msg)
+				if err != nil {
+					s.Logger.Errorf("could not unmarshal deribit message on [%s], err: %s", market, err)
+					continue
+				}
+				if msg.Method == "heartbeat" && msg.Params.Type == "test_request" {
+					err = s.send(&map[string]interface{}{"method": "public/test", "jsonrpc": "2.0", "id": 3}, ws)
+					if err != nil {
+						s.Logger.Errorf("could not answer the heartbeat on [%s], err: %s", market, err)
+						return
+					}
+					continue
+				}
+				for _, trade := range msg.trades(*contract, contractSize) {
+					s.chanTrades <- trade
+				}
+			}
+		}()
+	}
+}
+
+// trades returns the trades of a message of a trades channel. Deribit gives amounts in USD
+// for inverse contracts, which are converted to a number of contracts of @contractSize.
+func (msg deribitTradesMessage) trades(contract futuresContract, contractSize float64) []*dia.FuturesTrade {
+	if msg.Method != "subscription" || !strings.HasPrefix(msg.Params.Channel, "trades.") {
+		return nil
+	}
+	if contractSize <= 0 {
+		contractSize = 1
+	}
+	var trades []*dia.FuturesTrade
+	for _, t := range msg.Params.Data {
+		trades = append(trades, contract.trade(dia.Deribit, t.InstrumentName, t.Price, t.Amount/contractSize, t.Direction, time.Unix(0, t.Timestamp*1e6), t.TradeID))
+	}
+	return trades
+}
+
+// deribitContract returns the contract of the futures @market and its contract size.
+func deribitContract(market string) (futuresContract, float64, error) {
+	currency := strings.Split(market, "-")[0]
+	body, err := utils.GetRequest("https://www.deribit.com/api/v2/public/get_instruments?kind=future&currency=" + currency)
+	if err != nil {
+		return futuresContract{}, 0, err
+	}
+	var instruments deribitInstruments
+	err = json.Unmarshal(body, &instruments)
+	if err != nil {
+		return futuresContract{}, 0, err
+	}
+	for _, instrument := range instruments.Result {
+		if instrument.InstrumentName != market {
+			continue
+		}
+		c := futuresContract{Underlying: instrument.BaseCurrency, Perpetual: instrument.SettlementPeriod == "perpetual"}
+		if !c.Perpetual {
+			c.Expiry = time.Unix(0, instrument.ExpirationTimestamp*1e6).UTC()
+		}
+		return c, instrument.ContractSize, nil
+	}
+	return futuresContract{}, 0, fmt.Errorf("unknown market %s", market)
+}
+
 // ScrapeMarkets - will scrape the markets specified during instantiation
 func (s *DeribitScraper) ScrapeMarkets() {
 	for _, market := range s.Markets {
@@ -141,6 +280,11 @@ func (s *DeribitScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// Channel returns the channel on which the trades of all futures markets are sent
+func (s *DeribitScraper) Channel() chan *dia.FuturesTrade {
+	return s.chanTrades
+}
+
 // marketKind can be "future" or "option"
 func (s *DeribitScraper) validateMarket(market string, marketKind DeribitScraperKind) error {
 	allFuturesMarketsDeribit, err := allDeribitMarketsOfKind(marketKind)
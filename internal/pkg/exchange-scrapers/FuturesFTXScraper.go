@@ -6,11 +6,13 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
 
 	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
@@ -26,28 +28,49 @@ const scrapeDataSaveLocationFTX = ""
 
 // FTXFuturesScraper - scrapes the futures from the FTX exchange
 type FTXFuturesScraper struct {
-	Markets   []string
-	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer
-	Logger    *zap.SugaredLogger
+	Markets    []string
+	WaitGroup  *sync.WaitGroup
+	Writer     writers.Writer
+	Logger     *zap.SugaredLogger
+	chanTrades chan *dia.FuturesTrade
 }
 
 type tradeMessageFTX struct {
-	Type string `json:"type"`
+	Type   string `json:"type"`
+	Market string `json:"market"`
+	Data   []struct {
+		ID    int64     `json:"id"`
+		Price float64   `json:"price"`
+		Size  float64   `json:"size"`
+		Side  string    `json:"side"`
+		Time  time.Time `json:"time"`
+	} `json:"data"`
 }
 
-// NewFTXFuturesScraper - returns an instance of the FTX scraper
-func NewFTXFuturesScraper(markets []string) FuturesScraper {
+// futureFTX is the result of https://ftx.com/api/futures/<market>
+type futureFTX struct {
+	Result struct {
+		Underlying string     `json:"underlying"`
+		Expiry     *time.Time `json:"expiry"`
+		Perpetual  bool       `json:"perpetual"`
+	} `json:"result"`
+}
+
+// NewFTXFuturesScraper - returns an instance of the FTX scraper. Messages are written by @writer, to text files if nil.
+func NewFTXFuturesScraper(markets []string, writer writers.Writer) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
+	if writer == nil {
+		writer = &writers.FileWriter{}
+	}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &FTXFuturesScraper{
-		WaitGroup: &wg,
-		Markets:   markets, // []string{"BNB-PERP", "ETH-PERP", "BTC-PERP", "EOS-PERP"}
-		Writer:    &writer,
-		Logger:    logger,
+		WaitGroup:  &wg,
+		Markets:    markets, // []string{"BNB-PERP", "ETH-PERP", "BTC-PERP", "EOS-PERP"}
+		Writer:     writer,
+		Logger:     logger,
+		chanTrades: make(chan *dia.FuturesTrade),
 	}
 
 	return scraper
@@ -102,9 +125,19 @@ func (s *FTXFuturesScraper) Scrape(market string) {
 		userCancelled <- true
 	}()
 
+	var contract *futuresContract
 	for {
 		// immediately invoked function expression for easy clenup with defer
 		func() {
+			if contract == nil {
+				c, err := ftxContract(market)
+				if err != nil {
+					s.Logger.Errorf("could not get the contract of %s: %s", market, err)
+					time.Sleep(time.Duration(retryIn) * time.Second)
+					return
+				}
+				contract = &c
+			}
 			u := url.URL{Scheme: "wss", Host: "ftx.com", Path: "/ws"}
 			s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
 			ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
@@ -163,6 +196,9 @@ func (s *FTXFuturesScraper) Scrape(market string) {
 							s.Logger.Errorf("could not write to file, err: %s", err)
 							return
 						}
+						for _, trade := range decodedMsg.trades(*contract) {
+							s.chanTrades <- trade
+						}
 					}
 				}
 			}
@@ -179,6 +215,41 @@ func (s *FTXFuturesScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// Channel returns the channel on which the trades of all markets are sent
+func (s *FTXFuturesScraper) Channel() chan *dia.FuturesTrade {
+	return s.chanTrades
+}
+
+// ftxContract returns the contract of @market
+func ftxContract(market string) (futuresContract, error) {
+	body, err := utils.GetRequest("https://ftx.com/api/futures/" + market)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	var future futureFTX
+	err = json.Unmarshal(body, &future)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	c := futuresContract{Underlying: future.Result.Underlying, Perpetual: future.Result.Perpetual}
+	if future.Result.Expiry != nil && !c.Perpetual {
+		c.Expiry = *future.Result.Expiry
+	}
+	return c, nil
+}
+
+// trades returns the trades of an update of the trades channel
+func (msg tradeMessageFTX) trades(contract futuresContract) []*dia.FuturesTrade {
+	if msg.Type != "update" {
+		return nil
+	}
+	var trades []*dia.FuturesTrade
+	for _, t := range msg.Data {
+		trades = append(trades, contract.trade(dia.FTX, msg.Market, t.Price, t.Size, t.Side, t.Time, strconv.FormatInt(t.ID, 10)))
+	}
+	return trades
+}
+
 func (s *FTXFuturesScraper) validateMarket(market string) {
 	containsMarket := utils.Contains(&allFuturesMarketsFTX, market)
 	if !containsMarket {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
@@ -14,6 +15,7 @@ import (
 	"time"
 
 	writers "github.com/diadata-org/diadata/internal/pkg/scraper-writers"
+	"github.com/diadata-org/diadata/pkg/dia"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	zap "go.uber.org/zap"
 	"golang.org/x/net/websocket"
@@ -35,32 +37,65 @@ var (
 	allowedMarketsHuobi     = []string{"BTC", "ETC", "ETH", "EOS", "LTC", "BCH", "XRP", "TRX", "BSV"}
 	allowedFrequenciesHuobi = []string{"CW", "NW", "CQ"}
 	bufferHuobi             bytes.Buffer
+	// contract types of the frequencies in the contract info API
+	contractTypesHuobi = map[string]string{"CW": "this_week", "NW": "next_week", "CQ": "quarter"}
 )
 
+// contracts are delivered at 16:00 in Singapore
+const deliveryHourUTCHuobi = 8
+
 // ---------------
 
 // HuobiFuturesScraper - scrapes huobi's futures markets
 type HuobiFuturesScraper struct {
-	Markets   []string // markets to scrape. To scrape all, call AllFuturesMarketsHuobi()
-	WaitGroup *sync.WaitGroup
-	Writer    writers.Writer // an interface to write the messages
-	Logger    *zap.SugaredLogger
+	Markets    []string // markets to scrape. To scrape all, call AllFuturesMarketsHuobi()
+	WaitGroup  *sync.WaitGroup
+	Writer     writers.Writer // an interface to write the messages
+	Logger     *zap.SugaredLogger
+	chanTrades chan *dia.FuturesTrade
+}
+
+// tradeMessageHuobi is a message of the trade detail channel
+type tradeMessageHuobi struct {
+	Ch   string `json:"ch"`
+	Tick struct {
+		Data []struct {
+			ID        json.Number `json:"id"`
+			Price     float64     `json:"price"`
+			Amount    float64     `json:"amount"`
+			Direction string      `json:"direction"`
+			Ts        int64       `json:"ts"`
+		} `json:"data"`
+	} `json:"tick"`
+}
+
+// contractInfoHuobi is the response of the contract info API
+type contractInfoHuobi struct {
+	Status string `json:"status"`
+	Data   []struct {
+		Symbol       string `json:"symbol"`
+		ContractType string `json:"contract_type"`
+		DeliveryDate string `json:"delivery_date"`
+	} `json:"data"`
 }
 
 // --------------------------------------------------------------------------------------------
 
-// NewHuobiFuturesScraper - returns an instance of the Huobi scraper
-func NewHuobiFuturesScraper(markets []string) FuturesScraper {
+// NewHuobiFuturesScraper - returns an instance of the Huobi scraper. Messages are written by @writer, to text files if nil.
+func NewHuobiFuturesScraper(markets []string, writer writers.Writer) FuturesScraper {
 	wg := sync.WaitGroup{}
-	writer := writers.FileWriter{}
+	if writer == nil {
+		writer = &writers.FileWriter{}
+	}
 	logger := zap.NewExample().Sugar() // or NewProduction, or NewDevelopment
 	defer logger.Sync()
 
 	var scraper FuturesScraper = &HuobiFuturesScraper{
-		WaitGroup: &wg,
-		Markets:   markets, // []string{"BNB-PERP", "ETH-PERP", "BTC-PERP", "EOS-PERP"}
-		Writer:    &writer,
-		Logger:    logger,
+		WaitGroup:  &wg,
+		Markets:    markets, // []string{"BTC_CW", "ETH_CQ"}
+		Writer:     writer,
+		Logger:     logger,
+		chanTrades: make(chan *dia.FuturesTrade),
 	}
 
 	return scraper
@@ -117,9 +152,20 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 		userCancelled <- true
 	}()
 
+	var contract *futuresContract
 	for {
 		// IIFE for easy cleanup with defer
 		func() {
+			// the contract of a market changes on delivery
+			if contract == nil || time.Now().After(contract.Expiry) {
+				c, err := huobiContract(market)
+				if err != nil {
+					s.Logger.Errorf("could not get the contract of %s: %s", market, err)
+					time.Sleep(time.Duration(retryIn) * time.Second)
+					return
+				}
+				contract = &c
+			}
 			ws, err := websocket.Dial(wsURLHuobi, "", "http://www.google.com")
 			// defer inside of the function will cleanup before the next run
 			defer s.ScraperClose(market, ws)
@@ -136,8 +182,6 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 				s.Logger.Errorf("problem subscriping to the [%s] trade channel, err: %s", market, err)
 				return
 			}
-			// create the conduit for the received messages
-			var msg = make([]byte, 512)
 			for {
 				select {
 				case <-userCancelled:
@@ -145,14 +189,20 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 					s.ScraperClose(market, ws)
 					os.Exit(0)
 				default:
-					m, err := ws.Read(msg)
+					if time.Now().After(contract.Expiry) {
+						s.Logger.Infof("[%s] contract delivered, reconnecting for the next one", market)
+						return
+					}
+					// receive whole frames, trade messages may be larger than a read buffer
+					var newmsg []byte
+					err := websocket.Message.Receive(ws, &newmsg)
 					if err != nil {
 						s.Logger.Errorf("[%s] %s", market, err)
 						// an error reading means we may have lost the connection
 						// return out and just try again
 						return
 					}
-					newmsg := msg[:m]
+					m := len(newmsg)
 					unzipmsg, err := parseGzip(newmsg)
 					if err != nil {
 						s.Logger.Errorf("[%s] problem saving to %s, err: %s", market, s.Writer.GetWriteFileName("huobi", market), err)
@@ -174,6 +224,14 @@ func (s *HuobiFuturesScraper) Scrape(market string) {
 							s.Logger.Errorf("[%s] problem saving to %s, err: %s", market, s.Writer.GetWriteFileName("huobi", market), err)
 							return
 						}
+						trades, err := parseHuobiFuturesTrades(unzipmsg, market, *contract)
+						if err != nil {
+							s.Logger.Errorf("[%s] could not parse message, err: %s", market, err)
+							continue
+						}
+						for _, trade := range trades {
+							s.chanTrades <- trade
+						}
 					}
 				}
 			}
@@ -191,8 +249,59 @@ func (s *HuobiFuturesScraper) ScrapeMarkets() {
 	s.WaitGroup.Wait()
 }
 
+// Channel returns the channel on which the trades of all markets are sent
+func (s *HuobiFuturesScraper) Channel() chan *dia.FuturesTrade {
+	return s.chanTrades
+}
+
 // ------------- Huobi util functions -------------------
 
+// huobiContract returns the contract currently traded as @market, e.g. BTC_CQ.
+func huobiContract(market string) (futuresContract, error) {
+	parts := strings.Split(market, "_")
+	if len(parts) != 2 {
+		return futuresContract{}, fmt.Errorf("invalid market %s", market)
+	}
+	contractType := contractTypesHuobi[parts[1]]
+	body, err := utils.GetRequest(marketURLHuobi + "/api/v1/contract_contract_info?symbol=" + parts[0] + "&contract_type=" + contractType)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	var info contractInfoHuobi
+	err = json.Unmarshal(body, &info)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	if info.Status != "ok" || len(info.Data) == 0 {
+		return futuresContract{}, fmt.Errorf("no contract %s", market)
+	}
+	expiry, err := time.Parse("20060102", info.Data[0].DeliveryDate)
+	if err != nil {
+		return futuresContract{}, err
+	}
+	return futuresContract{
+		Underlying: info.Data[0].Symbol,
+		Expiry:     expiry.Add(deliveryHourUTCHuobi * time.Hour),
+	}, nil
+}
+
+// parseHuobiFuturesTrades returns the trades of a message of the trade detail channel of @market.
+func parseHuobiFuturesTrades(message []byte, market string, contract futuresContract) ([]*dia.FuturesTrade, error) {
+	var msg tradeMessageHuobi
+	err := json.Unmarshal(message, &msg)
+	if err != nil {
+		return nil, err
+	}
+	if msg.Ch != "market."+market+".trade.detail" {
+		return nil, nil
+	}
+	var trades []*dia.FuturesTrade
+	for _, t := range msg.Tick.Data {
+		trades = append(trades, contract.trade(dia.HuobiExchange, market, t.Price, t.Amount, t.Direction, time.Unix(0, t.Ts*1e6), t.ID.String()))
+	}
+	return trades, nil
+}
+
 // AllFuturesMarketsHuobi - returns all the futures markets tradable on Huobi.
 // Lists all of the Huobi Futures markets. TODO: add a REST HTTP call to obtain the list
 // of trdabale markets.
@@ -14,6 +14,12 @@ import (
 	ws "github.com/gorilla/websocket"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.GateIOExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewGateIOScraper(exchange)
+	})
+}
+
 var _GateIOsocketurl string = "wss://api.gateio.ws/ws/v4/"
 
 type ResponseGate struct {
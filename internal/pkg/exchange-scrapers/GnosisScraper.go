@@ -19,9 +19,13 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.GnosisExchange, Centralized: false, Contract: common.HexToAddress("0x6F400810b62df8E13fded51bE75fF5393eaa841F"), BlockChain: blockchains[dia.Ethereum], WatchdogDelay: watchdogDelayLong}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewGnosisScraper(exchange)
+	})
+}
+
 const (
-	gnosisWsDial         = "ws://159.69.120.42:8546/"
-	gnosisRestDial       = "http://159.69.120.42:8545/"
 	gnosisLookBackBlocks = 6 * 60 * 24 * 7
 )
 
@@ -55,6 +59,7 @@ type GnosisScraper struct {
 }
 
 func NewGnosisScraper(exchange dia.Exchange) *GnosisScraper {
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 	scraper := &GnosisScraper{
 		exchangeName:   exchange.Name,
 		contract:       exchange.Contract,
@@ -68,15 +73,7 @@ func NewGnosisScraper(exchange dia.Exchange) *GnosisScraper {
 		tokens:         make(map[uint16]*GnosisToken),
 	}
 
-	wsClient, err := ethclient.Dial(gnosisWsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.WsClient = wsClient
-	restClient, err := ethclient.Dial(gnosisRestDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.RestClient = restClient
 
 	scraper.loadTokens()
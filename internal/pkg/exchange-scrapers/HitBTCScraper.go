@@ -15,6 +15,12 @@ import (
 	ws "github.com/gorilla/websocket"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.HitBTCExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewHitBTCScraper(exchange)
+	})
+}
+
 var _socketurl string = "wss://api.hitbtc.com/api/2/ws"
 
 const WS_TIMEOUT = 10 * time.Second
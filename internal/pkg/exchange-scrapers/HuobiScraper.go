@@ -16,6 +16,12 @@ import (
 	ws "github.com/gorilla/websocket"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.HuobiExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewHuobiScraper(exchange)
+	})
+}
+
 var _HuobiSocketurl string = "wss://api.huobi.pro/ws"
 
 type EventType struct {
@@ -131,15 +137,20 @@ func (s *HuobiScraper) mainLoop() {
 								f64Volume = -f64Volume
 							}
 
-							// element id is more than int64/uint64 in size
-							// leave the id in float64 format
+							// The element id exceeds the precision of float64, so that
+							// different trades can share the same parsed id. Only the
+							// tradeId is unique, trades without it are not deduplicated.
+							var foreignTradeID string
+							if tradeID, ok := md_element["tradeId"].(float64); ok {
+								foreignTradeID = strconv.FormatInt(int64(tradeID), 16)
+							}
 							t := &dia.Trade{
 								Symbol:         ps.pair.Symbol,
 								Pair:           forName,
 								Price:          f64Price,
 								Volume:         f64Volume,
 								Time:           timeStamp,
-								ForeignTradeID: strconv.FormatFloat(md_element["id"].(float64), 'E', -1, 64),
+								ForeignTradeID: foreignTradeID,
 								Source:         s.exchangeName,
 							}
 							ps.parent.chanTrades <- t
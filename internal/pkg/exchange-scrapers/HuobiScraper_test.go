@@ -0,0 +1,28 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestHuobiScraperReplay(t *testing.T) {
+	rs := newReplayServer(t, "huobi")
+	socketURL := _HuobiSocketurl
+	_HuobiSocketurl = rs.wsURL("/ws")
+	defer func() { _HuobiSocketurl = socketURL }()
+	start := time.Now()
+
+	s := NewHuobiScraper(Exchanges[dia.HuobiExchange])
+	defer s.Close()
+	if _, err := s.ScrapePair(dia.Pair{Symbol: "BTC", ForeignName: "BTCUSDT"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Huobi trades are stamped on arrival.
+	checkTrades(t, start, collectTrades(t, s.Channel(), 2), []dia.Trade{
+		{Symbol: "BTC", Pair: "BTCUSDT", Price: 47010.5, Volume: 0.0125, ForeignTradeID: "17c5d478d1", Source: dia.HuobiExchange},
+		{Symbol: "BTC", Pair: "BTCUSDT", Price: 47010.2, Volume: -0.5, ForeignTradeID: "17c5d478d2", Source: dia.HuobiExchange},
+	})
+}
@@ -1,19 +1,30 @@
 package scrapers
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	krakenapi "github.com/beldur/kraken-go-api-client"
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/utils"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.KrakenExchange, Centralized: true, WatchdogDelay: watchdogDelay, RequiresAPIKey: true}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewKrakenScraper(key, secret, exchange)
+	})
+}
+
 const (
-	krakenRefreshDelay = time.Second * 30 * 1
+	krakenRefreshDelay   = time.Second * 30 * 1
+	krakenOrderBookDelay = time.Second * 10
+	krakenDepthURL       = "https://api.kraken.com/0/public/Depth"
 )
 
 type KrakenScraper struct {
@@ -30,6 +41,10 @@ type KrakenScraper struct {
 	ticker       *time.Ticker
 	exchangeName string
 	chanTrades   chan *dia.Trade
+	// order books are polled for the pairs in orderBookPairs
+	orderBookPairs  sync.Map // pair.ForeignName -> dia.Pair
+	orderBookTicker *time.Ticker
+	chanOrderBooks  chan *dia.OrderBookSnapshot
 }
 
 // NewKrakenScraper returns a new KrakenScraper initialized with default values.
@@ -44,6 +59,9 @@ func NewKrakenScraper(key string, secret string, exchange dia.Exchange) *KrakenS
 		exchangeName: exchange.Name,
 		error:        nil,
 		chanTrades:   make(chan *dia.Trade),
+
+		orderBookTicker: time.NewTicker(krakenOrderBookDelay),
+		chanOrderBooks:  make(chan *dia.OrderBookSnapshot),
 	}
 	go s.mainLoop()
 	return s
@@ -71,6 +89,8 @@ func (s *KrakenScraper) mainLoop() {
 		select {
 		case <-s.ticker.C:
 			s.Update()
+		case <-s.orderBookTicker.C:
+			s.updateOrderBooks()
 		case <-s.shutdown: // user requested shutdown
 			log.Printf("KrakenScraper shutting down")
 			s.cleanup(nil)
@@ -138,6 +158,76 @@ func (s *KrakenScraper) ScrapePair(pair dia.Pair) (PairScraper, error) {
 	return ps, nil
 }
 
+// ScrapeOrderBook adds @pair to the pairs whose order books are polled
+// every krakenOrderBookDelay.
+func (s *KrakenScraper) ScrapeOrderBook(pair dia.Pair) error {
+	s.errorLock.RLock()
+	defer s.errorLock.RUnlock()
+	if s.error != nil {
+		return s.error
+	}
+	if s.closed {
+		return errors.New("KrakenScraper: Call ScrapeOrderBook on closed scraper")
+	}
+	s.orderBookPairs.Store(pair.ForeignName, pair)
+	return nil
+}
+
+// OrderBookChannel returns a channel that can be used to receive order book snapshots
+func (s *KrakenScraper) OrderBookChannel() chan *dia.OrderBookSnapshot {
+	return s.chanOrderBooks
+}
+
+func (s *KrakenScraper) updateOrderBooks() {
+	s.orderBookPairs.Range(func(k, v interface{}) bool {
+		pair := v.(dia.Pair)
+		ob, err := s.fetchOrderBook(pair)
+		if err != nil {
+			log.Errorf("fetch order book of %s: %v", pair.ForeignName, err)
+			return true
+		}
+		s.chanOrderBooks <- ob
+		return true
+	})
+}
+
+// fetchOrderBook queries the Depth endpoint directly, as krakenapi only returns
+// books listed under the requested name, whereas Kraken answers with its
+// internal name (e.g. XXBTZUSD for XBTUSD).
+func (s *KrakenScraper) fetchOrderBook(pair dia.Pair) (*dia.OrderBookSnapshot, error) {
+	data, err := utils.GetRequest(fmt.Sprintf("%s?pair=%s&count=%d", krakenDepthURL, pair.ForeignName, orderBookLevels))
+	if err != nil {
+		return nil, err
+	}
+	var response struct {
+		Error  []string                `json:"error"`
+		Result krakenapi.DepthResponse `json:"result"`
+	}
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Error) > 0 {
+		return nil, errors.New(strings.Join(response.Error, ", "))
+	}
+	for _, book := range response.Result {
+		ob := &dia.OrderBookSnapshot{
+			Pair:   pair.ForeignName,
+			Symbol: pair.Symbol,
+			Time:   time.Now(),
+			Source: s.exchangeName,
+		}
+		for _, bid := range book.Bids {
+			ob.Bids = append(ob.Bids, dia.OrderBookLevel{Price: bid.Price, Volume: bid.Amount})
+		}
+		for _, ask := range book.Asks {
+			ob.Asks = append(ob.Asks, dia.OrderBookLevel{Price: ask.Price, Volume: ask.Amount})
+		}
+		return ob, nil
+	}
+	return nil, errors.New("empty depth response")
+}
+
 // FetchAvailablePairs returns a list with all available trade pairs
 func (s *KrakenScraper) FetchAvailablePairs() (pairs []dia.Pair, err error) {
 	return []dia.Pair{}, errors.New("FetchAvailablePairs() not implemented")
@@ -223,9 +313,10 @@ func (s *KrakenScraper) Update() {
 		} else {
 			if r != nil {
 				ps.lastRecord = r.Last
-				for _, ti := range r.Trades {
+				for i, ti := range r.Trades {
 					p, _ := s.NormalizePair(ps.pair)
-					t := NewTrade(p, ti, strconv.FormatInt(r.Last, 16))
+					// Kraken has no trade ids, the trades of a response are told apart by their index
+					t := NewTrade(p, ti, strconv.FormatInt(r.Last, 16)+"-"+strconv.Itoa(i))
 					ps.parent.chanTrades <- t
 					log.Info("got trade: ", t)
 				}
@@ -0,0 +1,26 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestKrakenScraperReplay(t *testing.T) {
+	rs := newReplayServer(t, "kraken")
+	rs.redirect()
+	start := time.Now()
+
+	s := NewKrakenScraper("", "", Exchanges[dia.KrakenExchange])
+	defer s.Close()
+	if _, err := s.ScrapePair(dia.Pair{Symbol: "BTC", ForeignName: "XXBTZUSD"}); err != nil {
+		t.Fatal(err)
+	}
+	go s.Update()
+
+	checkTrades(t, start, collectTrades(t, s.Channel(), 2), []dia.Trade{
+		{Symbol: "BTC", Pair: "BTCUSD", Price: 47005.1, Volume: 0.0085, Time: time.Unix(1630050000, 0), ForeignTradeID: "169f19d726987e80-0", Source: dia.KrakenExchange},
+		{Symbol: "BTC", Pair: "BTCUSD", Price: 47004.9, Volume: -0.12, Time: time.Unix(1630050001, 0), ForeignTradeID: "169f19d726987e80-1", Source: dia.KrakenExchange},
+	})
+}
@@ -11,6 +11,12 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.KuCoinExchange, Centralized: true, WatchdogDelay: watchdogDelay, RequiresAPIKey: true}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewKuCoinScraper(key, secret, exchange)
+	})
+}
+
 type KuExchangePairs []KuExchangePair
 
 type KucoinMarketMatch struct {
@@ -19,10 +19,14 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.KyberExchange, Centralized: true, BlockChain: blockchains[dia.Ethereum], WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewKyberScraper(exchange)
+	})
+}
+
 const (
 	kyberContract       = "0x9AAb3f75489902f3a48495025729a0AF77d4b11e"
-	kyberWsDial         = "ws://159.69.120.42:8546/"
-	kyberRestDial       = "http://159.69.120.42:8545/"
 	kyberLookBackBlocks = 6 * 60 * 24
 )
 
@@ -55,6 +59,7 @@ type KyberScraper struct {
 }
 
 func NewKyberScraper(exchange dia.Exchange) *KyberScraper {
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 	scraper := &KyberScraper{
 		exchangeName:   exchange.Name,
 		initDone:       make(chan nothing),
@@ -66,15 +71,7 @@ func NewKyberScraper(exchange dia.Exchange) *KyberScraper {
 		resubscribe:    make(chan nothing),
 		tokens:         make(map[string]*KyberToken),
 	}
-	wsClient, err := ethclient.Dial(kyberWsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.WsClient = wsClient
-	restClient, err := ethclient.Dial(kyberRestDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.RestClient = restClient
 
 	scraper.loadTokens()
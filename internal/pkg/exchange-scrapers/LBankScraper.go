@@ -2,8 +2,6 @@ package scrapers
 
 import (
 	"errors"
-	"hash/fnv"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -14,6 +12,12 @@ import (
 	ws "github.com/gorilla/websocket"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.LBankExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewLBankScraper(exchange)
+	})
+}
+
 var _LBankSocketurl string = "wss://api.lbkex.com/ws/V2/"
 
 type ResponseLBank struct {
@@ -103,6 +107,7 @@ func (s *LBankScraper) mainLoop() {
 				}
 			}
 
+			// LBank sends no trade id
 			timeStamp := time.Now().UTC()
 			t := &dia.Trade{
 				Symbol:         ps.pair.Symbol,
@@ -110,7 +115,7 @@ func (s *LBankScraper) mainLoop() {
 				Price:          f64Price,
 				Volume:         f64Volume,
 				Time:           timeStamp,
-				ForeignTradeID: strconv.FormatInt(int64(hash(timeStamp.String())), 16),
+				ForeignTradeID: "",
 				Source:         s.exchangeName,
 			}
 			ps.parent.chanTrades <- t
@@ -119,12 +124,6 @@ func (s *LBankScraper) mainLoop() {
 	s.cleanup(err)
 }
 
-func hash(s string) uint32 {
-	h := fnv.New32a()
-	h.Write([]byte(s))
-	return h.Sum32()
-}
-
 func (s *LBankScraper) cleanup(err error) {
 	s.errorLock.Lock()
 	defer s.errorLock.Unlock()
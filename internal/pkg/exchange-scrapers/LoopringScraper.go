@@ -14,6 +14,12 @@ import (
 	ws "github.com/gorilla/websocket"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.LoopringExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], WatchdogDelay: watchdogDelayLong}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewLoopringScraper(exchange)
+	})
+}
+
 var _LoopringSocketurl string = "wss://ws.api3.loopring.io/v3/ws"
 
 type WebSocketRequest struct {
@@ -14,6 +14,12 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.MakerExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewMakerScraper(exchange)
+	})
+}
+
 const (
 	MakerBatchDelay = 60 * 1
 )
@@ -17,6 +17,12 @@ import (
 	ws "github.com/gorilla/websocket"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.OKExExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewOKExScraper(exchange)
+	})
+}
+
 var _OKExSocketURL = "wss://ws.okex.com:8443/ws/v5/public"
 
 //var _OKExSocketURL = url.URL{Scheme: "wss", Host: "real.okex.com:10441", Path: "/ws/v1", RawQuery: "compress=true"}
@@ -298,15 +304,13 @@ func (s *OKExScraper) ScrapePair(pair dia.Pair) (PairScraper, error) {
 
 	s.pairScrapers[pair.ForeignName] = ps
 
-	//a := &Subscribe{
-	//	OP:      "addChannel",
-	//	Args: "ok_sub_spot_" + strings.ToLower(pair.ForeignName) + "_deals",
-	//}
-	//
-	//subByteString := `{"channel":` + `"` + a.Channel + `"` + `,"event":` + `"` + a.Event + `"}`
-	//if err := s.wsClient.WriteMessage(ws.TextMessage, []byte(subByteString)); err != nil {
-	//	fmt.Println(err.Error())
-	//}
+	a := &Subscribe{
+		OP:   "subscribe",
+		Args: []OKEXArgs{{Channel: "trades", InstID: pair.ForeignName}},
+	}
+	if err := s.wsClient.WriteJSON(a); err != nil {
+		log.Errorln(err.Error())
+	}
 
 	return ps, nil
 }
@@ -0,0 +1,35 @@
+package scrapers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestOKExScraperReplay(t *testing.T) {
+	rs := newReplayServer(t, "okex")
+	// Instruments are fetched from a hardcoded endpoint on reconnects.
+	rs.redirect()
+	socketURL := _OKExSocketURL
+	_OKExSocketURL = rs.wsURL("/ws/v5/public")
+	defer func() { _OKExSocketURL = socketURL }()
+	start := time.Now()
+
+	s := NewOKExScraper(Exchanges[dia.OKExExchange])
+	defer s.Close()
+	if _, err := s.ScrapePair(dia.Pair{Symbol: "BTC", ForeignName: "BTC-USDT"}); err != nil {
+		t.Fatal(err)
+	}
+
+	checkTrades(t, start, collectTrades(t, s.Channel(), 2), []dia.Trade{
+		{Symbol: "BTC", Pair: "BTC-USDT", Price: 47020.1, Volume: 0.0302, Time: time.Unix(1630050003, 0), ForeignTradeID: "215327951", Source: dia.OKExExchange},
+		{Symbol: "BTC", Pair: "BTC-USDT", Price: 47019.8, Volume: -1.2, Time: time.Unix(1630050004, 0), ForeignTradeID: "215327952", Source: dia.OKExExchange},
+	})
+	// the pair is subscribed by ScrapePair, not only after a reconnect
+	want := `{"op":"subscribe","args":[{"channel":"trades","instId":"BTC-USDT"}]}`
+	if sent := rs.sentMessages(); len(sent) == 0 || strings.TrimSpace(sent[0]) != want {
+		t.Errorf("expected subscription %s, got %v", want, sent)
+	}
+}
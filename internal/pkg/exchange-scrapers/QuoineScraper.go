@@ -14,6 +14,12 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.QuoineExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewQuoineScraper(exchange)
+	})
+}
+
 var pingPeriod = 60*time.Second*2 - 1
 
 var LiquidSocketURL string = "wss://tap.liquid.com/app/LiquidTapClient"
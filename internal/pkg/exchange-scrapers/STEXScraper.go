@@ -17,6 +17,12 @@ import (
 	"github.com/graarh/golang-socketio/transport"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.STEXExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewSTEXScraper(exchange)
+	})
+}
+
 var _socketURL string = "socket.stex.com"
 
 const (
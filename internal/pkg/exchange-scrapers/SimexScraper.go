@@ -14,6 +14,12 @@ import (
 	utils "github.com/diadata-org/diadata/pkg/utils"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.SimexExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewSimexScraper(exchange)
+	})
+}
+
 type PairIdMap struct {
 	Id          float64
 	LastIdTrade int
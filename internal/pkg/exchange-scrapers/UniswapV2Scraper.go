@@ -1,12 +1,16 @@
 package scrapers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"math"
 	"math/big"
 	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
@@ -18,33 +22,81 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.UniswapExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], Contract: common.HexToAddress("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f"), WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewUniswapScraper(exchange)
+	})
+	RegisterExchange(dia.Exchange{Name: dia.SushiSwapExchange, Centralized: false, BlockChain: blockchains[dia.Ethereum], Contract: common.HexToAddress("0xc0aee478e3658e2610c5f7a4a2e1777ce9e4f2ac"), WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewUniswapScraper(exchange)
+	})
+	RegisterExchange(dia.Exchange{Name: dia.PanCakeSwap, Centralized: false, BlockChain: blockchains[dia.BINANCESMARTCHAIN], Contract: common.HexToAddress("0xbcfccbde45ce874adcb698cc183debcf17952812"), WatchdogDelay: watchdogDelayLong}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewUniswapScraper(exchange)
+	})
+	RegisterExchange(dia.Exchange{Name: dia.DfynNetwork, Centralized: false, BlockChain: blockchains[dia.POLYGON], Contract: common.HexToAddress("0xe7fb3e833efe5f9c441105eb65ef8b261266423b"), WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewUniswapScraper(exchange)
+	})
+	registerUniswapV2Forks(configCollectors.ConfigFileConnectors("evm", ""))
+}
+
+// registerUniswapV2Forks registers the Uniswap V2 forks defined in the configuration files in @dir.
+// The name of a fork must match the name of its file.
+func registerUniswapV2Forks(dir string) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		log.Error("list exchange configurations: ", err)
+		return
+	}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			log.Error("read exchange configuration: ", err)
+			continue
+		}
+		config, err := parseEVMExchangeConfig(data)
+		if err != nil {
+			log.Errorf("parse exchange configuration %s: %v", file, err)
+			continue
+		}
+		if config.Fork != uniswapV2Fork {
+			continue
+		}
+		if name := strings.TrimSuffix(filepath.Base(file), ".json"); config.Name != name {
+			log.Errorf("fork %s is configured in %s", config.Name, file)
+			continue
+		}
+		if _, ok := Exchanges[config.Name]; ok {
+			continue
+		}
+		RegisterExchange(config.apply(dia.Exchange{Name: config.Name, Centralized: false, WatchdogDelay: watchdogDelay}), func(exchange dia.Exchange, key string, secret string) APIScraper {
+			return NewUniswapScraper(exchange)
+		})
+	}
+}
+
 var (
 	exchangeFactoryContractAddress = "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f"
 	reversePairs                   *[]string
 )
 
-const (
-	wsDial   = "ws://159.69.120.42:8546/"
-	restDial = "http://159.69.120.42:8545/"
-
-	wsDialBSC = "wss://bsc-ws-node.nariox.org:443"
-	// restDialBSC = "https://bsc-dataseed2.defibit.io/"
-	restDialBSC = "https://bsc-dataseed.binance.org/"
-	// restDialBSC = "https://bsc-dataseed1.defibit.io/"
-	// restDialBSC = "https://bsc-dataseed1.ninicoin.io/"
-
-	wsDialPolygon   = "wss://polygon-mainnet.g.alchemy.com/v2/v4QY39R1qGD-v2-4Qk2W7e6tYkO_5Jid"
-	restDialPolygon = "https://polygon-mainnet.g.alchemy.com/v2/v4QY39R1qGD-v2-4Qk2W7e6tYkO_5Jid"
-)
-
 type UniswapToken struct {
 	Address  common.Address
 	Symbol   string
 	Decimals uint8
 }
 
+// asset returns the token as asset on @blockchain.
+func (t UniswapToken) asset(blockchain string) dia.Asset {
+	return dia.Asset{
+		Symbol:     t.Symbol,
+		Address:    t.Address.Hex(),
+		Decimals:   t.Decimals,
+		Blockchain: blockchain,
+	}
+}
+
 type UniswapPair struct {
 	Token0      UniswapToken
 	Token1      UniswapToken
@@ -78,78 +130,53 @@ type UniswapScraper struct {
 	// used to keep track of trading pairs that we subscribed to
 	pairScrapers map[string]*UniswapPairScraper
 	exchangeName string
+	blockchain   string
 	chanTrades   chan *dia.Trade
+	// pairs with a pair scraper, available for backfills once pairsReady is closed
+	backfillPairs []UniswapPair
+	pairsReady    chan nothing
+	// closed once the blocks missed since the last run have been backfilled
+	resumed       chan nothing
+	checkpoint    *blockCheckpoint
+	subscriptions subscriptionTracker
+	// holds trades until their block is final
+	confirmations *confirmationBuffer
+	// configuration of the fork, see EVMExchangeConfig
+	feeTier       float64
+	reverseTokens *[]string
+	minLiquidity  float64
 }
 
 // NewUniswapScraper returns a new UniswapScraper for the given pair
 func NewUniswapScraper(exchange dia.Exchange) *UniswapScraper {
 	log.Info("NewUniswapScraper ", exchange.Name)
-	var wsClient, restClient *ethclient.Client
-	var err error
-
-	switch exchange.Name {
-	case dia.UniswapExchange:
-		exchangeFactoryContractAddress = exchange.Contract.String()
-		wsClient, err = ethclient.Dial(wsDial)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		restClient, err = ethclient.Dial(restDial)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		break
-	case dia.SushiSwapExchange:
-		exchangeFactoryContractAddress = exchange.Contract.String()
-		wsClient, err = ethclient.Dial(wsDial)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		restClient, err = ethclient.Dial(restDial)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-	case dia.PanCakeSwap:
-		log.Infoln("Init ws and rest client for BSC chain")
-		wsClient, err = ethclient.Dial(wsDialBSC)
-		if err != nil {
-			log.Fatal(err)
-		}
-		restClient, err = ethclient.Dial(restDialBSC)
-		if err != nil {
-			log.Fatal(err)
-		}
-		exchangeFactoryContractAddress = exchange.Contract.String()
-
-	case dia.DfynNetwork:
-		log.Infoln("Init ws and rest client for Polygon chain")
-		wsClient, err = ethclient.Dial(wsDialPolygon)
-		if err != nil {
-			log.Fatal(err)
-		}
-		restClient, err = ethclient.Dial(restDialPolygon)
-		if err != nil {
-			log.Fatal(err)
-		}
-		exchangeFactoryContractAddress = exchange.Contract.String()
-	}
+	config := mustLoadEVMExchangeConfig(exchange.Name)
+	exchange, wsClient, restClient := config.connect(exchange)
+	exchangeFactoryContractAddress = exchange.Contract.String()
 
+	relDB := connectRelDB()
 	s := &UniswapScraper{
 		shutdown:     make(chan nothing),
 		shutdownDone: make(chan nothing),
 		pairScrapers: make(map[string]*UniswapPairScraper),
 		exchangeName: exchange.Name,
+		blockchain:   exchange.BlockChain.Name,
 		error:        nil,
 		chanTrades:   make(chan *dia.Trade),
+		pairsReady:   make(chan nothing),
+		resumed:      make(chan nothing),
+		checkpoint:   newBlockCheckpoint(exchange.Name, relDB),
+		feeTier:      config.FeeTier,
+		minLiquidity: config.MinLiquidity,
 	}
 
+	s.reverseTokens = config.reverseTokens()
 	s.WsClient = wsClient
 	s.RestClient = restClient
+	s.confirmations = newConfirmationBuffer(exchange.BlockChain, restClient, relDB, func(t *dia.Trade) { s.chanTrades <- t })
+	s.confirmations.onFinal = s.setCheckpoint
 
+	go s.confirmations.run(s.shutdown)
 	go s.mainLoop()
 	return s
 }
@@ -159,9 +186,11 @@ func (s *UniswapScraper) mainLoop() {
 
 	// Import tokens which appear as base token and we need a quotation for
 	var err error
-	reversePairs, err = getReverseTokensFromConfig("uniswap/reverse_tokens")
-	if err != nil {
-		log.Error("error getting tokens for which pairs should be reversed: ", err)
+	if s.reverseTokens == nil {
+		s.reverseTokens, err = getReverseTokensFromConfig("uniswap/reverse_tokens")
+		if err != nil {
+			log.Error("error getting tokens for which pairs should be reversed: ", err)
+		}
 	}
 
 	// wait for all pairs have added into s.PairScrapers
@@ -223,77 +252,249 @@ func (s *UniswapScraper) mainLoop() {
 		}
 		pair.normalizeUniPair()
 		ps, ok := s.pairScrapers[pair.ForeignName]
-		if ok {
-			log.Info(i, ": found pair scraper for: ", pair.ForeignName, " with address ", pair.Address.Hex())
-			sink, err := s.GetSwapsChannel(pair.Address)
+		if ok && s.minLiquidity > 0 {
+			ok, err = s.hasLiquidity(pair)
 			if err != nil {
-				log.Error("error fetching swaps channel: ", err)
+				log.Error("error fetching reserves: ", err)
 			}
-
-			go func() {
-				for {
-					rawSwap, ok := <-sink
-					if ok {
-						swap, err := s.normalizeUniswapSwap(*rawSwap)
-						if err != nil {
-							log.Error("error normalizing swap: ", err)
-						}
-						price, volume, err := getSwapData(swap)
-						if err != nil {
-							log.Error("error getting swap data: ", err)
-						}
-
-						t := &dia.Trade{
-							Symbol:         ps.pair.Symbol,
-							Pair:           ps.pair.ForeignName,
-							Price:          price,
-							Volume:         volume,
-							Time:           time.Unix(swap.Timestamp, 0),
-							ForeignTradeID: swap.ID,
-							Source:         s.exchangeName,
-						}
-						// If we need quotation of a base token, reverse pair
-						if utils.Contains(reversePairs, pair.Token1.Address.Hex()) {
-							tSwapped, err := dia.SwapTrade(*t)
-							if err == nil {
-								t = &tSwapped
-							}
-						}
-						if price > 0 {
-							log.Info("Got trade: ", t)
-							ps.parent.chanTrades <- t
-						}
-						if price == 0 {
-							log.Info("Got zero trade: ", t)
-						}
-					}
-				}
-			}()
+			if !ok {
+				log.Infof("skip pair %s, liquidity below %v", pair.ForeignName, s.minLiquidity)
+				continue
+			}
+		}
+		if ok {
+			log.Info(i, ": found pair scraper for: ", pair.ForeignName, " with address ", pair.Address.Hex())
+			s.backfillPairs = append(s.backfillPairs, pair)
+			go s.watchSwaps(ps, pair)
 		} else {
 			log.Info("Skipping pair due to no pairScraper being available")
 		}
 	}
+	close(s.pairsReady)
 
+	s.resume()
 	// s.cleanup(err)
 }
 
-// GetSwapsChannel returns a channel for swaps of the pair with address @pairAddress
-func (s *UniswapScraper) GetSwapsChannel(pairAddress common.Address) (chan *uniswapcontract.UniswapV2PairSwap, error) {
+// emitSwap sends the trade corresponding to @rawSwap of @pair on the trades channel
+// once its block is final, stamped with the time of its block. A zero @timestamp
+// leaves looking up the block time to the confirmation buffer.
+func (s *UniswapScraper) emitSwap(ps *UniswapPairScraper, pair UniswapPair, rawSwap uniswapcontract.UniswapV2PairSwap, timestamp time.Time) {
+	if rawSwap.Raw.Removed {
+		s.confirmations.retract(rawSwap.Raw)
+		return
+	}
+	swap, err := s.normalizeUniswapSwap(rawSwap, pair)
+	if err != nil {
+		log.Error("error normalizing swap: ", err)
+	}
+	if !timestamp.IsZero() {
+		swap.Timestamp = timestamp.Unix()
+	}
+	price, volume, err := getSwapData(swap, s.feeTier)
+	if err != nil {
+		log.Error("error getting swap data: ", err)
+	}
+
+	t := &dia.Trade{
+		Symbol:         ps.pair.Symbol,
+		Pair:           ps.pair.ForeignName,
+		Price:          price,
+		Volume:         volume,
+		Time:           time.Unix(swap.Timestamp, 0),
+		ForeignTradeID: swap.ID,
+		Source:         s.exchangeName,
+		QuoteAsset:     pair.Token0.asset(s.blockchain),
+		BaseAsset:      pair.Token1.asset(s.blockchain),
+	}
+	// If we need quotation of a base token, reverse pair
+	if utils.Contains(s.reverseTokens, pair.Token1.Address.Hex()) {
+		tSwapped, err := dia.SwapTrade(*t)
+		if err == nil {
+			t = &tSwapped
+		}
+	}
+	if price > 0 {
+		log.Info("Got trade: ", t)
+		s.confirmations.add(t, rawSwap.Raw)
+	}
+	if price == 0 {
+		log.Info("Got zero trade: ", t)
+	}
+}
+
+// setCheckpoint stores @block as processed once the blocks missed since the last
+// run have been backfilled, unless a swaps subscription is down.
+func (s *UniswapScraper) setCheckpoint(block uint64) {
+	select {
+	case <-s.resumed:
+		if s.subscriptions.healthy() {
+			s.checkpoint.set(block)
+		}
+	default:
+	}
+}
+
+// resume backfills the blocks from the stored checkpoint up to the current block. A failed
+// backfill is retried from the checkpoint it reached, and new blocks only advance the
+// checkpoint once it succeeded.
+func (s *UniswapScraper) resume() {
+	for {
+		err := s.resumeFromCheckpoint()
+		if err == nil {
+			close(s.resumed)
+			return
+		}
+		log.Error("error resuming from checkpoint: ", err)
+		select {
+		case <-time.After(backfillRetryDelay):
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+func (s *UniswapScraper) resumeFromCheckpoint() error {
+	lastBlock, ok := s.checkpoint.last()
+	if !ok {
+		return nil
+	}
+	currentBlock, err := s.RestClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	<-s.pairsReady
+	return s.backfill(s.backfillPairs, lastBlock+1, currentBlock, true)
+}
+
+// Backfill emits the trades of all blocks in [@startBlock, @endBlock] on Channel(),
+// timestamped with the time of their block.
+func (s *UniswapScraper) Backfill(startBlock uint64, endBlock uint64) error {
+	<-s.pairsReady
+	return s.backfill(s.backfillPairs, startBlock, endBlock, false)
+}
+
+// backfill emits the swaps of @pairs in [@startBlock, @endBlock]. With @checkpoint, the
+// checkpoint follows the final blocks backfilled.
+func (s *UniswapScraper) backfill(pairs []UniswapPair, startBlock uint64, endBlock uint64, checkpoint bool) error {
+	log.Infof("backfill %s from block %d to %d", s.exchangeName, startBlock, endBlock)
+	blockTimes := newBlockTimes(s.RestClient)
+
+	for _, blocks := range blockRanges(startBlock, endBlock) {
+		for _, pair := range pairs {
+			filterer, err := uniswapcontract.NewUniswapV2PairFilterer(pair.Address, s.RestClient)
+			if err != nil {
+				return err
+			}
+			end := blocks[1]
+			swaps, err := filterer.FilterSwap(&bind.FilterOpts{Start: blocks[0], End: &end}, []common.Address{}, []common.Address{})
+			if err != nil {
+				return err
+			}
+			for swaps.Next() {
+				timestamp, err := blockTimes.get(swaps.Event.Raw.BlockNumber)
+				if err != nil {
+					swaps.Close()
+					return err
+				}
+				s.emitSwap(s.pairScrapers[pair.ForeignName], pair, *swaps.Event, timestamp)
+			}
+			err = swaps.Error()
+			swaps.Close()
+			if err != nil {
+				return err
+			}
+		}
+		if checkpoint {
+			// trades of blocks which are not final yet are still buffered
+			s.checkpoint.set(s.confirmations.finalUpTo(blocks[1]))
+		}
+		log.Infof("backfilled %s up to block %d", s.exchangeName, blocks[1])
+	}
+	if checkpoint {
+		s.checkpoint.flush()
+	}
+	return nil
+}
+
+// GetSwapsChannel returns a channel for swaps of the pair with address @pairAddress and its subscription
+func (s *UniswapScraper) GetSwapsChannel(pairAddress common.Address) (chan *uniswapcontract.UniswapV2PairSwap, event.Subscription, error) {
 
 	sink := make(chan *uniswapcontract.UniswapV2PairSwap)
 	var pairFiltererContract *uniswapcontract.UniswapV2PairFilterer
 	pairFiltererContract, err := uniswapcontract.NewUniswapV2PairFilterer(pairAddress, s.WsClient)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 
-	_, err = pairFiltererContract.WatchSwap(&bind.WatchOpts{}, sink, []common.Address{}, []common.Address{})
+	sub, err := pairFiltererContract.WatchSwap(&bind.WatchOpts{}, sink, []common.Address{}, []common.Address{})
 	if err != nil {
-		log.Error("error in get swaps channel: ", err)
+		return nil, nil, err
 	}
 
-	return sink, nil
+	return sink, sub, nil
+
+}
+
+// watchSwaps emits the swaps of @pair from its subscription until s is closed. While the
+// subscription is down, the checkpoint is held. Once it is renewed, the blocks since the
+// last swap received are backfilled.
+func (s *UniswapScraper) watchSwaps(ps *UniswapPairScraper, pair UniswapPair) {
+	down := false
+	var missedFrom uint64
+	for {
+		sink, sub, err := s.GetSwapsChannel(pair.Address)
+		if err == nil && down {
+			err = s.backfillMissed(pair, missedFrom)
+			if err != nil {
+				sub.Unsubscribe()
+			}
+		}
+		if err != nil {
+			log.Errorf("error subscribing to the swaps of %s: %v", pair.ForeignName, err)
+			if !down {
+				down, missedFrom = true, missedSince(&s.subscriptions, s.confirmations)
+			}
+			select {
+			case <-time.After(backfillRetryDelay):
+				continue
+			case <-s.shutdown:
+				return
+			}
+		}
+		if down {
+			down = false
+			s.subscriptions.restored()
+		}
 
+	receive:
+		for {
+			select {
+			case rawSwap := <-sink:
+				s.subscriptions.received(rawSwap.Raw.BlockNumber)
+				s.emitSwap(ps, pair, *rawSwap, time.Time{})
+			case err := <-sub.Err():
+				log.Errorf("swaps subscription of %s failed: %v", pair.ForeignName, err)
+				down, missedFrom = true, missedSince(&s.subscriptions, s.confirmations)
+				break receive
+			case <-s.shutdown:
+				sub.Unsubscribe()
+				return
+			}
+		}
+	}
+}
+
+// backfillMissed emits the swaps of @pair from block @startBlock up to the current block.
+func (s *UniswapScraper) backfillMissed(pair UniswapPair, startBlock uint64) error {
+	currentBlock, err := s.RestClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	if startBlock > currentBlock {
+		return nil
+	}
+	return s.backfill([]UniswapPair{pair}, startBlock, currentBlock, false)
 }
 
 // getReverseTokensFromConfig returns a list of addresses from config file.
@@ -335,14 +536,9 @@ func getReverseTokensFromConfig(filename string) (*[]string, error) {
 	return &reverseTokens, nil
 }
 
-// normalizeUniswapSwap takes a swap as returned by the swap contract's channel and converts it to a UniswapSwap type
-func (s *UniswapScraper) normalizeUniswapSwap(swap uniswapcontract.UniswapV2PairSwap) (normalizedSwap UniswapSwap, err error) {
+// normalizeUniswapSwap takes a swap of @pair as returned by the swap contract's channel or filter and converts it to a UniswapSwap type
+func (s *UniswapScraper) normalizeUniswapSwap(swap uniswapcontract.UniswapV2PairSwap, pair UniswapPair) (normalizedSwap UniswapSwap, err error) {
 
-	pair, err := s.GetPairByAddress(swap.Raw.Address)
-	if err != nil {
-		log.Error("error getting pair by address: ", err)
-		return
-	}
 	decimals0 := int(pair.Token0.Decimals)
 	decimals1 := int(pair.Token1.Decimals)
 	amount0In, _ := new(big.Float).Quo(big.NewFloat(0).SetInt(swap.Amount0In), new(big.Float).SetFloat64(math.Pow10(decimals0))).Float64()
@@ -351,7 +547,7 @@ func (s *UniswapScraper) normalizeUniswapSwap(swap uniswapcontract.UniswapV2Pair
 	amount1Out, _ := new(big.Float).Quo(big.NewFloat(0).SetInt(swap.Amount1Out), new(big.Float).SetFloat64(math.Pow10(decimals1))).Float64()
 
 	normalizedSwap = UniswapSwap{
-		ID:         swap.Raw.TxHash.Hex(),
+		ID:         swap.Raw.TxHash.Hex() + "-" + fmt.Sprint(swap.Raw.Index),
 		Timestamp:  time.Now().Unix(),
 		Pair:       pair,
 		Amount0In:  amount0In,
@@ -377,6 +573,8 @@ func (s *UniswapScraper) FetchAvailablePairs() (pairs []dia.Pair, err error) {
 			ForeignName: pair.ForeignName,
 			Exchange:    "UniswapV2",
 			Ignore:      false,
+			QuoteAsset:  pair.Token0.asset(s.blockchain),
+			BaseAsset:   pair.Token1.asset(s.blockchain),
 		}
 		normalizedPair, _ := s.NormalizePair(pairToNormalise)
 		pairs = append(pairs, normalizedPair)
@@ -553,19 +751,44 @@ func (s *UniswapScraper) getNumPairs() (int, error) {
 	return int(numPairs.Int64()), nil
 }
 
-// getSwapData returns price, volume and sell/buy information of @swap
-func getSwapData(swap UniswapSwap) (price float64, volume float64, err error) {
+// getSwapData returns price, volume and sell/buy information of @swap.
+// The price is corrected for the fee @feeTier charged on the input amount.
+func getSwapData(swap UniswapSwap, feeTier float64) (price float64, volume float64, err error) {
 	if swap.Amount0In == float64(0) {
 		volume = swap.Amount0Out
-		price = swap.Amount1In / swap.Amount0Out
+		price = swap.Amount1In * (1 - feeTier) / swap.Amount0Out
 		return
 	}
 
 	volume = -swap.Amount0In
-	price = swap.Amount1Out / swap.Amount0In
+	price = swap.Amount1Out / (swap.Amount0In * (1 - feeTier))
 	return
 }
 
+// hasLiquidity reports whether both reserves of @pair hold at least s.minLiquidity tokens.
+func (s *UniswapScraper) hasLiquidity(pair UniswapPair) (bool, error) {
+	pairContract, err := uniswapcontract.NewIUniswapV2PairCaller(pair.Address, s.RestClient)
+	if err != nil {
+		return false, err
+	}
+	reserves, err := pairContract.GetReserves(&bind.CallOpts{})
+	if err != nil {
+		return false, err
+	}
+	token0, err := pairContract.Token0(&bind.CallOpts{})
+	if err != nil {
+		return false, err
+	}
+	// pair may have been normalized, so match the reserves by token address
+	decimals0, decimals1 := pair.Token0.Decimals, pair.Token1.Decimals
+	if token0 != pair.Token0.Address {
+		decimals0, decimals1 = decimals1, decimals0
+	}
+	reserve0, _ := new(big.Float).Quo(big.NewFloat(0).SetInt(reserves.Reserve0), new(big.Float).SetFloat64(math.Pow10(int(decimals0)))).Float64()
+	reserve1, _ := new(big.Float).Quo(big.NewFloat(0).SetInt(reserves.Reserve1), new(big.Float).SetFloat64(math.Pow10(int(decimals1)))).Float64()
+	return reserve0 >= s.minLiquidity && reserve1 >= s.minLiquidity, nil
+}
+
 func (s *UniswapScraper) cleanup(err error) {
 	s.errorLock.Lock()
 	defer s.errorLock.Unlock()
@@ -1,7 +1,9 @@
 package scrapers
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"strings"
@@ -18,8 +20,15 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.UniswapExchangeV3, Centralized: false, BlockChain: blockchains[dia.Ethereum], Contract: common.HexToAddress("0x1F98431c8aD98523631AE4a59f267346ea31F984"), WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewUniswapV3Scraper(exchange)
+	})
+}
+
 var (
 	UniswapV3FactoryContractAddress = "0x1F98431c8aD98523631AE4a59f267346ea31F984"
 )
@@ -50,44 +59,46 @@ type UniswapV3Scraper struct {
 	pairRecieved chan *UniswapPair
 
 	exchangeName string
+	blockchain   string
 	chanTrades   chan *dia.Trade
+	// pools subscribed to, available for backfills once pairsReady is closed
+	backfillPairs []UniswapPair
+	pairsReady    chan nothing
+	// closed once the blocks missed since the last run have been backfilled
+	resumed       chan nothing
+	checkpoint    *blockCheckpoint
+	subscriptions subscriptionTracker
+	// holds trades until their block is final
+	confirmations *confirmationBuffer
 }
 
 // NewUniswapV3Scraper returns a new UniswapV3Scraper
 func NewUniswapV3Scraper(exchange dia.Exchange) *UniswapV3Scraper {
 	log.Info("NewUniswapScraper ", exchange.Name)
-	var wsClient, restClient *ethclient.Client
-	var err error
-
-	switch exchange.Name {
-	case dia.UniswapExchangeV3:
-		exchangeFactoryContractAddress = exchange.Contract.String()
-		wsClient, err = ethclient.Dial(wsDial)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		restClient, err = ethclient.Dial(restDial)
-		if err != nil {
-			log.Fatal(err)
-		}
-
-		break
-	}
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
+	UniswapV3FactoryContractAddress = exchange.Contract.Hex()
 
+	relDB := connectRelDB()
 	s := &UniswapV3Scraper{
 		shutdown:     make(chan nothing),
 		shutdownDone: make(chan nothing),
 		pairScrapers: make(map[string]*UniswapPairV3Scraper),
 		exchangeName: exchange.Name,
+		blockchain:   exchange.BlockChain.Name,
 		pairRecieved: make(chan *UniswapPair),
 		error:        nil,
 		chanTrades:   make(chan *dia.Trade),
+		pairsReady:   make(chan nothing),
+		resumed:      make(chan nothing),
+		checkpoint:   newBlockCheckpoint(exchange.Name, relDB),
 	}
 
 	s.WsClient = wsClient
 	s.RestClient = restClient
+	s.confirmations = newConfirmationBuffer(exchange.BlockChain, restClient, relDB, func(t *dia.Trade) { s.chanTrades <- t })
+	s.confirmations.onFinal = s.setCheckpoint
 
+	go s.confirmations.run(s.shutdown)
 	go s.mainLoop()
 	return s
 }
@@ -111,6 +122,8 @@ func (s *UniswapV3Scraper) mainLoop() {
 		}
 		log.Info("Found ", len(pairs), " pairs")
 		log.Info("Found ", len(s.pairScrapers), " pairScrapers")
+		// signal that all pools have been received
+		s.pairRecieved <- nil
 	}()
 
 	if len(s.pairScrapers) == 0 {
@@ -119,6 +132,11 @@ func (s *UniswapV3Scraper) mainLoop() {
 	}
 	for {
 		pair := <-s.pairRecieved
+		if pair == nil {
+			close(s.pairsReady)
+			go s.resume()
+			continue
+		}
 		log.Infoln("Subscribing for pair", pair)
 
 		if len(pair.Token0.Symbol) < 2 || len(pair.Token1.Symbol) < 2 {
@@ -140,67 +158,226 @@ func (s *UniswapV3Scraper) mainLoop() {
 		pair.normalizeUniPair()
 		if true {
 			log.Info(": found pair scraper for: ", pair.ForeignName, " with address ", pair.Address.Hex())
-			sink, err := s.GetSwapsChannel(pair.Address)
-			if err != nil {
-				log.Error("error fetching swaps channel: ", err)
-			}
-
-			go func() {
-				for {
-					rawSwap, ok := <-sink
-					if ok {
-						swap, err := s.normalizeUniswapSwap(*rawSwap)
-						if err != nil {
-							log.Error("error normalizing swap: ", err)
-						}
-						price, volume := s.getSwapData(swap)
-
-						t := &dia.Trade{
-							Symbol:         pair.Token0.Symbol,
-							Pair:           pair.ForeignName,
-							Price:          price,
-							Volume:         volume,
-							Time:           time.Unix(swap.Timestamp, 0),
-							ForeignTradeID: swap.ID,
-							Source:         s.exchangeName,
-						}
-						// If we need quotation of a base token, reverse pair
-						if utils.Contains(reversePairs, strings.ToLower(pair.Token1.Address.Hex())) {
-							tSwapped, err := dia.SwapTrade(*t)
-							if err == nil {
-								t = &tSwapped
-							}
-						}
-						if price > 0 {
-							log.Info("Got trade: ", t)
-							s.chanTrades <- t
-						}
-					}
-				}
-			}()
+			s.backfillPairs = append(s.backfillPairs, *pair)
+			go s.watchSwaps(*pair)
 		} else {
 			log.Info("Skipping pair due to no pairScraper being available")
 		}
 	}
 }
 
-// GetSwapsChannel returns a channel for swaps of the pair with address @pairAddress
-func (s *UniswapV3Scraper) GetSwapsChannel(pairAddress common.Address) (chan *UniswapV3Pair.UniswapV3PairSwap, error) {
+// GetSwapsChannel returns a channel for swaps of the pair with address @pairAddress and its subscription
+func (s *UniswapV3Scraper) GetSwapsChannel(pairAddress common.Address) (chan *UniswapV3Pair.UniswapV3PairSwap, event.Subscription, error) {
 	sink := make(chan *UniswapV3Pair.UniswapV3PairSwap)
 	var pairFiltererContract *UniswapV3Pair.UniswapV3PairFilterer
 
 	pairFiltererContract, err := UniswapV3Pair.NewUniswapV3PairFilterer(pairAddress, s.WsClient)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 
-	_, err = pairFiltererContract.WatchSwap(&bind.WatchOpts{}, sink, []common.Address{}, []common.Address{})
+	sub, err := pairFiltererContract.WatchSwap(&bind.WatchOpts{}, sink, []common.Address{}, []common.Address{})
 	if err != nil {
-		log.Error("error in get swaps channel: ", err)
+		return nil, nil, err
 	}
 
-	return sink, nil
+	return sink, sub, nil
+
+}
+
+// watchSwaps emits the swaps of @pair from its subscription until s is closed. While the
+// subscription is down, the checkpoint is held. Once it is renewed, the blocks since the
+// last swap received are backfilled.
+func (s *UniswapV3Scraper) watchSwaps(pair UniswapPair) {
+	down := false
+	var missedFrom uint64
+	for {
+		sink, sub, err := s.GetSwapsChannel(pair.Address)
+		if err == nil && down {
+			err = s.backfillMissed(pair, missedFrom)
+			if err != nil {
+				sub.Unsubscribe()
+			}
+		}
+		if err != nil {
+			log.Errorf("error subscribing to the swaps of %s: %v", pair.ForeignName, err)
+			if !down {
+				down, missedFrom = true, missedSince(&s.subscriptions, s.confirmations)
+			}
+			select {
+			case <-time.After(backfillRetryDelay):
+				continue
+			case <-s.shutdown:
+				return
+			}
+		}
+		if down {
+			down = false
+			s.subscriptions.restored()
+		}
 
+	receive:
+		for {
+			select {
+			case rawSwap := <-sink:
+				s.subscriptions.received(rawSwap.Raw.BlockNumber)
+				s.emitSwap(pair, *rawSwap, time.Time{})
+			case err := <-sub.Err():
+				log.Errorf("swaps subscription of %s failed: %v", pair.ForeignName, err)
+				down, missedFrom = true, missedSince(&s.subscriptions, s.confirmations)
+				break receive
+			case <-s.shutdown:
+				sub.Unsubscribe()
+				return
+			}
+		}
+	}
+}
+
+// backfillMissed emits the swaps of @pair from block @startBlock up to the current block.
+func (s *UniswapV3Scraper) backfillMissed(pair UniswapPair, startBlock uint64) error {
+	currentBlock, err := s.RestClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	if startBlock > currentBlock {
+		return nil
+	}
+	return s.backfill([]UniswapPair{pair}, startBlock, currentBlock, false)
+}
+
+// emitSwap sends the trade corresponding to @rawSwap of @pair on the trades channel
+// once its block is final. A zero @timestamp stamps the trade with the current time.
+func (s *UniswapV3Scraper) emitSwap(pair UniswapPair, rawSwap UniswapV3Pair.UniswapV3PairSwap, timestamp time.Time) {
+	if rawSwap.Raw.Removed {
+		s.confirmations.retract(rawSwap.Raw)
+		return
+	}
+	swap, err := s.normalizeUniswapSwap(rawSwap, pair)
+	if err != nil {
+		log.Error("error normalizing swap: ", err)
+	}
+	if !timestamp.IsZero() {
+		swap.Timestamp = timestamp.Unix()
+	}
+	price, volume := s.getSwapData(swap)
+
+	t := &dia.Trade{
+		Symbol:         pair.Token0.Symbol,
+		Pair:           pair.ForeignName,
+		Price:          price,
+		Volume:         volume,
+		Time:           time.Unix(swap.Timestamp, 0),
+		ForeignTradeID: swap.ID,
+		Source:         s.exchangeName,
+		QuoteAsset:     pair.Token0.asset(s.blockchain),
+		BaseAsset:      pair.Token1.asset(s.blockchain),
+	}
+	// If we need quotation of a base token, reverse pair
+	if utils.Contains(reversePairs, strings.ToLower(pair.Token1.Address.Hex())) {
+		tSwapped, err := dia.SwapTrade(*t)
+		if err == nil {
+			t = &tSwapped
+		}
+	}
+	if price > 0 {
+		log.Info("Got trade: ", t)
+		s.confirmations.add(t, rawSwap.Raw)
+	}
+}
+
+// setCheckpoint stores @block as processed once the blocks missed since the last
+// run have been backfilled, unless a swaps subscription is down.
+func (s *UniswapV3Scraper) setCheckpoint(block uint64) {
+	select {
+	case <-s.resumed:
+		if s.subscriptions.healthy() {
+			s.checkpoint.set(block)
+		}
+	default:
+	}
+}
+
+// resume backfills the blocks from the stored checkpoint up to the current block. A failed
+// backfill is retried from the checkpoint it reached, and new blocks only advance the
+// checkpoint once it succeeded.
+func (s *UniswapV3Scraper) resume() {
+	for {
+		err := s.resumeFromCheckpoint()
+		if err == nil {
+			close(s.resumed)
+			return
+		}
+		log.Error("error resuming from checkpoint: ", err)
+		select {
+		case <-time.After(backfillRetryDelay):
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+func (s *UniswapV3Scraper) resumeFromCheckpoint() error {
+	lastBlock, ok := s.checkpoint.last()
+	if !ok {
+		return nil
+	}
+	currentBlock, err := s.RestClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	<-s.pairsReady
+	return s.backfill(s.backfillPairs, lastBlock+1, currentBlock, true)
+}
+
+// Backfill emits the trades of all blocks in [@startBlock, @endBlock] on Channel(),
+// timestamped with the time of their block.
+func (s *UniswapV3Scraper) Backfill(startBlock uint64, endBlock uint64) error {
+	<-s.pairsReady
+	return s.backfill(s.backfillPairs, startBlock, endBlock, false)
+}
+
+// backfill emits the swaps of @pairs in [@startBlock, @endBlock]. With @checkpoint, the
+// checkpoint follows the final blocks backfilled.
+func (s *UniswapV3Scraper) backfill(pairs []UniswapPair, startBlock uint64, endBlock uint64, checkpoint bool) error {
+	log.Infof("backfill %s from block %d to %d", s.exchangeName, startBlock, endBlock)
+	blockTimes := newBlockTimes(s.RestClient)
+
+	for _, blocks := range blockRanges(startBlock, endBlock) {
+		for _, pair := range pairs {
+			filterer, err := UniswapV3Pair.NewUniswapV3PairFilterer(pair.Address, s.RestClient)
+			if err != nil {
+				return err
+			}
+			end := blocks[1]
+			swaps, err := filterer.FilterSwap(&bind.FilterOpts{Start: blocks[0], End: &end}, []common.Address{}, []common.Address{})
+			if err != nil {
+				return err
+			}
+			for swaps.Next() {
+				timestamp, err := blockTimes.get(swaps.Event.Raw.BlockNumber)
+				if err != nil {
+					swaps.Close()
+					return err
+				}
+				s.emitSwap(pair, *swaps.Event, timestamp)
+			}
+			err = swaps.Error()
+			swaps.Close()
+			if err != nil {
+				return err
+			}
+		}
+		if checkpoint {
+			// trades of blocks which are not final yet are still buffered
+			s.checkpoint.set(s.confirmations.finalUpTo(blocks[1]))
+		}
+		log.Infof("backfilled %s up to block %d", s.exchangeName, blocks[1])
+	}
+	if checkpoint {
+		s.checkpoint.flush()
+	}
+	return nil
 }
 
 func (s *UniswapV3Scraper) getSwapData(swap UniswapV3Swap) (price float64, volume float64) {
@@ -217,21 +394,16 @@ func (s *UniswapV3Scraper) getSwapData(swap UniswapV3Swap) (price float64, volum
 	return
 }
 
-// normalizeUniswapSwap takes a swap as returned by the swap contract's channel and converts it to a UniswapSwap type
-func (s *UniswapV3Scraper) normalizeUniswapSwap(swap UniswapV3Pair.UniswapV3PairSwap) (normalizedSwap UniswapV3Swap, err error) {
+// normalizeUniswapSwap takes a swap of @pair as returned by the swap contract's channel or filter and converts it to a UniswapSwap type
+func (s *UniswapV3Scraper) normalizeUniswapSwap(swap UniswapV3Pair.UniswapV3PairSwap, pair UniswapPair) (normalizedSwap UniswapV3Swap, err error) {
 
-	pair, err := s.GetPairByAddress(swap.Raw.Address)
-	if err != nil {
-		log.Error("error getting pair by address: ", err)
-		return
-	}
 	decimals0 := int(pair.Token0.Decimals)
 	decimals1 := int(pair.Token1.Decimals)
 	amount0, _ := new(big.Float).Quo(big.NewFloat(0).SetInt(swap.Amount0), new(big.Float).SetFloat64(math.Pow10(decimals0))).Float64()
 	amount1, _ := new(big.Float).Quo(big.NewFloat(0).SetInt(swap.Amount1), new(big.Float).SetFloat64(math.Pow10(decimals1))).Float64()
 
 	normalizedSwap = UniswapV3Swap{
-		ID:        swap.Raw.TxHash.Hex(),
+		ID:        swap.Raw.TxHash.Hex() + "-" + fmt.Sprint(swap.Raw.Index),
 		Timestamp: time.Now().Unix(),
 		Pair:      pair,
 		Amount0:   amount0,
@@ -11,6 +11,12 @@ import (
 	"time"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.ZBExchange, Centralized: true, WatchdogDelay: watchdogDelay}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewZBScraper(exchange)
+	})
+}
+
 var ZBSocketURL string = "wss://api.zb.work/websocket"
 
 type ZBSubscribe struct {
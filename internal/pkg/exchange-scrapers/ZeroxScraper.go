@@ -19,10 +19,14 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
+func init() {
+	RegisterExchange(dia.Exchange{Name: dia.ZeroxExchange, Centralized: true, BlockChain: blockchains[dia.Ethereum], WatchdogDelay: watchdogDelayLong}, func(exchange dia.Exchange, key string, secret string) APIScraper {
+		return NewZeroxScraper(exchange)
+	})
+}
+
 const (
 	zeroxContract       = "0x61935CbDd02287B511119DDb11Aeb42F1593b7Ef"
-	zeroxWsDial         = "ws://159.69.120.42:8546/"
-	zeroxRestDial       = "http://159.69.120.42:8545/"
 	zeroxLookBackBlocks = 6 * 60 * 24
 )
 
@@ -55,6 +59,7 @@ type ZeroxScraper struct {
 }
 
 func NewZeroxScraper(exchange dia.Exchange) *ZeroxScraper {
+	exchange, wsClient, restClient := dialEVMExchange(exchange)
 	scraper := &ZeroxScraper{
 		exchangeName:   exchange.Name,
 		initDone:       make(chan nothing),
@@ -66,15 +71,7 @@ func NewZeroxScraper(exchange dia.Exchange) *ZeroxScraper {
 		resubscribe:    make(chan nothing),
 		tokens:         make(map[string]*ZeroxToken),
 	}
-	wsClient, err := ethclient.Dial(zeroxWsDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.WsClient = wsClient
-	restClient, err := ethclient.Dial(zeroxRestDial)
-	if err != nil {
-		log.Fatal(err)
-	}
 	scraper.RestClient = restClient
 
 	scraper.loadTokens()
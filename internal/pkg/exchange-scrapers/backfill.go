@@ -0,0 +1,219 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// number of blocks covered by a single FilterSwap query
+	backfillBatchSize = 2000
+	// minimal time between two writes of a checkpoint to the scrapers table
+	checkpointInterval = 10 * time.Second
+	// time between two attempts to backfill or to renew a subscription
+	backfillRetryDelay = 10 * time.Second
+)
+
+// BackfillScraper is implemented by on-chain scrapers which can emit the trades of past blocks.
+type BackfillScraper interface {
+	// Backfill emits the trades of all blocks in [startBlock, endBlock] on Channel(),
+	// timestamped with the time of their block.
+	Backfill(startBlock uint64, endBlock uint64) error
+}
+
+// DEXScraperState is the state of an on-chain scraper kept in the scrapers table.
+type DEXScraperState struct {
+	// all swaps up to and including this block have been emitted
+	LastBlockNum uint64 `json:"last_block_num"`
+}
+
+// blockCheckpoint keeps track of the last processed block of an on-chain scraper
+// and persists it via RelDB.SetScraperState.
+type blockCheckpoint struct {
+	name       string
+	relDB      *models.RelDB
+	mu         sync.Mutex
+	state      DEXScraperState
+	found      bool
+	lastStored time.Time
+}
+
+// newBlockCheckpoint loads the checkpoint stored under @name in @relDB. Without
+// a connection to postgres, progress is only tracked in memory.
+func newBlockCheckpoint(name string, relDB *models.RelDB) *blockCheckpoint {
+	c := &blockCheckpoint{name: name, relDB: relDB}
+	if relDB == nil {
+		log.Warnf("no checkpoints for %s without a connection to postgres", name)
+		return c
+	}
+	err := relDB.GetScraperState(context.Background(), name, &c.state)
+	if err != nil {
+		if !errors.Is(err, pgx.ErrNoRows) {
+			log.Errorf("load scraper state of %s: %v", name, err)
+		}
+		return c
+	}
+	c.found = true
+	log.Infof("%s resumes after block %d", name, c.state.LastBlockNum)
+	return c
+}
+
+// connectRelDB returns a connection to postgres, or nil if none is available.
+func connectRelDB() *models.RelDB {
+	relDB, err := models.NewPostgresDataStore()
+	if err != nil {
+		log.Error("cannot connect to postgres: ", err)
+		return nil
+	}
+	return relDB
+}
+
+// last returns the last processed block, if any was stored.
+func (c *blockCheckpoint) last() (uint64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state.LastBlockNum, c.found
+}
+
+// set marks all blocks up to and including @block as processed. The checkpoint
+// never moves backwards and is written at most every checkpointInterval.
+func (c *blockCheckpoint) set(block uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.found && block <= c.state.LastBlockNum {
+		return
+	}
+	c.state.LastBlockNum = block
+	c.found = true
+	if time.Since(c.lastStored) >= checkpointInterval {
+		c.store()
+	}
+}
+
+// flush writes the current checkpoint.
+func (c *blockCheckpoint) flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.found {
+		c.store()
+	}
+}
+
+// must be called with c.mu held
+func (c *blockCheckpoint) store() {
+	if c.relDB == nil {
+		return
+	}
+	if err := c.relDB.SetScraperState(context.Background(), c.name, &c.state); err != nil {
+		log.Errorf("store scraper state of %s: %v", c.name, err)
+		return
+	}
+	c.lastStored = time.Now()
+}
+
+// subscriptionTracker tracks the log subscriptions of an on-chain scraper. While one of
+// them is down, swaps may be missed, so the checkpoint must not move past them.
+type subscriptionTracker struct {
+	mu   sync.Mutex
+	down int
+	// latest block of a log received on any subscription
+	lastReceived uint64
+}
+
+// received records a log of @block received on a subscription.
+func (st *subscriptionTracker) received(block uint64) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	if block > st.lastReceived {
+		st.lastReceived = block
+	}
+}
+
+// failed records a subscription going down and returns the last block received.
+func (st *subscriptionTracker) failed() uint64 {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.down++
+	return st.lastReceived
+}
+
+// restored records a subscription renewed after the blocks missed meanwhile were backfilled.
+func (st *subscriptionTracker) restored() {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.down--
+}
+
+// healthy returns whether all subscriptions are up.
+func (st *subscriptionTracker) healthy() bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	return st.down == 0
+}
+
+// missedSince records a failed subscription of @st and returns the first block whose logs
+// may have been missed: the last block received, unless @cb already emitted it.
+func missedSince(st *subscriptionTracker, cb *confirmationBuffer) uint64 {
+	last := st.failed()
+	final := cb.finalUpTo(math.MaxUint64)
+	if last == 0 {
+		return final + 1
+	}
+	if last <= final {
+		return last + 1
+	}
+	return last
+}
+
+// blockTimes caches the timestamps of blocks.
+type blockTimes struct {
+	client *ethclient.Client
+	mu     sync.Mutex
+	cache  map[uint64]time.Time
+}
+
+func newBlockTimes(client *ethclient.Client) *blockTimes {
+	return &blockTimes{
+		client: client,
+		cache:  make(map[uint64]time.Time),
+	}
+}
+
+// get returns the timestamp of block @number.
+func (bt *blockTimes) get(number uint64) (time.Time, error) {
+	bt.mu.Lock()
+	defer bt.mu.Unlock()
+	if t, ok := bt.cache[number]; ok {
+		return t, nil
+	}
+	header, err := bt.client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+	if err != nil {
+		return time.Time{}, err
+	}
+	t := time.Unix(int64(header.Time), 0)
+	bt.cache[number] = t
+	return t, nil
+}
+
+// blockRanges splits [@start, @end] into consecutive ranges of at most backfillBatchSize blocks.
+func blockRanges(start uint64, end uint64) (ranges [][2]uint64) {
+	for from := start; from <= end; from += backfillBatchSize {
+		to := from + backfillBatchSize - 1
+		if to > end {
+			to = end
+		}
+		ranges = append(ranges, [2]uint64{from, to})
+		if to == end {
+			break
+		}
+	}
+	return
+}
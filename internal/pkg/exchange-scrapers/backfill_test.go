@@ -0,0 +1,72 @@
+package scrapers
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestBlockRanges(t *testing.T) {
+	cases := []struct {
+		start, end uint64
+		want       [][2]uint64
+	}{
+		{100, 100, [][2]uint64{{100, 100}}},
+		{100, 99, nil},
+		{0, backfillBatchSize - 1, [][2]uint64{{0, backfillBatchSize - 1}}},
+		{10, 2*backfillBatchSize + 10, [][2]uint64{
+			{10, backfillBatchSize + 9},
+			{backfillBatchSize + 10, 2*backfillBatchSize + 9},
+			{2*backfillBatchSize + 10, 2*backfillBatchSize + 10},
+		}},
+	}
+	for _, c := range cases {
+		if got := blockRanges(c.start, c.end); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("blockRanges(%d, %d) = %v, want %v", c.start, c.end, got, c.want)
+		}
+	}
+}
+
+func TestBlockCheckpointIsMonotonic(t *testing.T) {
+	c := &blockCheckpoint{name: "test"}
+	if _, ok := c.last(); ok {
+		t.Fatal("empty checkpoint reported a block")
+	}
+	c.set(10)
+	c.set(5)
+	if last, ok := c.last(); !ok || last != 10 {
+		t.Errorf("checkpoint at %d, want 10", last)
+	}
+}
+
+func TestMissedSince(t *testing.T) {
+	chain := newFakeChain(100)
+	cb := newConfirmationBuffer(dia.BlockChain{Name: "Test", ConfirmationDepth: 3}, chain, nil, func(*dia.Trade) {})
+	var st subscriptionTracker
+
+	// nothing received yet, all blocks after the final one may be missed
+	if from := missedSince(&st, cb); from != 98 {
+		t.Errorf("missed since block %d, want 98", from)
+	}
+	st.restored()
+	// the last block received is pending, its other logs may be missed
+	st.received(99)
+	if from := missedSince(&st, cb); from != 99 {
+		t.Errorf("missed since block %d, want 99", from)
+	}
+	if st.healthy() {
+		t.Error("tracker healthy while a subscription is down")
+	}
+	st.restored()
+	// the last block received was emitted meanwhile
+	st.received(90)
+	chain.head = 120
+	cb.mu.Lock()
+	cb.advanceHead(chain.head)
+	cb.release()
+	cb.mu.Unlock()
+	if from := missedSince(&st, cb); from != 100 {
+		t.Errorf("missed since block %d, want 100", from)
+	}
+}
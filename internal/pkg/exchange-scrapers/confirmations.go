@@ -0,0 +1,256 @@
+package scrapers
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// time between two lookups of the current block
+const confirmationPollInterval = 10 * time.Second
+
+// headerReader is the part of ethclient.Client used for confirming blocks.
+type headerReader interface {
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// logID identifies a log within a block.
+type logID struct {
+	txHash common.Hash
+	index  uint
+}
+
+type pendingTrade struct {
+	trade     *dia.Trade
+	blockHash common.Hash
+}
+
+// confirmationBuffer holds the trades of an on-chain scraper until their block is
+// buried under the confirmation depth of the chain. Trades of blocks dropped in a
+// reorg are retracted before they are emitted.
+type confirmationBuffer struct {
+	chain  string
+	depth  uint64
+	client headerReader
+	relDB  *models.RelDB
+	emit   func(*dia.Trade)
+	// called with the last final block after the trades up to it were emitted
+	onFinal func(block uint64)
+
+	mu      sync.Mutex
+	head    uint64
+	final   uint64 // the trades of all blocks up to final have been emitted
+	pending map[uint64]map[logID]pendingTrade
+}
+
+// confirmationDepth returns the confirmation depth of @chain. It can be overridden
+// by the environment variable CONFIRMATION_DEPTH.
+func confirmationDepth(chain dia.BlockChain) uint64 {
+	if s := os.Getenv("CONFIRMATION_DEPTH"); s != "" {
+		depth, err := strconv.ParseUint(s, 10, 64)
+		if err == nil {
+			return depth
+		}
+		log.Errorf("invalid CONFIRMATION_DEPTH %s: %v", s, err)
+	}
+	return chain.ConfirmationDepth
+}
+
+// newConfirmationBuffer returns a buffer which passes final trades to @emit. Block
+// hashes are looked up in the blockdata table of @relDB, if given, and on @client.
+func newConfirmationBuffer(chain dia.BlockChain, client headerReader, relDB *models.RelDB, emit func(*dia.Trade)) *confirmationBuffer {
+	cb := &confirmationBuffer{
+		chain:   chain.Name,
+		depth:   confirmationDepth(chain),
+		client:  client,
+		relDB:   relDB,
+		emit:    emit,
+		onFinal: func(uint64) {},
+		pending: make(map[uint64]map[logID]pendingTrade),
+	}
+	head, err := client.BlockNumber(context.Background())
+	if err != nil {
+		log.Error("get current block: ", err)
+	}
+	cb.advanceHead(head)
+	cb.final = cb.lastFinal()
+	log.Infof("emitting trades on %s after %d confirmations", cb.chain, cb.depth)
+	return cb
+}
+
+// add emits @t, the trade of log @l, once its block is final. Logs of blocks which
+// are already final, as returned by filter queries, are emitted right away.
+func (cb *confirmationBuffer) add(t *dia.Trade, l types.Log) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if l.Removed {
+		cb.retractLocked(l)
+		return
+	}
+	cb.advanceHead(l.BlockNumber)
+	if l.BlockNumber <= cb.final {
+		cb.emit(t)
+		return
+	}
+	trades, ok := cb.pending[l.BlockNumber]
+	if !ok {
+		trades = make(map[logID]pendingTrade)
+		cb.pending[l.BlockNumber] = trades
+	}
+	trades[logID{txHash: l.TxHash, index: l.Index}] = pendingTrade{trade: t, blockHash: l.BlockHash}
+	cb.release()
+}
+
+// retract drops the pending trade of log @l, which was removed in a reorg.
+func (cb *confirmationBuffer) retract(l types.Log) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.retractLocked(l)
+}
+
+// must be called with cb.mu held
+func (cb *confirmationBuffer) retractLocked(l types.Log) {
+	id := logID{txHash: l.TxHash, index: l.Index}
+	if pt, ok := cb.pending[l.BlockNumber][id]; ok && pt.blockHash == l.BlockHash {
+		delete(cb.pending[l.BlockNumber], id)
+		log.Warnf("retracted trade %s of block %d, removed in a reorg", pt.trade.ForeignTradeID, l.BlockNumber)
+		return
+	}
+	if l.BlockNumber <= cb.final {
+		log.Errorf("log %s-%d of block %d removed after it was emitted, reorg deeper than %d blocks", l.TxHash.Hex(), l.Index, l.BlockNumber, cb.depth)
+	}
+}
+
+// run releases trades as new blocks arrive until @shutdown is closed.
+func (cb *confirmationBuffer) run(shutdown chan nothing) {
+	ticker := time.NewTicker(confirmationPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			head, err := cb.client.BlockNumber(context.Background())
+			if err != nil {
+				log.Error("get current block: ", err)
+				continue
+			}
+			cb.mu.Lock()
+			cb.advanceHead(head)
+			cb.release()
+			cb.mu.Unlock()
+		case <-shutdown:
+			return
+		}
+	}
+}
+
+// finalUpTo returns @block or, if @block is not final yet, the last final block.
+func (cb *confirmationBuffer) finalUpTo(block uint64) uint64 {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	if block > cb.final {
+		return cb.final
+	}
+	return block
+}
+
+// must be called with cb.mu held
+func (cb *confirmationBuffer) advanceHead(block uint64) {
+	if block > cb.head {
+		cb.head = block
+	}
+}
+
+// must be called with cb.mu held
+func (cb *confirmationBuffer) lastFinal() uint64 {
+	if cb.head < cb.depth {
+		return 0
+	}
+	return cb.head - cb.depth
+}
+
+// release emits the pending trades of all final blocks in block order. Trades whose
+// block hash is no longer canonical are dropped.
+// must be called with cb.mu held
+func (cb *confirmationBuffer) release() {
+	lastFinal := cb.lastFinal()
+	if lastFinal <= cb.final {
+		return
+	}
+	var blocks []uint64
+	for number := range cb.pending {
+		if number <= lastFinal {
+			blocks = append(blocks, number)
+		}
+	}
+	sort.Slice(blocks, func(i, j int) bool { return blocks[i] < blocks[j] })
+
+	for _, number := range blocks {
+		canonical, blockTime, err := cb.canonicalBlock(number)
+		if err != nil {
+			// keep the block pending and retry with the next head
+			log.Errorf("get hash of block %d: %v", number, err)
+			cb.final = number - 1
+			cb.onFinal(cb.final)
+			return
+		}
+		var ids []logID
+		for id := range cb.pending[number] {
+			ids = append(ids, id)
+		}
+		sort.Slice(ids, func(i, j int) bool {
+			if ids[i].txHash != ids[j].txHash {
+				return ids[i].txHash.Hex() < ids[j].txHash.Hex()
+			}
+			return ids[i].index < ids[j].index
+		})
+		for _, id := range ids {
+			pt := cb.pending[number][id]
+			if pt.blockHash != canonical {
+				log.Warnf("retracted trade %s of block %d, block %s was reorged out", pt.trade.ForeignTradeID, number, pt.blockHash.Hex())
+				continue
+			}
+			// the trade carries the time it was seen, which lags behind its block by the confirmation delay
+			pt.trade.Time = blockTime
+			cb.emit(pt.trade)
+		}
+		delete(cb.pending, number)
+	}
+	cb.final = lastFinal
+	cb.onFinal(cb.final)
+}
+
+// canonicalBlock returns the hash and the time of block @number on the canonical chain.
+// Blocks tracked in the blockdata table are only stored once they are final.
+func (cb *confirmationBuffer) canonicalBlock(number uint64) (common.Hash, time.Time, error) {
+	if cb.relDB != nil {
+		blockdata, err := cb.relDB.GetBlockData(cb.chain, int64(number))
+		if err == nil {
+			hash, ok := blockdata.Data["Hash"].(string)
+			switch blockTime := blockdata.Data["Time"].(type) {
+			case float64:
+				if ok {
+					return common.HexToHash(hash), time.Unix(int64(blockTime), 0), nil
+				}
+			case uint64:
+				if ok {
+					return common.HexToHash(hash), time.Unix(int64(blockTime), 0), nil
+				}
+			}
+		}
+	}
+	header, err := cb.client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(number))
+	if err != nil {
+		return common.Hash{}, time.Time{}, err
+	}
+	return header.Hash(), time.Unix(int64(header.Time), 0), nil
+}
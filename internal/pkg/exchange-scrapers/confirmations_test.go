@@ -0,0 +1,95 @@
+package scrapers
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// fakeChain serves the canonical headers of a chain which can be reorged.
+type fakeChain struct {
+	head    uint64
+	headers map[uint64]*types.Header
+}
+
+func newFakeChain(head uint64) *fakeChain {
+	c := &fakeChain{head: head, headers: make(map[uint64]*types.Header)}
+	for n := uint64(0); n <= head; n++ {
+		c.setBlock(n, "canonical")
+	}
+	return c
+}
+
+func (c *fakeChain) setBlock(number uint64, fork string) common.Hash {
+	header := &types.Header{Number: new(big.Int).SetUint64(number), Time: 1600000000 + 12*number, Extra: []byte(fork)}
+	c.headers[number] = header
+	return header.Hash()
+}
+
+func (c *fakeChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return c.head, nil
+}
+
+func (c *fakeChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.headers[number.Uint64()], nil
+}
+
+func (c *fakeChain) swapLog(number uint64, tx byte) types.Log {
+	return types.Log{BlockNumber: number, BlockHash: c.headers[number].Hash(), TxHash: common.Hash{tx}}
+}
+
+func TestConfirmationBuffer(t *testing.T) {
+	chain := newFakeChain(100)
+	var emitted []string
+	times := make(map[string]time.Time)
+	cb := newConfirmationBuffer(dia.BlockChain{Name: "Test", ConfirmationDepth: 3}, chain, nil, func(t *dia.Trade) {
+		emitted = append(emitted, t.ForeignTradeID)
+		times[t.ForeignTradeID] = t.Time
+	})
+	var final uint64
+	cb.onFinal = func(block uint64) { final = block }
+
+	// trades of final blocks pass right away
+	cb.add(&dia.Trade{ForeignTradeID: "final"}, chain.swapLog(97, 1))
+	if len(emitted) != 1 {
+		t.Fatalf("trade of final block not emitted: %v", emitted)
+	}
+
+	orphaned := chain.swapLog(99, 2)
+	cb.add(&dia.Trade{ForeignTradeID: "orphaned"}, orphaned)
+	removed := chain.swapLog(100, 3)
+	cb.add(&dia.Trade{ForeignTradeID: "removed"}, removed)
+	cb.add(&dia.Trade{ForeignTradeID: "kept", Time: time.Now()}, chain.swapLog(100, 4))
+	if len(emitted) != 1 {
+		t.Fatalf("trades of pending blocks emitted: %v", emitted)
+	}
+
+	// blocks 99 and 100 are replaced, but only the removal of block 100 is seen
+	chain.setBlock(99, "fork")
+	removed.Removed = true
+	cb.add(nil, removed)
+	chain.setBlock(100, "canonical")
+	chain.setBlock(101, "fork")
+	chain.setBlock(102, "fork")
+	chain.setBlock(103, "fork")
+	cb.add(&dia.Trade{ForeignTradeID: "new"}, chain.swapLog(103, 5))
+
+	want := []string{"final", "kept"}
+	if len(emitted) != len(want) || emitted[0] != want[0] || emitted[1] != want[1] {
+		t.Errorf("emitted %v, want %v", emitted, want)
+	}
+	if want := time.Unix(1600000000+12*100, 0); !times["kept"].Equal(want) {
+		t.Errorf("trade stamped with %v, want the time of its block %v", times["kept"], want)
+	}
+	if final != 100 {
+		t.Errorf("final block %d, want 100", final)
+	}
+	if n := cb.finalUpTo(103); n != 100 {
+		t.Errorf("finalUpTo(103) = %d, want 100", n)
+	}
+}
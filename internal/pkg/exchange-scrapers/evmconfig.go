@@ -0,0 +1,166 @@
+package scrapers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// uniswapV2Fork is the Fork of exchanges scraped by the UniswapScraper.
+const uniswapV2Fork = "UniswapV2"
+
+// EVMExchangeConfig holds the chain and RPC endpoints of an on-chain exchange on an
+// EVM chain. It is read from config/evm/<exchange>.json.
+// Forks of a supported exchange are defined entirely in their configuration: a file
+// with Name and Fork set registers the exchange Name, scraped like the Fork.
+type EVMExchangeConfig struct {
+	Name string
+	Fork string
+	// Name of the chain as in the blockchains map, e.g. dia.BINANCESMARTCHAIN
+	Chain       string
+	ChainID     int64
+	NativeToken string
+	WsDial      string
+	RestDial    string
+	// Address of the exchange's factory or registry contract
+	FactoryAddress string
+	// Fee charged on the input amount of a swap, e.g. 0.003. If set, prices are
+	// corrected for the fee.
+	FeeTier float64
+	// Tokens whose pairs are reversed, as listed in uniswap/reverse_tokens.json.
+	// If not set, the list of that file is used.
+	ReverseTokens []UniswapToken
+	// Pairs with less than MinLiquidity tokens in either reserve are not scraped.
+	MinLiquidity float64
+}
+
+// loadEVMExchangeConfig reads the configuration of the exchange @name.
+func loadEVMExchangeConfig(name string) (config EVMExchangeConfig, err error) {
+	data, err := ioutil.ReadFile(configCollectors.ConfigFileConnectors("evm/"+name, ".json"))
+	if err != nil {
+		return
+	}
+	return parseEVMExchangeConfig(data)
+}
+
+func parseEVMExchangeConfig(data []byte) (config EVMExchangeConfig, err error) {
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return
+	}
+	if _, ok := blockchains[config.Chain]; !ok {
+		err = fmt.Errorf("unknown chain %s", config.Chain)
+		return
+	}
+	if config.WsDial == "" || config.RestDial == "" {
+		err = fmt.Errorf("RPC endpoints missing for chain %s", config.Chain)
+		return
+	}
+	if config.FeeTier < 0 || config.FeeTier >= 1 {
+		err = fmt.Errorf("invalid fee tier %v", config.FeeTier)
+		return
+	}
+	if config.Fork == "" {
+		return
+	}
+	if config.Fork != uniswapV2Fork {
+		err = fmt.Errorf("unsupported fork %s", config.Fork)
+		return
+	}
+	if config.Name == "" || config.FactoryAddress == "" {
+		err = fmt.Errorf("name or factory address missing for %s fork", config.Fork)
+	}
+	return
+}
+
+// reverseTokens returns the addresses of the configured reverse tokens, or nil
+// if none are configured.
+func (c EVMExchangeConfig) reverseTokens() *[]string {
+	if len(c.ReverseTokens) == 0 {
+		return nil
+	}
+	var addresses []string
+	for _, token := range c.ReverseTokens {
+		addresses = append(addresses, token.Address.Hex())
+	}
+	return &addresses
+}
+
+// BlockChain returns the configured chain.
+func (c EVMExchangeConfig) BlockChain() dia.BlockChain {
+	chain := blockchains[c.Chain]
+	if c.ChainID != 0 {
+		chain.ChainID = c.ChainID
+	}
+	if c.NativeToken != "" {
+		chain.NativeToken = c.NativeToken
+	}
+	return chain
+}
+
+// apply returns @exchange with the configured chain and factory contract.
+func (c EVMExchangeConfig) apply(exchange dia.Exchange) dia.Exchange {
+	exchange.BlockChain = c.BlockChain()
+	if c.FactoryAddress != "" {
+		exchange.Contract = common.HexToAddress(c.FactoryAddress)
+	}
+	return exchange
+}
+
+// dial connects to the websocket and http endpoints and checks that both serve the configured chain.
+func (c EVMExchangeConfig) dial() (wsClient *ethclient.Client, restClient *ethclient.Client, err error) {
+	wsClient, err = ethclient.Dial(c.WsDial)
+	if err != nil {
+		return
+	}
+	restClient, err = ethclient.Dial(c.RestDial)
+	if err != nil {
+		return
+	}
+	chainID := c.BlockChain().ChainID
+	if chainID == 0 {
+		return
+	}
+	for _, client := range []*ethclient.Client{wsClient, restClient} {
+		id, err := client.ChainID(context.Background())
+		if err != nil {
+			return wsClient, restClient, err
+		}
+		if id.Int64() != chainID {
+			return wsClient, restClient, fmt.Errorf("endpoint serves chain ID %d instead of %d", id.Int64(), chainID)
+		}
+	}
+	return
+}
+
+// dialEVMExchange loads the configuration of @exchange and connects to its chain.
+// The returned exchange carries the configured chain and factory contract.
+func dialEVMExchange(exchange dia.Exchange) (dia.Exchange, *ethclient.Client, *ethclient.Client) {
+	return mustLoadEVMExchangeConfig(exchange.Name).connect(exchange)
+}
+
+// mustLoadEVMExchangeConfig reads the configuration of the exchange @name and exits if it is invalid.
+func mustLoadEVMExchangeConfig(name string) EVMExchangeConfig {
+	config, err := loadEVMExchangeConfig(name)
+	if err != nil {
+		log.Fatalf("load chain configuration of %s: %v", name, err)
+	}
+	return config
+}
+
+// connect applies the configuration to @exchange and connects to its chain.
+func (c EVMExchangeConfig) connect(exchange dia.Exchange) (dia.Exchange, *ethclient.Client, *ethclient.Client) {
+	exchange = c.apply(exchange)
+	log.Infof("connecting %s to %s", exchange.Name, exchange.BlockChain.Name)
+	wsClient, restClient, err := c.dial()
+	if err != nil {
+		log.Fatalf("connect %s to %s: %v", exchange.Name, exchange.BlockChain.Name, err)
+	}
+	return exchange, wsClient, restClient
+}
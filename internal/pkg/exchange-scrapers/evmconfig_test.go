@@ -0,0 +1,78 @@
+package scrapers
+
+import (
+	"io/ioutil"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEVMExchangeConfigs(t *testing.T) {
+	registerUniswapV2Forks("../../../config/evm")
+	files, err := filepath.Glob("../../../config/evm/*.json")
+	if err != nil || len(files) == 0 {
+		t.Fatal("no chain configurations found: ", err)
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".json")
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		config, err := parseEVMExchangeConfig(data)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+			continue
+		}
+		exchange, ok := Exchanges[name]
+		if !ok {
+			t.Errorf("%s: configured exchange is not registered", name)
+			continue
+		}
+		configured := config.apply(exchange)
+		if configured.BlockChain.Name != exchange.BlockChain.Name {
+			t.Errorf("%s: configured on %s but registered on %s", name, configured.BlockChain.Name, exchange.BlockChain.Name)
+		}
+		if configured.BlockChain.ChainID == 0 {
+			t.Errorf("%s: no chain ID", name)
+		}
+	}
+}
+
+func TestParseEVMExchangeConfig(t *testing.T) {
+	config, err := parseEVMExchangeConfig([]byte(`{"Chain": "Polygon", "WsDial": "wss://node", "RestDial": "https://node", "FactoryAddress": "0xe7fb3e833efe5f9c441105eb65ef8b261266423b"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chain := config.BlockChain(); chain.ChainID != 137 || chain.NativeToken != "MATIC" {
+		t.Errorf("unexpected chain %+v", chain)
+	}
+	if _, err := parseEVMExchangeConfig([]byte(`{"Chain": "Moon", "WsDial": "wss://node", "RestDial": "https://node"}`)); err == nil {
+		t.Error("unknown chain accepted")
+	}
+}
+
+func TestParseUniswapV2Fork(t *testing.T) {
+	config, err := parseEVMExchangeConfig([]byte(`{"Name": "Fork", "Fork": "UniswapV2", "Chain": "Polygon", "WsDial": "wss://node", "RestDial": "https://node", "FactoryAddress": "0x5757371414417b8C6CAad45bAeF941aBc7d3Ab32", "FeeTier": 0.003, "ReverseTokens": [{"Address": "0x831753dd7087cac61ab5644b308642cc1c33dc13", "Symbol": "QUICK"}]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reverse := config.reverseTokens(); reverse == nil || (*reverse)[0] != "0x831753DD7087CaC61aB5644b308642cc1c33Dc13" {
+		t.Errorf("unexpected reverse tokens %v", reverse)
+	}
+	for _, fork := range []string{
+		`{"Name": "Fork", "Fork": "UniswapV2", "Chain": "Polygon", "WsDial": "wss://node", "RestDial": "https://node"}`,
+		`{"Name": "Fork", "Fork": "Curvefi", "Chain": "Polygon", "WsDial": "wss://node", "RestDial": "https://node", "FactoryAddress": "0x5757371414417b8C6CAad45bAeF941aBc7d3Ab32"}`,
+		`{"Name": "Fork", "Fork": "UniswapV2", "Chain": "Polygon", "WsDial": "wss://node", "RestDial": "https://node", "FactoryAddress": "0x5757371414417b8C6CAad45bAeF941aBc7d3Ab32", "FeeTier": 3}`,
+	} {
+		if _, err := parseEVMExchangeConfig([]byte(fork)); err == nil {
+			t.Errorf("invalid fork accepted: %s", fork)
+		}
+	}
+
+	price, _, _ := getSwapData(UniswapSwap{Amount1In: 100, Amount0Out: 1}, 0.003)
+	if math.Abs(price-99.7) > 1e-9 {
+		t.Errorf("fee corrected price %v, want 99.7", price)
+	}
+}
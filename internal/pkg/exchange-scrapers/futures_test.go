@@ -0,0 +1,82 @@
+package scrapers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestParseFuturesTrades(t *testing.T) {
+	quarterly := futuresContract{Underlying: "BTC", Expiry: time.Date(2020, 3, 27, 8, 0, 0, 0, time.UTC)}
+	perpetual := futuresContract{Underlying: "BTC", Perpetual: true}
+
+	bitmex, err := parseBitmexFuturesTrades([]byte(`{"table":"trade","action":"insert","data":[
+		{"timestamp":"2020-01-02T03:04:05.678Z","symbol":"XBTUSD","side":"Sell","size":100,"price":7000.5,"trdMatchID":"a-b"}]}`), perpetual)
+	if err != nil {
+		t.Fatal(err)
+	}
+	huobi, err := parseHuobiFuturesTrades([]byte(`{"ch":"market.BTC_CQ.trade.detail","tick":{"data":[
+		{"id":601595424,"price":7200.1,"amount":2,"direction":"buy","ts":1577934245678}]}}`), "BTC_CQ", quarterly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ftxMessage tradeMessageFTX
+	err = json.Unmarshal([]byte(`{"channel":"trades","market":"BTC-PERP","type":"update","data":[
+		{"id":42,"price":7001,"size":0.5,"side":"buy","time":"2020-01-02T03:04:05.678Z"}]}`), &ftxMessage)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bitflyer, err := parseBitflyerFuturesTrades([]byte(`{"jsonrpc":"2.0","method":"channelMessage","params":{"channel":"lightning_executions_BTCJPY27MAR2020","message":[
+		{"id":7,"side":"SELL","price":780000,"size":0.01,"exec_date":"2020-01-02T03:04:05.678Z"}]}}`), "BTCJPY27MAR2020")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	at := time.Date(2020, 1, 2, 3, 4, 5, 678e6, time.UTC)
+	tests := []struct {
+		name   string
+		trades []*dia.FuturesTrade
+		want   dia.FuturesTrade
+	}{
+		{"bitmex", bitmex, dia.FuturesTrade{Exchange: "Bitmex", Contract: "XBTUSD", Underlying: "BTC", Perpetual: true, Price: 7000.5, Size: 100, Side: dia.FuturesSell, Time: at, ForeignTradeID: "a-b"}},
+		{"huobi", huobi, dia.FuturesTrade{Exchange: dia.HuobiExchange, Contract: "BTC_CQ", Underlying: "BTC", Expiry: quarterly.Expiry, Price: 7200.1, Size: 2, Side: dia.FuturesBuy, Time: at, ForeignTradeID: "601595424"}},
+		{"ftx", ftxMessage.trades(perpetual), dia.FuturesTrade{Exchange: dia.FTX, Contract: "BTC-PERP", Underlying: "BTC", Perpetual: true, Price: 7001, Size: 0.5, Side: dia.FuturesBuy, Time: at, ForeignTradeID: "42"}},
+		{"bitflyer", bitflyer, dia.FuturesTrade{Exchange: "Bitflyer", Contract: "BTCJPY27MAR2020", Underlying: "BTC", Expiry: time.Date(2020, 3, 27, 0, 0, 0, 0, time.UTC), Price: 780000, Size: 0.01, Side: dia.FuturesSell, Time: at, ForeignTradeID: "7"}},
+	}
+	for _, test := range tests {
+		if len(test.trades) != 1 {
+			t.Errorf("%s: got %d trades, want 1", test.name, len(test.trades))
+			continue
+		}
+		got := *test.trades[0]
+		if !got.Time.Equal(test.want.Time) || !got.Expiry.Equal(test.want.Expiry) {
+			t.Errorf("%s: got times %v, %v, want %v, %v", test.name, got.Time, got.Expiry, test.want.Time, test.want.Expiry)
+		}
+		got.Time, got.Expiry = test.want.Time, test.want.Expiry
+		if got != test.want {
+			t.Errorf("%s: got %+v, want %+v", test.name, got, test.want)
+		}
+	}
+
+	// the snapshot sent on subscription is no new trade
+	trades, err := parseBitmexFuturesTrades([]byte(`{"table":"trade","action":"partial","data":[{"symbol":"XBTUSD","price":1}]}`), perpetual)
+	if err != nil || len(trades) != 0 {
+		t.Errorf("got %d trades, %v for the snapshot", len(trades), err)
+	}
+}
+
+func TestFuturesSide(t *testing.T) {
+	for side, want := range map[string]string{
+		"Buy":  dia.FuturesBuy,
+		"SELL": dia.FuturesSell,
+		"sell": dia.FuturesSell,
+		"":     "",
+		"Bid":  "",
+	} {
+		if got := futuresSide(side); got != want {
+			t.Errorf("futuresSide(%q) = %q, want %q", side, got, want)
+		}
+	}
+}
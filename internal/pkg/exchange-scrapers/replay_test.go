@@ -0,0 +1,452 @@
+package scrapers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	ws "github.com/gorilla/websocket"
+)
+
+// The replay harness serves recorded exchange traffic from a local stand-in server,
+// so that scrapers can be tested offline. Run the tests with -record to refresh the
+// fixtures in testdata/replay from the live exchange APIs:
+//
+//	go test ./internal/pkg/exchange-scrapers -run Replay -record
+var (
+	record         = flag.Bool("record", false, "record replay fixtures from the live exchange APIs")
+	recordDuration = flag.Duration("record.duration", 20*time.Second, "how long to record exchange traffic")
+)
+
+// replayTimeout bounds the time a replayed scraper may take to emit its trades.
+const replayTimeout = 10 * time.Second
+
+// replayFrame is a websocket frame sent by the exchange. It is sent as soon as the
+// scraper has written @After messages, such as subscriptions or pongs.
+type replayFrame struct {
+	After  int    `json:"after"`
+	Text   string `json:"text,omitempty"`
+	Binary []byte `json:"binary,omitempty"`
+	// Gzip marks binary frames stored decompressed in Text.
+	Gzip bool `json:"gzip,omitempty"`
+}
+
+// replayExchange is an HTTP request of the scraper together with the recorded response.
+type replayExchange struct {
+	Method      string `json:"method"`
+	URI         string `json:"uri"`
+	Body        string `json:"body,omitempty"`
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Response    string `json:"response"`
+}
+
+// replayConnection holds the frames sent by the exchange on a websocket connection
+// opened on @URI.
+type replayConnection struct {
+	URI    string        `json:"uri"`
+	Frames []replayFrame `json:"frames"`
+}
+
+// replayFixture holds the traffic of a single scraper session.
+type replayFixture struct {
+	WSUpstream   string             `json:"wsUpstream,omitempty"`
+	HTTPUpstream string             `json:"httpUpstream,omitempty"`
+	Connections  []replayConnection `json:"connections,omitempty"`
+	HTTP         []replayExchange   `json:"http,omitempty"`
+}
+
+// replayServer is the local stand-in for an exchange's websocket and HTTP APIs.
+// It listens in plain text for URLs pointed at it and over TLS for redirected
+// connections to hardcoded https:// and wss:// endpoints.
+type replayServer struct {
+	t         *testing.T
+	file      string
+	server    *httptest.Server
+	tlsServer *httptest.Server
+	mu        sync.Mutex
+	fixture   replayFixture
+	// served marks the recorded connections already replayed.
+	served map[int]bool
+	// sent holds the text messages the scraper wrote on replayed connections.
+	sent []string
+	// transport reaches the live APIs while recording.
+	transport http.RoundTripper
+}
+
+// newReplayServer starts a stand-in server for the fixture testdata/replay/@name.json.
+func newReplayServer(t *testing.T, name string) *replayServer {
+	rs := &replayServer{
+		t:         t,
+		file:      filepath.Join("testdata", "replay", name+".json"),
+		served:    make(map[int]bool),
+		transport: http.DefaultTransport,
+	}
+	data, err := ioutil.ReadFile(rs.file)
+	if err != nil {
+		t.Fatalf("read fixture: %v", err)
+	}
+	if err = json.Unmarshal(data, &rs.fixture); err != nil {
+		t.Fatalf("parse fixture %s: %v", rs.file, err)
+	}
+	if *record {
+		rs.fixture.Connections = nil
+		rs.fixture.HTTP = nil
+	}
+
+	rs.server = httptest.NewServer(http.HandlerFunc(rs.handle))
+	rs.tlsServer = httptest.NewTLSServer(http.HandlerFunc(rs.handle))
+	t.Cleanup(rs.close)
+	return rs
+}
+
+// wsURL returns the websocket URL of @path on the stand-in server.
+func (rs *replayServer) wsURL(path string) string {
+	return "ws" + strings.TrimPrefix(rs.server.URL, "http") + path
+}
+
+// redirect routes all connections made through the default websocket dialer and
+// the default HTTP transport to the stand-in server, for scrapers and client
+// libraries with hardcoded endpoints.
+func (rs *replayServer) redirect() {
+	addr := rs.tlsServer.Listener.Addr().String()
+	dial := func(ctx context.Context, network, _ string) (net.Conn, error) {
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	dialer := ws.DefaultDialer
+	ws.DefaultDialer = &ws.Dialer{
+		NetDialContext:   dial,
+		TLSClientConfig:  &tls.Config{InsecureSkipVerify: true},
+		HandshakeTimeout: 5 * time.Second,
+	}
+	transport := http.DefaultTransport
+	http.DefaultTransport = &http.Transport{
+		DialContext:     dial,
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+	rs.t.Cleanup(func() {
+		ws.DefaultDialer = dialer
+		http.DefaultTransport = transport
+	})
+}
+
+func (rs *replayServer) handle(w http.ResponseWriter, r *http.Request) {
+	if ws.IsWebSocketUpgrade(r) {
+		rs.handleWebsocket(w, r)
+		return
+	}
+	body, _ := ioutil.ReadAll(r.Body)
+	exchange := replayExchange{Method: r.Method, URI: r.URL.RequestURI(), Body: string(body)}
+
+	if *record {
+		rs.recordHTTP(&exchange)
+	} else if !rs.lookupHTTP(&exchange) {
+		rs.t.Logf("replay: no recorded response for %s %s", exchange.Method, exchange.URI)
+		http.NotFound(w, r)
+		return
+	}
+	if exchange.ContentType != "" {
+		w.Header().Set("Content-Type", exchange.ContentType)
+	}
+	w.WriteHeader(exchange.Status)
+	w.Write([]byte(exchange.Response))
+}
+
+func (rs *replayServer) lookupHTTP(exchange *replayExchange) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for _, recorded := range rs.fixture.HTTP {
+		if recorded.Method == exchange.Method && recorded.URI == exchange.URI && recorded.Body == exchange.Body {
+			*exchange = recorded
+			return true
+		}
+	}
+	return false
+}
+
+func (rs *replayServer) recordHTTP(exchange *replayExchange) {
+	req, err := http.NewRequest(exchange.Method, rs.fixture.HTTPUpstream+exchange.URI, strings.NewReader(exchange.Body))
+	if err != nil {
+		rs.t.Fatalf("record: %v", err)
+	}
+	if exchange.Body != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	resp, err := rs.transport.RoundTrip(req)
+	if err != nil {
+		rs.t.Errorf("record %s: %v", req.URL, err)
+		exchange.Status = http.StatusBadGateway
+		return
+	}
+	defer resp.Body.Close()
+	data, _ := ioutil.ReadAll(resp.Body)
+	exchange.Status = resp.StatusCode
+	exchange.ContentType = resp.Header.Get("Content-Type")
+	exchange.Response = string(data)
+
+	rs.mu.Lock()
+	rs.fixture.HTTP = append(rs.fixture.HTTP, *exchange)
+	rs.mu.Unlock()
+}
+
+func (rs *replayServer) handleWebsocket(w http.ResponseWriter, r *http.Request) {
+	upgrader := ws.Upgrader{CheckOrigin: func(*http.Request) bool { return true }}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		rs.t.Errorf("replay: upgrade: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	uri := r.URL.RequestURI()
+	if *record {
+		rs.recordWebsocket(conn, uri)
+		return
+	}
+
+	frames, ok := rs.nextConnection(uri)
+	if !ok {
+		// Connections without recorded traffic, such as reconnects, are held open.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}
+
+	// Count the scraper's messages and send each frame once its precondition holds.
+	received := make(chan int)
+	go func() {
+		defer close(received)
+		for n := 1; ; n++ {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if messageType == ws.TextMessage {
+				rs.mu.Lock()
+				rs.sent = append(rs.sent, string(data))
+				rs.mu.Unlock()
+			}
+			received <- n
+		}
+	}()
+	count := 0
+	for _, frame := range frames {
+		for count < frame.After {
+			n, ok := <-received
+			if !ok {
+				return
+			}
+			count = n
+		}
+		messageType, data := frame.message()
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+	for range received {
+	}
+}
+
+// sentMessages returns the text messages the scraper wrote on replayed connections so far.
+func (rs *replayServer) sentMessages() []string {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	return append([]string{}, rs.sent...)
+}
+
+// nextConnection returns the frames of the first recorded connection on @uri
+// which has not been replayed yet.
+func (rs *replayServer) nextConnection(uri string) ([]replayFrame, bool) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	for i, connection := range rs.fixture.Connections {
+		if connection.URI == uri && !rs.served[i] {
+			rs.served[i] = true
+			return connection.Frames, true
+		}
+	}
+	return nil, false
+}
+
+// recordWebsocket proxies the scraper's connection to the live API and records
+// the frames sent by the exchange.
+func (rs *replayServer) recordWebsocket(conn *ws.Conn, uri string) {
+	dialer := &ws.Dialer{Proxy: http.ProxyFromEnvironment, HandshakeTimeout: 10 * time.Second}
+	upstream, _, err := dialer.Dial(rs.fixture.WSUpstream+uri, nil)
+	if err != nil {
+		rs.t.Errorf("record: dial %s: %v", rs.fixture.WSUpstream+uri, err)
+		return
+	}
+	defer upstream.Close()
+
+	rs.mu.Lock()
+	index := len(rs.fixture.Connections)
+	rs.fixture.Connections = append(rs.fixture.Connections, replayConnection{URI: uri})
+	rs.mu.Unlock()
+
+	var countLock sync.Mutex
+	count := 0
+	go func() {
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				upstream.Close()
+				return
+			}
+			countLock.Lock()
+			count++
+			countLock.Unlock()
+			if err := upstream.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}()
+	for {
+		messageType, data, err := upstream.ReadMessage()
+		if err != nil {
+			return
+		}
+		countLock.Lock()
+		frame := newReplayFrame(count, messageType, data)
+		countLock.Unlock()
+
+		rs.mu.Lock()
+		rs.fixture.Connections[index].Frames = append(rs.fixture.Connections[index].Frames, frame)
+		rs.mu.Unlock()
+		if err := conn.WriteMessage(messageType, data); err != nil {
+			return
+		}
+	}
+}
+
+func newReplayFrame(after int, messageType int, data []byte) replayFrame {
+	frame := replayFrame{After: after}
+	if messageType == ws.TextMessage {
+		frame.Text = string(data)
+		return frame
+	}
+	if reader, err := gzip.NewReader(bytes.NewReader(data)); err == nil {
+		if text, err := ioutil.ReadAll(reader); err == nil {
+			frame.Text = string(text)
+			frame.Gzip = true
+			return frame
+		}
+	}
+	frame.Binary = data
+	return frame
+}
+
+func (frame replayFrame) message() (int, []byte) {
+	switch {
+	case frame.Gzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write([]byte(frame.Text))
+		zw.Close()
+		return ws.BinaryMessage, buf.Bytes()
+	case frame.Binary != nil:
+		return ws.BinaryMessage, frame.Binary
+	default:
+		return ws.TextMessage, []byte(frame.Text)
+	}
+}
+
+func (rs *replayServer) close() {
+	for _, server := range []*httptest.Server{rs.server, rs.tlsServer} {
+		server.CloseClientConnections()
+		server.Close()
+	}
+	if !*record {
+		return
+	}
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	data, err := json.MarshalIndent(rs.fixture, "", "  ")
+	if err != nil {
+		rs.t.Errorf("record: %v", err)
+		return
+	}
+	if err = ioutil.WriteFile(rs.file, append(data, '\n'), 0644); err != nil {
+		rs.t.Errorf("record: %v", err)
+	}
+	rs.t.Logf("recorded %d connections and %d HTTP responses to %s", len(rs.fixture.Connections), len(rs.fixture.HTTP), rs.file)
+}
+
+// collectTrades reads @n trades from @c. While recording, it instead drains @c for
+// the recording duration and logs the trades, so that expectations can be updated.
+func collectTrades(t *testing.T, c chan *dia.Trade, n int) []dia.Trade {
+	var trades []dia.Trade
+	if *record {
+		deadline := time.After(*recordDuration)
+		for {
+			select {
+			case trade := <-c:
+				t.Logf("recorded trade: %+v", *trade)
+			case <-deadline:
+				t.Skip("fixture recorded, review the expected trades")
+			}
+		}
+	}
+	timeout := time.After(replayTimeout)
+	for len(trades) < n {
+		select {
+		case trade := <-c:
+			trades = append(trades, *trade)
+		case <-timeout:
+			t.Fatalf("received %d of %d trades before timeout", len(trades), n)
+		}
+	}
+	return trades
+}
+
+// checkTrades compares the replayed trades with the expected ones. Trades of
+// different pairs may arrive interleaved, so only the order within a pair matters.
+// Trades expected with a zero Time are stamped on arrival and must lie in [@start, now].
+func checkTrades(t *testing.T, start time.Time, got []dia.Trade, want []dia.Trade) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d trades, want %d", len(got), len(want))
+	}
+	byPair := func(trades []dia.Trade) func(i, j int) bool {
+		return func(i, j int) bool { return trades[i].Pair < trades[j].Pair }
+	}
+	sort.SliceStable(got, byPair(got))
+	sort.SliceStable(want, byPair(want))
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Symbol != w.Symbol || g.Pair != w.Pair || g.Source != w.Source || g.ForeignTradeID != w.ForeignTradeID {
+			t.Errorf("trade %d: got %s %s on %s (id %s), want %s %s on %s (id %s)", i, g.Symbol, g.Pair, g.Source, g.ForeignTradeID, w.Symbol, w.Pair, w.Source, w.ForeignTradeID)
+		}
+		if math.Abs(g.Price-w.Price) > 1e-9*math.Abs(w.Price) {
+			t.Errorf("trade %d: got price %v, want %v", i, g.Price, w.Price)
+		}
+		if math.Abs(g.Volume-w.Volume) > 1e-9*math.Abs(w.Volume) {
+			t.Errorf("trade %d: got volume %v, want %v", i, g.Volume, w.Volume)
+		}
+		if w.Time.IsZero() {
+			if g.Time.Before(start) || g.Time.After(time.Now()) {
+				t.Errorf("trade %d: time %v not stamped on arrival", i, g.Time)
+			}
+		} else if !g.Time.Equal(w.Time) {
+			t.Errorf("trade %d: got time %v, want %v", i, g.Time, w.Time)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// volumeWindow is the time range of the volume compared with MinVolume24h.
+const volumeWindow = 24 * time.Hour
+
+// ExchangeRules configure how the exchanges of an asset contribute to its filters on all exchanges.
+type ExchangeRules struct {
+	// Weights of the exchanges by name. Exchanges without weight have the weight 1.
+	Weights map[string]float64
+	// MinVolume24h is the minimal volume in USD of the asset on an exchange during the last 24 hours
+	MinVolume24h float64
+	// MaxStaleness is the maximal age in seconds of the last trade on an exchange at the end of a block. No limit if 0.
+	MaxStaleness int
+	// Blocklist holds the exchanges which never contribute
+	Blocklist []string
+}
+
+// override returns @r with the rules set in @o. Weights are merged and blocklists joined.
+func (r ExchangeRules) override(o ExchangeRules) ExchangeRules {
+	weights := make(map[string]float64)
+	for exchange, w := range r.Weights {
+		weights[exchange] = w
+	}
+	for exchange, w := range o.Weights {
+		weights[exchange] = w
+	}
+	r.Weights = weights
+	if o.MinVolume24h != 0 {
+		r.MinVolume24h = o.MinVolume24h
+	}
+	if o.MaxStaleness != 0 {
+		r.MaxStaleness = o.MaxStaleness
+	}
+	r.Blocklist = append(append([]string{}, r.Blocklist...), o.Blocklist...)
+	return r
+}
+
+func (r ExchangeRules) validate() error {
+	for exchange, w := range r.Weights {
+		if w < 0 {
+			return fmt.Errorf("negative weight of exchange %s", exchange)
+		}
+	}
+	if r.MinVolume24h < 0 || r.MaxStaleness < 0 {
+		return fmt.Errorf("negative exchange rule")
+	}
+	return nil
+}
+
+// ExchangesConfig configures the exchange rules of all assets.
+type ExchangesConfig struct {
+	ExchangeRules
+	// Overrides holds rules of single assets, keyed by symbol or asset identifier
+	Overrides map[string]ExchangeRules
+}
+
+// rules returns the exchange rules of @asset. Overrides of the asset identifier
+// take precedence over those of its symbol.
+func (c ExchangesConfig) rules(asset dia.Asset) ExchangeRules {
+	r := c.ExchangeRules
+	if o, ok := c.Overrides[asset.Symbol]; ok {
+		r = r.override(o)
+	}
+	if asset.HasAddress() {
+		for key, o := range c.Overrides {
+			if strings.EqualFold(key, asset.Identifier()) {
+				r = r.override(o)
+			}
+		}
+	}
+	return r
+}
+
+func (c ExchangesConfig) validate() error {
+	if err := c.ExchangeRules.validate(); err != nil {
+		return err
+	}
+	for key, o := range c.Overrides {
+		if err := o.validate(); err != nil {
+			return fmt.Errorf("%s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// blockVolume is the volume in USD of an asset on an exchange in the block ending at end.
+type blockVolume struct {
+	end    time.Time
+	volume float64
+}
+
+// exchangeActivity is the recent trading of an asset on an exchange.
+type exchangeActivity struct {
+	lastTrade time.Time
+	current   float64
+	volumes   []blockVolume
+}
+
+// add adds @t to the current block.
+func (a *exchangeActivity) add(t dia.Trade) {
+	if t.Time.After(a.lastTrade) {
+		a.lastTrade = t.Time
+	}
+	a.current += t.EstimatedUSDPrice * math.Abs(t.Volume)
+}
+
+// closeBlock ends the current block at @t and returns the volume of the last 24 hours.
+func (a *exchangeActivity) closeBlock(t time.Time) float64 {
+	a.volumes = append(a.volumes, blockVolume{end: t, volume: a.current})
+	a.current = 0
+	i := 0
+	for i < len(a.volumes) && !a.volumes[i].end.After(t.Add(-volumeWindow)) {
+		i++
+	}
+	a.volumes = a.volumes[i:]
+	var volume float64
+	for _, v := range a.volumes {
+		volume += v.volume
+	}
+	return volume
+}
+
+// exchangeWeights returns the weights of the exchanges trading @asset in @activities at the end @t of a block.
+// Excluded exchanges have the weight 0. The volume rule only applies once the blocks since @firstBlock
+// span 24 hours, so that it does not exclude all exchanges after a start.
+func exchangeWeights(rules ExchangeRules, asset dia.Asset, activities map[string]*exchangeActivity, firstBlock time.Time, t time.Time) []dia.ExchangeWeight {
+	blocked := make(map[string]bool)
+	for _, exchange := range rules.Blocklist {
+		blocked[exchange] = true
+	}
+	result := []dia.ExchangeWeight{}
+	for exchange, a := range activities {
+		volume := a.closeBlock(t)
+		weight := 1.0
+		if w, ok := rules.Weights[exchange]; ok {
+			weight = w
+		}
+		switch {
+		case blocked[exchange]:
+			weight = 0
+		case rules.MaxStaleness > 0 && t.Sub(a.lastTrade) > time.Duration(rules.MaxStaleness)*time.Second:
+			weight = 0
+		case volume < rules.MinVolume24h && t.Sub(firstBlock) >= volumeWindow:
+			weight = 0
+		}
+		result = append(result, dia.ExchangeWeight{Symbol: asset.Symbol, Asset: asset, Exchange: exchange, Weight: weight})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Exchange < result[j].Exchange })
+	return result
+}
@@ -0,0 +1,118 @@
+package filters
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestExchangeRules(t *testing.T) {
+	config, err := parseFiltersConfig([]byte(`{
+		"Filters": [{"Type": "MAIR", "Window": 120}, {"Type": "VOL", "Window": 120}],
+		"Exchanges": {"Weights": {"Binance": 3}, "MaxStaleness": 150, "Blocklist": ["Wash"],
+			"Overrides": {"ETH": {"Blocklist": ["Kraken"]}}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rules := config.Exchanges.rules(dia.Asset{Symbol: "ETH"}); len(rules.Blocklist) != 2 || rules.Weights["Binance"] != 3 {
+		t.Errorf("expected joined blocklists and inherited weights, got %v", rules)
+	}
+	s := &FiltersBlockService{
+		filters:       make(map[string][]Filter),
+		filtersConfig: config,
+		activities:    make(map[string]map[string]*exchangeActivity),
+		assets:        make(map[string]dia.Asset),
+	}
+	begin := time.Unix(1600000080, 0)
+	block := func(begin time.Time, trades ...dia.Trade) *dia.FiltersBlock {
+		return s.computeFiltersBlock(&dia.TradesBlock{TradesBlockData: dia.TradesBlockData{
+			BeginTime: begin,
+			EndTime:   begin.Add(dia.BlockSizeSeconds * time.Second),
+			Trades:    trades,
+		}})
+	}
+	trade := func(exchange string, price float64, seconds int) dia.Trade {
+		return dia.Trade{Symbol: "BTC", Source: exchange, Price: price, EstimatedUSDPrice: price, Volume: 1, Time: begin.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	fb := block(begin, trade("Binance", 100, 10), trade("Kraken", 200, 20), trade("Wash", 10000, 30))
+	// 9 seconds of 100 with weight 3 and 10 seconds of 200 with weight 1
+	if price, want := kingPrice(fb, "BTC"), (27*100+10*200)/37.0; math.Abs(price-want) > 1e-9 {
+		t.Errorf("expected weighted price %v, got %v", want, price)
+	}
+	expected := []dia.ExchangeWeight{{Exchange: "Binance", Weight: 3}, {Exchange: "Kraken", Weight: 1}, {Exchange: "Wash", Weight: 0}}
+	if len(fb.FiltersBlockData.ExchangeWeights) != len(expected) {
+		t.Fatalf("expected weights %v, got %v", expected, fb.FiltersBlockData.ExchangeWeights)
+	}
+	for i, w := range fb.FiltersBlockData.ExchangeWeights {
+		if w.Symbol != "BTC" || w.Exchange != expected[i].Exchange || w.Weight != expected[i].Weight {
+			t.Errorf("expected weight %v, got %v", expected[i], w)
+		}
+	}
+	if vol := s.filters["BTC"][1].(*FilterVOL).value; vol != 300 {
+		t.Errorf("expected volume 300 without blocked exchange, got %v", vol)
+	}
+
+	// the last trade on Binance is older than 150 seconds at the end of the next block
+	next := begin.Add(dia.BlockSizeSeconds * time.Second)
+	fb = block(next, trade("Kraken", 210, 130))
+	if price := kingPrice(fb, "BTC"); price <= 200 || price > 210 {
+		t.Errorf("expected price of Kraken, got %v", price)
+	}
+	if w := fb.FiltersBlockData.ExchangeWeights[0]; w.Exchange != "Binance" || w.Weight != 0 {
+		t.Errorf("expected stale exchange without weight, got %v", w)
+	}
+
+	// only blocked trades, no volume is aggregated
+	block(next.Add(dia.BlockSizeSeconds*time.Second), trade("Wash", 10000, 250))
+	if vol := s.filters["BTC"][1].(*FilterVOL).value; vol != 0 {
+		t.Errorf("expected volume 0 without trades on included exchanges, got %v", vol)
+	}
+}
+
+func TestExchangeRulesPoolTrades(t *testing.T) {
+	config, err := parseFiltersConfig([]byte(`{
+		"Filters": [{"Type": "MAIR", "Window": 120}, {"Type": "MEDIR", "Window": 120}],
+		"Exchanges": {"Weights": {"A": 1}}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &FiltersBlockService{
+		filters:       make(map[string][]Filter),
+		filtersConfig: config,
+		activities:    make(map[string]map[string]*exchangeActivity),
+		assets:        make(map[string]dia.Asset),
+	}
+	begin := time.Unix(1600000080, 0)
+	trade := func(exchange string, price float64, seconds int) dia.Trade {
+		return dia.Trade{Symbol: "BTC", Source: exchange, Price: price, EstimatedUSDPrice: price, Volume: 1, Time: begin.Add(time.Duration(seconds) * time.Second)}
+	}
+	fb := s.computeFiltersBlock(&dia.TradesBlock{TradesBlockData: dia.TradesBlockData{
+		BeginTime: begin,
+		EndTime:   begin.Add(dia.BlockSizeSeconds * time.Second),
+		Trades:    []dia.Trade{trade("A", 100, 10), trade("B", 101, 40), trade("C", 1000, 41), trade("B", 101, 60)},
+	}})
+
+	// the single trade of C is an outlier among the trades on all exchanges
+	if price := kingPrice(fb, "BTC"); price < 100 || price > 101 {
+		t.Errorf("expected price without the outlier of C, got %v", price)
+	}
+	for _, fp := range fb.FiltersBlockData.FilterPoints {
+		if fp.Name == "MEDIR120" && fp.Value != 101 {
+			t.Errorf("expected median 101 of the trades on all exchanges, got %v", fp.Value)
+		}
+	}
+}
+
+func kingPrice(fb *dia.FiltersBlock, symbol string) float64 {
+	for _, fp := range fb.FiltersBlockData.FilterPoints {
+		if fp.Name == dia.FilterKing && fp.Symbol == symbol {
+			return fp.Value
+		}
+	}
+	return 0
+}
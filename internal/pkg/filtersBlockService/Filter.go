@@ -1,6 +1,7 @@
 package filters
 
 import (
+	"fmt"
 	"math"
 	"sort"
 	"time"
@@ -9,12 +10,54 @@ import (
 	models "github.com/diadata-org/diadata/pkg/model"
 )
 
-// Filter defines a filter's methods processing trades from the tradesBlockService
+// Filter defines a filter's methods processing trades from the tradesBlockService.
+// Filters of other packages are plugged in with RegisterFilter.
 type Filter interface {
-	compute(trade dia.Trade)
-	finalCompute(t time.Time) float64
-	filterPointForBlock() *dia.FilterPoint
-	save(ds models.Datastore) error
+	// Compute adds a trade of the current block
+	Compute(trade dia.Trade)
+	// FinalCompute computes the value of the filter at the end @t of the block
+	FinalCompute(t time.Time) float64
+	// FilterPointForBlock returns the point published in the filters block, or nil
+	FilterPointForBlock() *dia.FilterPoint
+	// Save writes the value of the filter to @ds
+	Save(ds models.Datastore) error
+}
+
+// WeightedFilter is implemented by filters which weight the trades on all exchanges with the
+// weights of their exchanges if exchange rules are configured. Other filters on all exchanges
+// compute the trades of the exchanges with a positive weight alike.
+type WeightedFilter interface {
+	// ComputeWeighted adds a trade of the current block with the positive @weight of its exchange
+	ComputeWeighted(trade dia.Trade, weight float64)
+}
+
+// Outlier methods of the filters
+const (
+	OutliersNone = "none"
+	OutliersIQR  = "iqr"
+	OutliersMAD  = "mad"
+)
+
+var outlierMethods = map[string]func([]float64) []float64{
+	OutliersNone: func(samples []float64) []float64 { return samples },
+	OutliersIQR:  removeOutliers,
+	OutliersMAD:  removeOutliersMAD,
+}
+
+// weightedOutlierMethods remove the outliers of samples with weights, like outlierMethods.
+var weightedOutlierMethods = map[string]func([]float64, []float64) ([]float64, []float64){
+	OutliersNone: func(samples []float64, weights []float64) ([]float64, []float64) { return samples, weights },
+	OutliersIQR:  removeWeightedOutliers,
+	OutliersMAD:  removeWeightedOutliersMAD,
+}
+
+// RemoveOutliers removes the outliers of @samples using the outlier method @method.
+func RemoveOutliers(method string, samples []float64) ([]float64, error) {
+	remove, ok := outlierMethods[method]
+	if !ok {
+		return samples, fmt.Errorf("unknown outlier method %s", method)
+	}
+	return remove(samples), nil
 }
 
 // RemoveOutliers Cleans a data set it accordance to the acceptable range within interquartile range.
@@ -39,6 +82,103 @@ func removeOutliers(samples []float64) []float64 {
 	return samples[lowerIndex:upperIndex]
 }
 
+// madThreshold is the number of scaled median absolute deviations a sample may differ from the median.
+const madThreshold = 3.0
+
+// removeOutliersMAD removes the samples further than madThreshold scaled median absolute deviations from the median.
+// see: https://en.wikipedia.org/wiki/Median_absolute_deviation
+func removeOutliersMAD(samples []float64) []float64 {
+	if len(samples) < 3 {
+		return samples
+	}
+	median := computeMedian(append([]float64{}, samples...))
+	deviations := make([]float64, len(samples))
+	for i, s := range samples {
+		deviations[i] = math.Abs(s - median)
+	}
+	// 1.4826 scales the deviation to the standard deviation of normally distributed samples
+	mad := 1.4826 * computeMedian(deviations)
+	if mad == 0 {
+		return samples
+	}
+	result := []float64{}
+	for _, s := range samples {
+		if math.Abs(s-median) <= madThreshold*mad {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// removeWeightedOutliers removes the samples outside of 1.5 weighted interquartile ranges of the
+// weighted quartiles, like removeOutliers. It returns the remaining samples and their weights.
+func removeWeightedOutliers(samples []float64, weights []float64) ([]float64, []float64) {
+	if len(samples) < 2 {
+		return samples, weights
+	}
+	Q1 := weightedQuantile(samples, weights, 0.25)
+	Q3 := weightedQuantile(samples, weights, 0.75)
+	IQR := Q3 - Q1
+	return keepWeighted(samples, weights, func(s float64) bool {
+		return s >= Q1-1.5*IQR && s <= Q3+1.5*IQR
+	})
+}
+
+// removeWeightedOutliersMAD removes the samples further than madThreshold scaled weighted median absolute
+// deviations from the weighted median, like removeOutliersMAD. It returns the remaining samples and their weights.
+func removeWeightedOutliersMAD(samples []float64, weights []float64) ([]float64, []float64) {
+	if len(samples) < 3 {
+		return samples, weights
+	}
+	median := weightedQuantile(samples, weights, 0.5)
+	deviations := make([]float64, len(samples))
+	for i, s := range samples {
+		deviations[i] = math.Abs(s - median)
+	}
+	mad := 1.4826 * weightedQuantile(deviations, weights, 0.5)
+	if mad == 0 {
+		return samples, weights
+	}
+	return keepWeighted(samples, weights, func(s float64) bool {
+		return math.Abs(s-median) <= madThreshold*mad
+	})
+}
+
+// keepWeighted returns the @samples for which @keep holds and their @weights.
+func keepWeighted(samples []float64, weights []float64, keep func(float64) bool) ([]float64, []float64) {
+	resultSamples, resultWeights := []float64{}, []float64{}
+	for i, s := range samples {
+		if keep(s) {
+			resultSamples = append(resultSamples, s)
+			resultWeights = append(resultWeights, weights[i])
+		}
+	}
+	return resultSamples, resultWeights
+}
+
+// weightedQuantile returns the smallest of the @samples at which the cumulated @weights of the
+// sorted samples reach the fraction @q of their total weight. @samples are left unsorted.
+func weightedQuantile(samples []float64, weights []float64, q float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	order := make([]int, len(samples))
+	var total float64
+	for i := range samples {
+		order[i] = i
+		total += weights[i]
+	}
+	sort.Slice(order, func(i, j int) bool { return samples[order[i]] < samples[order[j]] })
+	var cumulated float64
+	for _, i := range order {
+		cumulated += weights[i]
+		if cumulated >= q*total {
+			return samples[i]
+		}
+	}
+	return samples[order[len(order)-1]]
+}
+
 // ------------ Auxilliary function for removeQoutliers -------------
 
 func computeMean(samples []float64) (mean float64) {
@@ -54,6 +194,19 @@ func computeMean(samples []float64) (mean float64) {
 	return
 }
 
+func weightedMean(samples []float64, weights []float64) (mean float64) {
+	var total, weight float64
+	for i, s := range samples {
+		total += weights[i] * s
+		weight += weights[i]
+	}
+	if weight == 0 {
+		return
+	}
+	mean = total / weight
+	return
+}
+
 func computeMedian(samples []float64) (median float64) {
 	var length = len(samples)
 	if length > 0 {
@@ -0,0 +1,247 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+)
+
+// FilterFactory returns the filter @name of @asset on @exchange, or on all exchanges
+// if @exchange is empty, for blocks starting at @beginTime.
+type FilterFactory func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter
+
+// filterType is a registered type of filters.
+type filterType struct {
+	factory         FilterFactory
+	defaultOutliers string
+}
+
+var filterTypes = make(map[string]filterType)
+
+// RegisterFilter registers the filters of type @name built by @factory. Their outlier method is
+// @defaultOutliers unless configured otherwise; filters without outlier method pass an empty string. Like RegisterExchange, it is meant to be called
+// from init functions, so that filters of other packages become available by importing them.
+func RegisterFilter(name string, defaultOutliers string, factory FilterFactory) {
+	filterTypes[name] = filterType{factory: factory, defaultOutliers: defaultOutliers}
+}
+
+// FilterParams are the parameters of a filter.
+type FilterParams struct {
+	// Window is the length of the filter's window in seconds, if it has one
+	Window int
+	// Outliers is the outlier method, one of OutliersNone, OutliersIQR and OutliersMAD.
+	// The default method of the filter type is used if empty.
+	Outliers string
+}
+
+// override returns @p with the parameters set in @o.
+func (p FilterParams) override(o FilterParams) FilterParams {
+	if o.Window != 0 {
+		p.Window = o.Window
+	}
+	if o.Outliers != "" {
+		p.Outliers = o.Outliers
+	}
+	return p
+}
+
+// FilterConfig configures a filter computed for all assets.
+type FilterConfig struct {
+	// Type is the name the filter is registered with, e.g. MAIR
+	Type string
+	FilterParams
+	// Overrides holds parameters of single assets, keyed by symbol or asset identifier
+	Overrides map[string]FilterParams
+}
+
+// params returns the parameters of the filter for @asset. Overrides of the asset
+// identifier take precedence over those of its symbol.
+func (c FilterConfig) params(asset dia.Asset) FilterParams {
+	p := c.FilterParams
+	if o, ok := c.Overrides[asset.Symbol]; ok {
+		p = p.override(o)
+	}
+	if asset.HasAddress() {
+		// identifiers hold lower case addresses
+		for key, o := range c.Overrides {
+			if strings.EqualFold(key, asset.Identifier()) {
+				p = p.override(o)
+			}
+		}
+	}
+	return c.withDefaultOutliers(p)
+}
+
+// withDefaultOutliers returns @p with the default outlier method of the filter type if it has none.
+func (c FilterConfig) withDefaultOutliers(p FilterParams) FilterParams {
+	if p.Outliers == "" {
+		p.Outliers = filterTypes[c.Type].defaultOutliers
+	}
+	return p
+}
+
+// filterPointName returns the name of the filter with the parameters @p, e.g. MAIR120.
+// The outlier method is appended if it differs from the default of the type, as in MEDIR120_MAD.
+func (c FilterConfig) filterPointName(p FilterParams) string {
+	name := c.Type
+	if p.Window > 0 {
+		name += strconv.Itoa(p.Window)
+	}
+	if p.Outliers != filterTypes[c.Type].defaultOutliers {
+		name += "_" + strings.ToUpper(p.Outliers)
+	}
+	return name
+}
+
+func (c FilterConfig) validate() error {
+	if _, ok := filterTypes[c.Type]; !ok {
+		return fmt.Errorf("unknown filter type %s", c.Type)
+	}
+	params := []FilterParams{c.FilterParams}
+	king := c.filterPointName(c.params(dia.Asset{})) == dia.FilterKing
+	for key, p := range c.Overrides {
+		params = append(params, p)
+		// an asset whose king filter is renamed would drop out of the filters block
+		if o := c.FilterParams.override(p); king && c.filterPointName(c.withDefaultOutliers(o)) != dia.FilterKing {
+			return fmt.Errorf("override of %s changes the parameters of the king filter %s", key, dia.FilterKing)
+		}
+	}
+	for _, p := range params {
+		if p.Window < 0 {
+			return fmt.Errorf("negative window of filter %s", c.Type)
+		}
+		if p.Outliers == "" {
+			continue
+		}
+		if _, ok := outlierMethods[p.Outliers]; !ok {
+			return fmt.Errorf("unknown outlier method %s of filter %s", p.Outliers, c.Type)
+		}
+		if filterTypes[c.Type].defaultOutliers == "" {
+			return fmt.Errorf("filter %s has no outlier method", c.Type)
+		}
+	}
+	return nil
+}
+
+// FiltersConfig lists the filters computed by the FiltersBlockService. It is read from
+// config/filters.json, or config/<name>.json for other deployments.
+type FiltersConfig struct {
+	Filters []FilterConfig
+	// Exchanges holds the rules weighting the exchanges in the filters on all exchanges.
+	// All exchanges contribute their trades if nil.
+	Exchanges *ExchangesConfig
+}
+
+// DefaultFiltersConfig returns the filters computed if no configuration is given.
+func DefaultFiltersConfig() FiltersConfig {
+	return FiltersConfig{Filters: []FilterConfig{
+		// Prices are written into redis in MA filter
+		{Type: "MA", FilterParams: FilterParams{Window: dia.BlockSizeSeconds}},
+		{Type: "TLT"},
+		{Type: "VOL", FilterParams: FilterParams{Window: dia.BlockSizeSeconds}},
+		{Type: "MAIR", FilterParams: FilterParams{Window: dia.BlockSizeSeconds}},
+		{Type: "MEDIR", FilterParams: FilterParams{Window: dia.BlockSizeSeconds}},
+	}}
+}
+
+// LoadFiltersConfig reads the configuration of the filters from config/<name>.json.
+func LoadFiltersConfig(name string) (config FiltersConfig, err error) {
+	data, err := ioutil.ReadFile(configCollectors.ConfigFileConnectors(name, ".json"))
+	if err != nil {
+		return
+	}
+	return parseFiltersConfig(data)
+}
+
+func parseFiltersConfig(data []byte) (config FiltersConfig, err error) {
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return
+	}
+	if len(config.Filters) == 0 {
+		err = fmt.Errorf("no filters configured")
+		return
+	}
+	if config.Exchanges != nil {
+		if err = config.Exchanges.validate(); err != nil {
+			return
+		}
+	}
+	for _, c := range config.Filters {
+		if err = c.validate(); err != nil {
+			return
+		}
+	}
+	// overrides can give two filters the same name for single assets
+	for _, asset := range overriddenAssets(config.Filters) {
+		names := make(map[string]bool)
+		for _, c := range config.Filters {
+			name := c.filterPointName(c.params(asset))
+			if names[name] {
+				err = fmt.Errorf("filter %s configured twice", name)
+				if asset != (dia.Asset{}) {
+					err = fmt.Errorf("filter %s configured twice for %s", name, asset.Identifier())
+				}
+				return
+			}
+			names[name] = true
+		}
+	}
+	return
+}
+
+// overriddenAssets returns assets covering the overrides of @filters: an asset without overrides,
+// one per symbol and per identifier, and one per symbol with each identifier, as an asset gets the
+// overrides of both its symbol and its identifier.
+func overriddenAssets(filters []FilterConfig) []dia.Asset {
+	symbols, identifiers := make(map[string]bool), make(map[string]bool)
+	for _, c := range filters {
+		for key := range c.Overrides {
+			if strings.Contains(key, "-") {
+				identifiers[key] = true
+			} else {
+				symbols[key] = true
+			}
+		}
+	}
+	assets := []dia.Asset{{}}
+	for _, symbol := range sortedKeys(symbols) {
+		assets = append(assets, dia.Asset{Symbol: symbol})
+	}
+	for _, identifier := range sortedKeys(identifiers) {
+		i := strings.LastIndex(identifier, "-")
+		asset := dia.Asset{Blockchain: identifier[:i], Address: identifier[i+1:]}
+		assets = append(assets, asset)
+		for _, symbol := range sortedKeys(symbols) {
+			asset.Symbol = symbol
+			assets = append(assets, asset)
+		}
+	}
+	return assets
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// newFilters returns the configured filters of @asset on @exchange.
+func (c FiltersConfig) newFilters(asset dia.Asset, exchange string, beginTime time.Time) []Filter {
+	var result []Filter
+	for _, fc := range c.Filters {
+		p := fc.params(asset)
+		result = append(result, filterTypes[fc.Type].factory(fc.filterPointName(p), asset, exchange, beginTime, p))
+	}
+	return result
+}
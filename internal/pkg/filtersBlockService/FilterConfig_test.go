@@ -0,0 +1,78 @@
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestParseFiltersConfig(t *testing.T) {
+	config, err := parseFiltersConfig([]byte(`{"Filters": [
+		{"Type": "MAIR", "Window": 300, "Overrides": {"BTC": {"Window": 60}, "Ethereum-0xdAC17F958D2ee523a2206206994597C13D831ec7": {"Outliers": "mad"}}},
+		{"Type": "MEDIR", "Window": 300, "Outliers": "mad"},
+		{"Type": "TLT"}
+	]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	usdt := dia.Asset{Symbol: "USDT", Blockchain: dia.Ethereum, Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7"}
+	expected := map[string][]string{
+		"ETH":  {"MAIR300", "MEDIR300_MAD"},
+		"BTC":  {"MAIR60", "MEDIR300_MAD"},
+		"USDT": {"MAIR300_MAD", "MEDIR300_MAD"},
+	}
+	for symbol, names := range expected {
+		asset := dia.Asset{Symbol: symbol}
+		if symbol == usdt.Symbol {
+			asset = usdt
+		}
+		for i, name := range names {
+			c := config.Filters[i]
+			if filterName := c.filterPointName(c.params(asset)); filterName != name {
+				t.Errorf("expected filter %s of %s, got %s", name, symbol, filterName)
+			}
+		}
+		filters := config.newFilters(asset, "", time.Now())
+		if len(filters) != 3 {
+			t.Fatalf("expected 3 filters of %s, got %d", symbol, len(filters))
+		}
+		if mair := filters[0].(*FilterMAIR); mair.filterName != names[0] {
+			t.Errorf("expected filter %s of %s, got %s", names[0], symbol, mair.filterName)
+		}
+	}
+
+	// overrides keeping the parameters of the king filter are allowed
+	if _, err := parseFiltersConfig([]byte(`{"Filters": [{"Type": "MAIR", "Window": 120, "Overrides": {"BTC": {"Outliers": "iqr"}}}]}`)); err != nil {
+		t.Error(err)
+	}
+
+	for _, invalid := range []string{
+		`{"Filters": []}`,
+		`{"Filters": [{"Type": "XYZ"}]}`,
+		`{"Filters": [{"Type": "MAIR", "Outliers": "xyz"}]}`,
+		`{"Filters": [{"Type": "TLT", "Outliers": "iqr"}]}`,
+		`{"Filters": [{"Type": "MAIR", "Window": 120}, {"Type": "MAIR", "Window": 120, "Outliers": "iqr"}]}`,
+		`{"Filters": [{"Type": "MAIR", "Window": 120, "Overrides": {"BTC": {"Window": 300}}}]}`,
+		`{"Filters": [{"Type": "MAIR", "Window": 120, "Overrides": {"BTC": {"Outliers": "mad"}}}]}`,
+		// the names of both filters are MAIR300 for BTC
+		`{"Filters": [{"Type": "MAIR", "Window": 60, "Overrides": {"BTC": {"Window": 300}}}, {"Type": "MAIR", "Window": 300}]}`,
+		// for the asset with this symbol and address
+		`{"Filters": [{"Type": "MAIR", "Window": 60, "Overrides": {"USDT": {"Window": 300}}}, {"Type": "MAIR", "Window": 180, "Overrides": {"Ethereum-0xdac17f958d2ee523a2206206994597c13d831ec7": {"Window": 300}}}]}`,
+	} {
+		if _, err := parseFiltersConfig([]byte(invalid)); err == nil {
+			t.Errorf("expected error for %s", invalid)
+		}
+	}
+}
+
+func TestRemoveOutliersMAD(t *testing.T) {
+	samples := []float64{10, 10.1, 9.9, 10.2, 9.8, 10, 25}
+	clean := removeOutliersMAD(samples)
+	if len(clean) != 6 {
+		t.Errorf("expected the outlier 25 to be removed, got %v", clean)
+	}
+	if samples[len(samples)-1] != 25 {
+		t.Error("samples must not be reordered")
+	}
+}
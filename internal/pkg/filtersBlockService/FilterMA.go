@@ -10,7 +10,7 @@ import (
 )
 
 type FilterMA struct {
-	symbol         string
+	asset          dia.Asset
 	exchange       string
 	currentTime    time.Time
 	previousPrices []float64
@@ -19,46 +19,59 @@ type FilterMA struct {
 	value          float64
 	modified       bool
 	filterName     string
+	removeOutliers func([]float64) []float64
 }
 
-func NewFilterMA(symbol string, exchange string, currentTime time.Time, param int) *FilterMA {
+func init() {
+	RegisterFilter("MA", OutliersNone, func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		s := NewFilterMA(asset, exchange, beginTime, params.Window)
+		s.filterName = name
+		s.removeOutliers = outlierMethods[params.Outliers]
+		return s
+	})
+}
+
+func NewFilterMA(asset dia.Asset, exchange string, currentTime time.Time, param int) *FilterMA {
 	s := &FilterMA{
-		symbol:         symbol,
+		asset:          asset,
 		exchange:       exchange,
 		previousPrices: []float64{},
 		currentTime:    currentTime,
 		param:          param,
 		filterName:     "MA" + strconv.Itoa(param),
+		removeOutliers: outlierMethods[OutliersNone],
 	}
 	return s
 }
-func (s *FilterMA) finalCompute(t time.Time) float64 {
+func (s *FilterMA) FinalCompute(t time.Time) float64 {
 	if s.lastTrade == nil {
 		return 0.0
 	} else {
 		s.fill(t, s.lastTrade.EstimatedUSDPrice)
 	}
 
+	prices := s.removeOutliers(append([]float64{}, s.previousPrices...))
 	var total float64 = 0
-	for _, v := range s.previousPrices {
+	for _, v := range prices {
 		total += v
 	}
 	div := s.param
-	if len(s.previousPrices) > 0 && len(s.previousPrices) < s.param {
-		div = len(s.previousPrices)
+	if len(prices) > 0 && len(prices) < s.param {
+		div = len(prices)
 	}
 	s.value = total / float64(div)
 	return s.value
 }
 
-func (s *FilterMA) filterPointForBlock() *dia.FilterPoint {
+func (s *FilterMA) FilterPointForBlock() *dia.FilterPoint {
 	if s.exchange != "" || s.filterName != dia.FilterKing {
 		return nil
 	} else {
 		return &dia.FilterPoint{
-			Symbol: s.symbol,
+			Symbol: s.asset.Symbol,
+			Asset:  s.asset,
 			Value:  s.value,
-			Name:   "MA" + strconv.Itoa(s.param),
+			Name:   s.filterName,
 			Time:   s.currentTime,
 		}
 	}
@@ -86,7 +99,7 @@ func (s *FilterMA) fill(t time.Time, price float64) {
 	s.currentTime = t
 }
 
-func (s *FilterMA) compute(trade dia.Trade) {
+func (s *FilterMA) Compute(trade dia.Trade) {
 	s.modified = true
 	if s.lastTrade != nil {
 		if trade.Time.Before(s.currentTime) {
@@ -100,11 +113,11 @@ func (s *FilterMA) compute(trade dia.Trade) {
 	s.lastTrade = &trade
 }
 
-func (s *FilterMA) save(ds models.Datastore) error {
-	log.Infof("save called on symbol %s on exchange %s", s.symbol, s.exchange)
+func (s *FilterMA) Save(ds models.Datastore) error {
+	log.Infof("save called on symbol %s on exchange %s", s.asset.Identifier(), s.exchange)
 	if s.modified {
 		s.modified = false
-		err := ds.SetFilter(s.filterName, s.symbol, s.exchange, s.value, s.currentTime)
+		err := ds.SetFilter(s.filterName, s.asset.Identifier(), s.exchange, s.value, s.currentTime)
 		if err != nil {
 			log.Errorln("FilterMA: Error:", err)
 		}
@@ -13,64 +13,92 @@ import (
 
 // FilterMAIR contains the configuration parameters of the filter
 type FilterMAIR struct {
-	symbol         string
+	asset          dia.Asset
 	exchange       string
 	currentTime    time.Time
 	previousPrices []float64
-	lastTrade      *dia.Trade
-	memory         int
-	value          float64
-	filterName     string
-	modified       bool
+	// weights of previousPrices, set if the filter computes weighted trades
+	previousWeights        []float64
+	weighted               bool
+	lastTrade              *dia.Trade
+	lastWeight             float64
+	memory                 int
+	value                  float64
+	filterName             string
+	modified               bool
+	removeOutliers         func([]float64) []float64
+	removeWeightedOutliers func([]float64, []float64) ([]float64, []float64)
+}
+
+func init() {
+	RegisterFilter("MAIR", OutliersIQR, func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		s := NewFilterMAIR(asset, exchange, beginTime, params.Window)
+		s.filterName = name
+		s.removeOutliers = outlierMethods[params.Outliers]
+		s.removeWeightedOutliers = weightedOutlierMethods[params.Outliers]
+		return s
+	})
 }
 
 //NewFilterMAIR creates a FilterMAIR
-func NewFilterMAIR(symbol string, exchange string, currentTime time.Time, memory int) *FilterMAIR {
+func NewFilterMAIR(asset dia.Asset, exchange string, currentTime time.Time, memory int) *FilterMAIR {
 	s := &FilterMAIR{
-		symbol:         symbol,
+		asset:          asset,
 		exchange:       exchange,
 		previousPrices: []float64{},
 		currentTime:    currentTime,
 		memory:         memory,
 		filterName:     "MAIR" + strconv.Itoa(memory),
+		removeOutliers: removeOutliers,
+		// weighted IQR
+		removeWeightedOutliers: removeWeightedOutliers,
 	}
 	return s
 }
 
-func (s *FilterMAIR) processDataPoint(price float64) {
+func (s *FilterMAIR) processDataPoint(price float64, weight float64) {
 	/// first remove extra value from buffer if already full
 	if len(s.previousPrices) >= s.memory {
 		s.previousPrices = s.previousPrices[0 : s.memory-1]
+		s.previousWeights = s.previousWeights[0 : s.memory-1]
 	}
 	s.previousPrices = append([]float64{price}, s.previousPrices...)
+	s.previousWeights = append([]float64{weight}, s.previousWeights...)
 }
-func (s *FilterMAIR) finalCompute(t time.Time) float64 {
+func (s *FilterMAIR) FinalCompute(t time.Time) float64 {
 	if s.lastTrade == nil {
 		return 0.0
 	}
 	// Add the last trade again to compensate for the delay since measurement to EOB
 	// adopted behaviour from FilterMA
-	s.processDataPoint(s.lastTrade.EstimatedUSDPrice)
-	cleanPrices := removeOutliers(s.previousPrices)
+	s.processDataPoint(s.lastTrade.EstimatedUSDPrice, s.lastWeight)
+	if s.weighted {
+		cleanPrices, weights := s.removeWeightedOutliers(s.previousPrices, s.previousWeights)
+		s.value = weightedMean(cleanPrices, weights)
+		return s.value
+	}
+	cleanPrices := s.removeOutliers(s.previousPrices)
 	s.value = computeMean(cleanPrices)
 	return s.value
 }
-func (s *FilterMAIR) filterPointForBlock() *dia.FilterPoint {
+
+func (s *FilterMAIR) FilterPointForBlock() *dia.FilterPoint {
 	if s.exchange != "" || s.filterName != dia.FilterKing {
 		return nil
 	}
 	return &dia.FilterPoint{
-		Symbol: s.symbol,
+		Symbol: s.asset.Symbol,
+		Asset:  s.asset,
 		Value:  s.value,
 		Name:   s.filterName,
 		Time:   s.currentTime,
 	}
 }
-func (s *FilterMAIR) fill(t time.Time, price float64) {
+func (s *FilterMAIR) fill(t time.Time, price float64, weight float64) {
 	diff := int(t.Sub(s.currentTime).Seconds())
 	if diff > 1 {
 		for diff > 1 {
-			s.processDataPoint(price)
+			s.processDataPoint(price, weight)
 			diff--
 		}
 	} else {
@@ -78,13 +106,25 @@ func (s *FilterMAIR) fill(t time.Time, price float64) {
 			if len(s.previousPrices) >= 1 {
 				/// Remove latest data point and update with newer
 				s.previousPrices = s.previousPrices[1:]
+				s.previousWeights = s.previousWeights[1:]
 			}
 		}
-		s.processDataPoint(price)
+		s.processDataPoint(price, weight)
 	}
 	s.currentTime = t
 }
-func (s *FilterMAIR) compute(trade dia.Trade) {
+func (s *FilterMAIR) Compute(trade dia.Trade) {
+	s.compute(trade, 1)
+}
+
+// ComputeWeighted adds @trade with the @weight of its exchange. The value is the weighted mean
+// of the prices within the weighted interquartile range.
+func (s *FilterMAIR) ComputeWeighted(trade dia.Trade, weight float64) {
+	s.weighted = true
+	s.compute(trade, weight)
+}
+
+func (s *FilterMAIR) compute(trade dia.Trade, weight float64) {
 	s.modified = true
 	if s.lastTrade != nil {
 		if trade.Time.Before(s.currentTime) {
@@ -92,19 +132,28 @@ func (s *FilterMAIR) compute(trade dia.Trade) {
 			return
 		}
 	}
-	s.fill(trade.Time, trade.EstimatedUSDPrice)
+	s.fill(trade.Time, trade.EstimatedUSDPrice, weight)
 	s.lastTrade = &trade
+	s.lastWeight = weight
 }
 
-func (s *FilterMAIR) save(ds models.Datastore) error {
+func (s *FilterMAIR) Save(ds models.Datastore) error {
 	if s.modified {
 		s.modified = false
-		err := ds.SetPriceZSET(s.symbol, s.exchange, s.value, s.currentTime)
+		if s.filterName != dia.FilterKing {
+			// only the king filter sets the price of an asset
+			err := ds.SetFilter(s.filterName, s.asset.Identifier(), s.exchange, s.value, s.currentTime)
+			if err != nil {
+				log.Errorln("FilterMAIR: Error:", err)
+			}
+			return err
+		}
+		err := ds.SetPriceZSET(s.asset.Identifier(), s.exchange, s.value, s.currentTime)
 		if err != nil {
 			log.Errorln("FilterMAIR: Error:", err)
 		}
 		if s.exchange == "" {
-			err = ds.SetPriceUSD(s.symbol, s.value)
+			err = ds.SetAssetPriceUSD(s.asset, s.value)
 			if err != nil {
 				log.Errorln("FilterMA: Error:", err)
 			}
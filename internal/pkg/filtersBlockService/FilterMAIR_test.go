@@ -91,15 +91,15 @@ func TestFilterMAIRIgnore(t *testing.T) {
 	firstPrice := 50.0
 	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
 	steps := filterParam
-	f := NewFilterMAIR("XRP", "", d, filterParam)
+	f := NewFilterMAIR(dia.Asset{Symbol: "XRP"}, "", d, filterParam)
 	p := firstPrice
 	priceIncrements := 1.0
 	for i := 0; i <= steps; i++ {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		d = d.Add(-time.Second)
 		p += priceIncrements
 	}
-	v := f.finalCompute(d)
+	v := f.FinalCompute(d)
 	if v != firstPrice {
 		t.Errorf("error should be initial value:%f got:%f", firstPrice, v)
 	}
@@ -109,12 +109,12 @@ func TestFilterMAIRAverage(t *testing.T) {
 	firstPrice := 50.0
 	avg := 0.
 	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-	f := NewFilterMAIR("XRP", "", d, filterParam)
+	f := NewFilterMAIR(dia.Asset{Symbol: "XRP"}, "", d, filterParam)
 	p := firstPrice
 	priceIncrements := 1.0
 	samples := 15
 	for i := 0; i < samples; i++ {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		d = d.Add(time.Second)
 		avg += p
 		p += priceIncrements
@@ -122,7 +122,7 @@ func TestFilterMAIRAverage(t *testing.T) {
 	// append last value twice. Same as filter
 	avg += p - priceIncrements
 	avg = avg / float64(samples+1)
-	v := f.finalCompute(d)
+	v := f.FinalCompute(d)
 	if v != avg {
 		t.Errorf("error should be average value:%f got:%f", avg, v)
 	}
@@ -132,12 +132,12 @@ func TestFilterMAIRAverageOutsideRange(t *testing.T) {
 	firstPrice := 50.0
 	avg := 0.
 	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-	f := NewFilterMAIR("XRP", "", d, memory)
+	f := NewFilterMAIR(dia.Asset{Symbol: "XRP"}, "", d, memory)
 	p := firstPrice
 	priceIncrements := 1.0
 	samples := 15
 	for i := 0; i < samples; i++ {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		d = d.Add(time.Second)
 		if samples-i <= memory {
 			avg += p
@@ -146,7 +146,7 @@ func TestFilterMAIRAverageOutsideRange(t *testing.T) {
 	}
 	// append last value twice. Same as filter
 	avg = (avg + priceIncrements*float64(memory-1)) / float64(memory)
-	v := f.finalCompute(d)
+	v := f.FinalCompute(d)
 	if v != avg {
 		t.Errorf("error should be average value:%f got:%f", avg, v)
 	}
@@ -169,12 +169,12 @@ func TestFilterMAIRAverageCleanOutliers(t *testing.T) {
 	for i, c := range cases {
 		memory := 20
 		d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-		f := NewFilterMAIR("XRP", "", d, memory)
+		f := NewFilterMAIR(dia.Asset{Symbol: "XRP"}, "", d, memory)
 		for _, p := range c.samples {
-			f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+			f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 			d = d.Add(time.Second)
 		}
-		v := f.finalCompute(d)
+		v := f.FinalCompute(d)
 		if math.Abs(float64(v-c.mean)) > 1e-4 {
 			t.Errorf("Mean was incorrect, got: %f, expected: %f for set:%d", v, c.mean, i)
 		}
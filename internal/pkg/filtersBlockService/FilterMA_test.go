@@ -12,17 +12,17 @@ func TestFilterMa(t *testing.T) {
 	firstPrice := 50.0
 
 	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-	f := NewFilterMA("XRP", "", d, filterParam)
+	f := NewFilterMA(dia.Asset{Symbol: "XRP"}, "", d, filterParam)
 	steps := filterParam
 	p := firstPrice
 	i := 0
 	for i <= steps {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		d = d.Add(time.Second)
 		i += 1
 	}
-	f.finalCompute(d)
-	v := f.filterPointForBlock()
+	f.FinalCompute(d)
+	v := f.FilterPointForBlock()
 	if v.Value != p {
 		t.Errorf("error should be stable %v", v)
 	}
@@ -30,13 +30,13 @@ func TestFilterMa(t *testing.T) {
 	priceIncrements := 1.0
 	i = 0
 	for i <= steps {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		p = p + priceIncrements
 		d = d.Add(time.Second)
 		i += 1
 	}
-	f.finalCompute(d)
-	v = f.filterPointForBlock()
+	f.FinalCompute(d)
+	v = f.FilterPointForBlock()
 	if v.Value != 53.25 { //TODO formulas
 		t.Errorf("error should be, %v", v)
 	}
@@ -48,17 +48,17 @@ func TestFilterMa2(t *testing.T) {
 	firstPrice := 50.0
 
 	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-	f := NewFilterMA("XRP", "", d, filterParam)
+	f := NewFilterMA(dia.Asset{Symbol: "XRP"}, "", d, filterParam)
 	steps := filterParam
 	p := firstPrice
 	i := 0
 	for i <= steps {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		d = d.Add(time.Second)
 		d = d.Add(time.Second)
 		i += 1
 	}
-	v := f.finalCompute(d)
+	v := f.FinalCompute(d)
 	if v != p {
 		t.Errorf("error should be stable %v", v)
 	}
@@ -66,13 +66,13 @@ func TestFilterMa2(t *testing.T) {
 	priceIncrements := 1.0
 	i = 0
 	for i <= steps {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		p = p + priceIncrements
 		d = d.Add(time.Second)
 		d = d.Add(time.Second)
 		i += 1
 	}
-	v = f.finalCompute(d)
+	v = f.FinalCompute(d)
 	if v != 56.4 { //TODO formulas
 		t.Errorf("error shouldnt be 57.0 %v", v)
 	}
@@ -85,16 +85,16 @@ func TestFilterMaIgnore(t *testing.T) {
 	firstPrice := 50.0
 
 	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-	f := NewFilterMA("XRP", "", d, filterParam)
+	f := NewFilterMA(dia.Asset{Symbol: "XRP"}, "", d, filterParam)
 	steps := filterParam
 	p := firstPrice
 	priceIncrements := 1.0
 	for i := 0; i <= steps; i++ {
-		f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 		d = d.Add(-time.Second)
 		p += priceIncrements
 	}
-	v := f.finalCompute(d)
+	v := f.FinalCompute(d)
 	if v != firstPrice {
 		t.Errorf("error should be initial value:%f got:%f", firstPrice, v)
 	}
@@ -12,59 +12,98 @@ import (
 
 // FilterMEDIR contains the configuration parameters of the filter
 type FilterMEDIR struct {
-	symbol         string
+	asset          dia.Asset
 	exchange       string
 	currentTime    time.Time
 	previousPrices []float64
-	lastTrade      *dia.Trade
-	memory         int
-	value          float64
-	filterName     string
-	modified       bool
+	// weights of previousPrices, set if the filter computes weighted trades
+	previousWeights        []float64
+	weighted               bool
+	lastTrade              *dia.Trade
+	memory                 int
+	value                  float64
+	filterName             string
+	modified               bool
+	removeOutliers         func([]float64) []float64
+	removeWeightedOutliers func([]float64, []float64) ([]float64, []float64)
+}
+
+func init() {
+	RegisterFilter("MEDIR", OutliersIQR, func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		s := NewFilterMEDIR(asset, exchange, beginTime, params.Window)
+		s.filterName = name
+		s.removeOutliers = outlierMethods[params.Outliers]
+		s.removeWeightedOutliers = weightedOutlierMethods[params.Outliers]
+		return s
+	})
 }
 
 //NewFilterMEDIR creates a FilterMEDIR
-func NewFilterMEDIR(symbol string, exchange string, currentTime time.Time, memory int) *FilterMEDIR {
+func NewFilterMEDIR(asset dia.Asset, exchange string, currentTime time.Time, memory int) *FilterMEDIR {
 	s := &FilterMEDIR{
-		symbol:         symbol,
+		asset:          asset,
 		exchange:       exchange,
 		previousPrices: []float64{},
 		currentTime:    currentTime,
 		memory:         memory,
 		filterName:     "MEDIR" + strconv.Itoa(memory),
+		removeOutliers: removeOutliers,
+		// weighted IQR
+		removeWeightedOutliers: removeWeightedOutliers,
 	}
 	return s
 }
 
-func (s *FilterMEDIR) processDataPoint(price float64) {
+func (s *FilterMEDIR) processDataPoint(price float64, weight float64) {
 	/// first remove extra value from buffer if already full
 	if len(s.previousPrices) >= s.memory {
 		s.previousPrices = s.previousPrices[0 : s.memory-1]
+		s.previousWeights = s.previousWeights[0 : s.memory-1]
 	}
 	s.previousPrices = append([]float64{price}, s.previousPrices...)
+	s.previousWeights = append([]float64{weight}, s.previousWeights...)
 }
-func (s *FilterMEDIR) finalCompute(t time.Time) float64 {
+func (s *FilterMEDIR) FinalCompute(t time.Time) float64 {
 	if s.lastTrade == nil {
 		return 0.0
 	}
-	cleanPrices := removeOutliers(s.previousPrices)
-	s.value = computeMedian(cleanPrices)
+	if s.weighted {
+		cleanPrices, weights := s.removeWeightedOutliers(s.previousPrices, s.previousWeights)
+		s.value = weightedQuantile(cleanPrices, weights, 0.5)
+	} else {
+		cleanPrices := s.removeOutliers(s.previousPrices)
+		s.value = computeMedian(cleanPrices)
+	}
 	s.previousPrices = []float64{}
+	s.previousWeights = []float64{}
 	return s.value
 }
-func (s *FilterMEDIR) filterPointForBlock() *dia.FilterPoint {
+
+func (s *FilterMEDIR) FilterPointForBlock() *dia.FilterPoint {
 	if s.exchange != "" || s.filterName != dia.FilterKing {
 		return nil
 	}
 	return &dia.FilterPoint{
-		Symbol: s.symbol,
+		Symbol: s.asset.Symbol,
+		Asset:  s.asset,
 		Value:  s.value,
 		Name:   s.filterName,
 		Time:   s.currentTime,
 	}
 }
 
-func (s *FilterMEDIR) compute(trade dia.Trade) {
+func (s *FilterMEDIR) Compute(trade dia.Trade) {
+	s.compute(trade, 1)
+}
+
+// ComputeWeighted adds @trade with the @weight of its exchange. The value is the weighted median
+// of the prices within the weighted interquartile range.
+func (s *FilterMEDIR) ComputeWeighted(trade dia.Trade, weight float64) {
+	s.weighted = true
+	s.compute(trade, weight)
+}
+
+func (s *FilterMEDIR) compute(trade dia.Trade, weight float64) {
 	s.modified = true
 	if s.lastTrade != nil {
 		if trade.Time.Before(s.currentTime) {
@@ -72,15 +111,15 @@ func (s *FilterMEDIR) compute(trade dia.Trade) {
 			return
 		}
 	}
-	s.processDataPoint(trade.EstimatedUSDPrice)
+	s.processDataPoint(trade.EstimatedUSDPrice, weight)
 	s.currentTime = trade.Time
 	s.lastTrade = &trade
 }
 
-func (s *FilterMEDIR) save(ds models.Datastore) error {
+func (s *FilterMEDIR) Save(ds models.Datastore) error {
 	if s.modified {
 		s.modified = false
-		err := ds.SetFilter(s.filterName, s.symbol, s.exchange, s.value, s.currentTime)
+		err := ds.SetFilter(s.filterName, s.asset.Identifier(), s.exchange, s.value, s.currentTime)
 		if err != nil {
 			log.Errorln("FilterMAIR: Error:", err)
 		}
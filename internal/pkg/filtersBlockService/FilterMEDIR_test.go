@@ -49,12 +49,12 @@ func TestFilterMEDIRMedianCleanOutliers(t *testing.T) {
 	for i, c := range cases {
 		memory := 20
 		d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
-		f := NewFilterMEDIR("XRP", "", d, memory)
+		f := NewFilterMEDIR(dia.Asset{Symbol: "XRP"}, "", d, memory)
 		for _, p := range c.samples {
-			f.compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
+			f.Compute(dia.Trade{EstimatedUSDPrice: p, Time: d})
 			d = d.Add(time.Second)
 		}
-		v := f.finalCompute(d)
+		v := f.FinalCompute(d)
 		if math.Abs(float64(v-c.mean)) > 1e-4 {
 			t.Errorf("Median was incorrect, got: %f, expected: %f for set:%d", v, c.mean, i)
 		}
@@ -10,35 +10,41 @@ import (
 )
 
 type FilterTLT struct {
-	symbol        string
+	asset         dia.Asset
 	exchange      string
 	lastTradeTime time.Time
 }
 
-func NewFilterTLT(symbol string, exchange string) *FilterTLT {
+func init() {
+	RegisterFilter("TLT", "", func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		return NewFilterTLT(asset, exchange)
+	})
+}
+
+func NewFilterTLT(asset dia.Asset, exchange string) *FilterTLT {
 	s := &FilterTLT{
-		symbol:   symbol,
+		asset:    asset,
 		exchange: exchange,
 	}
 	return s
 }
 
-func (s *FilterTLT) filterPointForBlock() *dia.FilterPoint {
+func (s *FilterTLT) FilterPointForBlock() *dia.FilterPoint {
 	return nil
 }
 
-func (s *FilterTLT) compute(trade dia.Trade) {
+func (s *FilterTLT) Compute(trade dia.Trade) {
 	s.lastTradeTime = trade.Time
 }
 
-func (s *FilterTLT) save(ds models.Datastore) error {
-	err := ds.SetLastTradeTimeForExchange(s.symbol, s.exchange, s.lastTradeTime)
+func (s *FilterTLT) Save(ds models.Datastore) error {
+	err := ds.SetLastTradeTimeForExchange(s.asset.Identifier(), s.exchange, s.lastTradeTime)
 	if err != nil {
 		log.Errorln("FilterTLT Error:", err)
 	}
 	return err
 }
 
-func (s *FilterTLT) finalCompute(time time.Time) float64 {
+func (s *FilterTLT) FinalCompute(time time.Time) float64 {
 	return 0.0
 }
@@ -0,0 +1,105 @@
+// FilterTWAP implements a time weighted average price over a sliding window. Each price
+// is weighted by the time until the next trade.
+// see: https://en.wikipedia.org/wiki/Time-weighted_average_price
+package filters
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// FilterTWAP contains the configuration parameters of the filter
+type FilterTWAP struct {
+	asset          dia.Asset
+	exchange       string
+	currentTime    time.Time
+	window         priceWindow
+	value          float64
+	filterName     string
+	modified       bool
+	removeOutliers func([]float64) []float64
+}
+
+func init() {
+	RegisterFilter("TWAP", OutliersNone, func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		s := NewFilterTWAP(asset, exchange, beginTime, params.Window)
+		s.filterName = name
+		s.removeOutliers = outlierMethods[params.Outliers]
+		return s
+	})
+}
+
+// NewFilterTWAP creates a FilterTWAP over a window of @window seconds
+func NewFilterTWAP(asset dia.Asset, exchange string, currentTime time.Time, window int) *FilterTWAP {
+	s := &FilterTWAP{
+		asset:          asset,
+		exchange:       exchange,
+		currentTime:    currentTime,
+		window:         newPriceWindow(window),
+		filterName:     "TWAP" + strconv.Itoa(window),
+		removeOutliers: outlierMethods[OutliersNone],
+	}
+	return s
+}
+
+func (s *FilterTWAP) Compute(trade dia.Trade) {
+	if trade.Time.Before(s.window.last()) {
+		log.Errorln("FilterTWAP: Ignoring Trade out of order ", s.window.last(), trade.Time)
+		return
+	}
+	s.window.add(trade)
+	s.modified = true
+}
+
+// FinalCompute returns the time weighted average of the prices in the window ending at @t.
+// The last price before the window counts from the start of the window.
+func (s *FilterTWAP) FinalCompute(t time.Time) float64 {
+	s.window.trim(t)
+	buckets := cleanBuckets(s.window.buckets, func(b priceBucket) float64 { return b.lastPrice }, s.removeOutliers)
+	if len(buckets) == 0 {
+		return s.value
+	}
+	start := t.Add(-s.window.length)
+	var total, duration float64
+	for i, b := range buckets {
+		from := b.time
+		if from.Before(start) {
+			from = start
+		}
+		to := t
+		if i+1 < len(buckets) {
+			to = buckets[i+1].time
+		}
+		if d := to.Sub(from).Seconds(); d > 0 {
+			total += b.lastPrice * d
+			duration += d
+		}
+	}
+	if duration > 0 {
+		s.value = total / duration
+	} else {
+		s.value = buckets[len(buckets)-1].lastPrice
+	}
+	s.currentTime = t
+	return s.value
+}
+
+func (s *FilterTWAP) FilterPointForBlock() *dia.FilterPoint {
+	return nil
+}
+
+func (s *FilterTWAP) Save(ds models.Datastore) error {
+	if !s.modified || s.value == 0 {
+		return nil
+	}
+	s.modified = false
+	err := ds.SetFilter(s.filterName, s.asset.Identifier(), s.exchange, s.value, s.currentTime)
+	if err != nil {
+		log.Errorln("FilterTWAP: Error:", err)
+	}
+	return err
+}
@@ -10,7 +10,7 @@ import (
 )
 
 type FilterVOL struct {
-	symbol      string
+	asset       dia.Asset
 	exchange    string
 	currentTime time.Time
 	volumeUSD   float64
@@ -18,11 +18,20 @@ type FilterVOL struct {
 	value       float64
 	filterName  string
 	memory      int
+	modified    bool
 }
 
-func NewFilterVOL(symbol string, exchange string, memory int) *FilterVOL {
+func init() {
+	RegisterFilter("VOL", "", func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		s := NewFilterVOL(asset, exchange, params.Window)
+		s.filterName = name
+		return s
+	})
+}
+
+func NewFilterVOL(asset dia.Asset, exchange string, memory int) *FilterVOL {
 	s := &FilterVOL{
-		symbol:     symbol,
+		asset:      asset,
 		exchange:   exchange,
 		volumeUSD:  0.0,
 		filterName: "VOL" + strconv.Itoa(memory),
@@ -31,23 +40,28 @@ func NewFilterVOL(symbol string, exchange string, memory int) *FilterVOL {
 	return s
 }
 
-func (s *FilterVOL) finalCompute(time time.Time) float64 {
+func (s *FilterVOL) FinalCompute(time time.Time) float64 {
 	s.value = s.volumeUSD
 	s.volumeUSD = 0.0
+	s.modified = true
 	return s.value
 }
 
-func (s *FilterVOL) filterPointForBlock() *dia.FilterPoint {
+func (s *FilterVOL) FilterPointForBlock() *dia.FilterPoint {
 	return nil
 }
 
-func (s *FilterVOL) compute(trade dia.Trade) {
+func (s *FilterVOL) Compute(trade dia.Trade) {
 	s.volumeUSD += trade.EstimatedUSDPrice * math.Abs(trade.Volume)
 	s.currentTime = trade.Time
 }
 
-func (s *FilterVOL) save(ds models.Datastore) error {
-	err := ds.SetFilter(s.filterName, s.symbol, s.exchange, s.value, s.currentTime)
+func (s *FilterVOL) Save(ds models.Datastore) error {
+	if !s.modified {
+		return nil
+	}
+	s.modified = false
+	err := ds.SetFilter(s.filterName, s.asset.Identifier(), s.exchange, s.value, s.currentTime)
 	if err != nil {
 		log.Errorln("FilterVOL Error:", err)
 	}
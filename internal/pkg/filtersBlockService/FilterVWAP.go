@@ -0,0 +1,101 @@
+// FilterVWAP implements a volume weighted average price over a sliding window
+// see: https://en.wikipedia.org/wiki/Volume-weighted_average_price
+package filters
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// FilterVWAP contains the configuration parameters of the filter
+type FilterVWAP struct {
+	asset          dia.Asset
+	exchange       string
+	currentTime    time.Time
+	window         priceWindow
+	value          float64
+	filterName     string
+	modified       bool
+	removeOutliers func([]float64) []float64
+}
+
+func init() {
+	RegisterFilter("VWAP", OutliersNone, func(name string, asset dia.Asset, exchange string, beginTime time.Time, params FilterParams) Filter {
+		s := NewFilterVWAP(asset, exchange, beginTime, params.Window)
+		s.filterName = name
+		s.removeOutliers = outlierMethods[params.Outliers]
+		return s
+	})
+}
+
+// NewFilterVWAP creates a FilterVWAP over a window of @window seconds
+func NewFilterVWAP(asset dia.Asset, exchange string, currentTime time.Time, window int) *FilterVWAP {
+	s := &FilterVWAP{
+		asset:          asset,
+		exchange:       exchange,
+		currentTime:    currentTime,
+		window:         newPriceWindow(window),
+		filterName:     "VWAP" + strconv.Itoa(window),
+		removeOutliers: outlierMethods[OutliersNone],
+	}
+	return s
+}
+
+func (s *FilterVWAP) Compute(trade dia.Trade) {
+	if trade.Time.Before(s.window.last()) {
+		log.Errorln("FilterVWAP: Ignoring Trade out of order ", s.window.last(), trade.Time)
+		return
+	}
+	s.window.add(trade)
+	s.modified = true
+}
+
+// FinalCompute returns the volume weighted average of the prices in the window ending at @t.
+// The value is kept if there is no trade with volume in the window.
+func (s *FilterVWAP) FinalCompute(t time.Time) float64 {
+	s.window.trim(t)
+	start := t.Add(-s.window.length)
+	buckets := []priceBucket{}
+	for _, b := range s.window.buckets {
+		if b.time.After(start) && b.volume > 0 {
+			buckets = append(buckets, b)
+		}
+	}
+	buckets = cleanBuckets(buckets, priceBucket.vwap, s.removeOutliers)
+	var turnover, volume float64
+	for _, b := range buckets {
+		turnover += b.turnover
+		volume += b.volume
+	}
+	if volume > 0 {
+		s.value = turnover / volume
+		s.currentTime = t
+	}
+	return s.value
+}
+
+// ComputeWeighted adds @trade with its volume weighted by the @weight of its exchange.
+func (s *FilterVWAP) ComputeWeighted(trade dia.Trade, weight float64) {
+	trade.Volume *= weight
+	s.Compute(trade)
+}
+
+func (s *FilterVWAP) FilterPointForBlock() *dia.FilterPoint {
+	return nil
+}
+
+func (s *FilterVWAP) Save(ds models.Datastore) error {
+	if !s.modified || s.value == 0 {
+		return nil
+	}
+	s.modified = false
+	err := ds.SetFilter(s.filterName, s.asset.Identifier(), s.exchange, s.value, s.currentTime)
+	if err != nil {
+		log.Errorln("FilterVWAP: Error:", err)
+	}
+	return err
+}
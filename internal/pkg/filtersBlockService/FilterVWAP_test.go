@@ -0,0 +1,73 @@
+package filters
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestFilterVWAP(t *testing.T) {
+	d := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFilterVWAP(dia.Asset{Symbol: "BTC"}, "", d, 60)
+	f.Compute(dia.Trade{EstimatedUSDPrice: 100, Volume: 1, Time: d.Add(-10 * time.Second)})
+	f.Compute(dia.Trade{EstimatedUSDPrice: 200, Volume: -1, Time: d.Add(10 * time.Second)})
+	f.Compute(dia.Trade{EstimatedUSDPrice: 300, Volume: 2, Time: d.Add(10 * time.Second)})
+	// out of order
+	f.Compute(dia.Trade{EstimatedUSDPrice: 1000, Volume: 1, Time: d})
+	if v := f.FinalCompute(d.Add(30 * time.Second)); v != 225 {
+		t.Errorf("expected VWAP 225, got %v", v)
+	}
+	// the first trade left the window
+	if v := f.FinalCompute(d.Add(55 * time.Second)); math.Abs(v-800.0/3) > 1e-9 {
+		t.Errorf("expected VWAP %v, got %v", 800.0/3, v)
+	}
+	// no trades in the window
+	if v := f.FinalCompute(d.Add(2 * time.Minute)); math.Abs(v-800.0/3) > 1e-9 {
+		t.Errorf("expected unchanged VWAP, got %v", v)
+	}
+}
+
+func TestFilterVWAPCleanOutliers(t *testing.T) {
+	d := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFilterVWAP(dia.Asset{Symbol: "BTC"}, "", d, 120)
+	f.removeOutliers = removeOutliers
+	for i, p := range []float64{100, 101, 99, 100, 102, 98, 1000} {
+		f.Compute(dia.Trade{EstimatedUSDPrice: p, Volume: 1, Time: d.Add(time.Duration(i) * time.Second)})
+	}
+	if v := f.FinalCompute(d.Add(time.Minute)); v != 100 {
+		t.Errorf("expected VWAP 100 without the outlier, got %v", v)
+	}
+}
+
+func TestFilterTWAP(t *testing.T) {
+	d := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFilterTWAP(dia.Asset{Symbol: "BTC"}, "Binance", d, 60)
+	f.Compute(dia.Trade{EstimatedUSDPrice: 100, Volume: 5, Time: d.Add(-30 * time.Second)})
+	f.Compute(dia.Trade{EstimatedUSDPrice: 200, Volume: 1, Time: d.Add(15 * time.Second)})
+	// 100 from -15s to 15s, 200 from 15s to 45s
+	if v := f.FinalCompute(d.Add(45 * time.Second)); v != 150 {
+		t.Errorf("expected TWAP 150, got %v", v)
+	}
+	if v := f.FinalCompute(d.Add(2 * time.Minute)); v != 200 {
+		t.Errorf("expected TWAP 200, got %v", v)
+	}
+}
+
+func TestPriceWindowResolution(t *testing.T) {
+	d := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFilterVWAP(dia.Asset{Symbol: "BTC"}, "", d, 86400)
+	for i := 0; i < 3600; i++ {
+		f.Compute(dia.Trade{EstimatedUSDPrice: 100 + float64(i%2), Volume: 1, Time: d.Add(time.Duration(i) * time.Second)})
+	}
+	if n := len(f.window.buckets); n != 60 {
+		t.Errorf("expected an hour of trades in 60 buckets, got %d", n)
+	}
+	if v := f.FinalCompute(d.Add(time.Hour)); v != 100.5 {
+		t.Errorf("expected VWAP 100.5, got %v", v)
+	}
+	if w := newPriceWindow(120); w.resolution != time.Second {
+		t.Errorf("expected short windows aggregated by second, got %v", w.resolution)
+	}
+}
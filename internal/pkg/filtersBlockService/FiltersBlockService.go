@@ -2,11 +2,14 @@ package filters
 
 import (
 	"errors"
+	"reflect"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/cnf/structhash"
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
 	models "github.com/diadata-org/diadata/pkg/model"
 	log "github.com/sirupsen/logrus"
 )
@@ -28,13 +31,19 @@ type FiltersBlockService struct {
 	started              bool
 	currentTime          time.Time
 	filters              map[string][]Filter
+	filtersConfig        FiltersConfig
 	lastLog              time.Time
 	calculationValues    []int
 	previousBlockFilters []dia.FilterPoint
 	datastore            models.Datastore
+	// trading of the assets by identifier and exchange, if exchange rules are configured
+	activities map[string]map[string]*exchangeActivity
+	assets     map[string]dia.Asset
+	firstBlock time.Time
 }
 
-func NewFiltersBlockService(previousBlockFilters []dia.FilterPoint, datastore models.Datastore, chanFiltersBlock chan *dia.FiltersBlock) *FiltersBlockService {
+// NewFiltersBlockService returns a service computing the filters of @filtersConfig on each trades block.
+func NewFiltersBlockService(previousBlockFilters []dia.FilterPoint, datastore models.Datastore, chanFiltersBlock chan *dia.FiltersBlock, filtersConfig FiltersConfig) *FiltersBlockService {
 	s := &FiltersBlockService{
 		shutdown:             make(chan nothing),
 		shutdownDone:         make(chan nothing),
@@ -43,10 +52,13 @@ func NewFiltersBlockService(previousBlockFilters []dia.FilterPoint, datastore mo
 		error:                nil,
 		started:              false,
 		filters:              make(map[string][]Filter),
+		filtersConfig:        filtersConfig,
 		lastLog:              time.Now(),
 		calculationValues:    make([]int, 0),
 		previousBlockFilters: previousBlockFilters,
 		datastore:            datastore,
+		activities:           make(map[string]map[string]*exchangeActivity),
+		assets:               make(map[string]dia.Asset),
 	}
 	s.calculationValues = append(s.calculationValues, dia.BlockSizeSeconds)
 
@@ -79,23 +91,25 @@ func (s *FiltersBlockService) cleanup(err error) {
 	close(s.shutdownDone) // signal that shutdown is complete
 }
 
-func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.FilterPoint) []dia.FilterPoint {
+// addMissingPoints adds the points of the previous block missing in @newFilters. The age of a point
+// is measured at the end @t of the block, so that recomputations give the same blocks.
+func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.FilterPoint, t time.Time) []dia.FilterPoint {
 	log.Debug("previousBlockFilters", previousBlockFilters)
 	log.Debug("newFilters:", newFilters)
 	missingPoints := 0
 	result := newFilters
 	newFiltersMap := make(map[string]*dia.FilterPoint)
 	for _, filter := range newFilters {
-		newFiltersMap[filter.Name+filter.Symbol] = &filter
+		newFiltersMap[filterPointKey(filter)] = &filter
 	}
 
 	for _, filter := range previousBlockFilters {
 
-		d := time.Now().Sub(filter.Time)
+		d := t.Sub(filter.Time)
 		log.Info("filter:", filter, " age:", d)
 
 		if d > time.Hour*24 {
-			_, ok := newFiltersMap[filter.Name+filter.Symbol]
+			_, ok := newFiltersMap[filterPointKey(filter)]
 			if !ok {
 				result = append(result, filter)
 				log.Debug("Adding", filter.Name+filter.Symbol)
@@ -112,51 +126,191 @@ func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.F
 	return result
 }
 
-func (s *FiltersBlockService) createFilters(symbol string, exchange string, BeginTime time.Time) {
-	_, ok := s.filters[symbol+exchange]
+// filterPointKey identifies the filter @fp and its asset. Points of previous blocks may lack the asset.
+func filterPointKey(fp dia.FilterPoint) string {
+	if fp.Asset.HasAddress() {
+		return fp.Name + fp.Asset.Identifier()
+	}
+	return fp.Name + fp.Symbol
+}
+
+// tradeAssets returns the assets whose filters @t is computed in. Trades of tokens with an
+// address are also computed in the filters of their symbol, which aggregate all tokens
+// quoted under that symbol.
+func tradeAssets(t dia.Trade) []dia.Asset {
+	symbol := dia.Asset{Symbol: t.Symbol}
+	if quote := t.Quote(); quote.HasAddress() {
+		return []dia.Asset{symbol, quote}
+	}
+	return []dia.Asset{symbol}
+}
+
+func (s *FiltersBlockService) createFilters(asset dia.Asset, exchange string, BeginTime time.Time) {
+	_, ok := s.filters[asset.Identifier()+exchange]
 	if !ok {
-		s.filters[symbol+exchange] = []Filter{
-			// Prices are written into redis in MA filter
-			NewFilterMA(symbol, exchange, BeginTime, dia.BlockSizeSeconds),
-			NewFilterTLT(symbol, exchange),
-			NewFilterVOL(symbol, exchange, dia.BlockSizeSeconds),
-			NewFilterMAIR(symbol, exchange, BeginTime, dia.BlockSizeSeconds),
-			NewFilterMEDIR(symbol, exchange, BeginTime, dia.BlockSizeSeconds),
-		}
+		s.filters[asset.Identifier()+exchange] = s.filtersConfig.newFilters(asset, exchange, BeginTime)
 	}
 }
 
-func (s *FiltersBlockService) computeFilters(t dia.Trade, key string) {
+// computeFilters computes the filters @key for @t and adds the time spent to @durations.
+func (s *FiltersBlockService) computeFilters(t dia.Trade, key string, durations map[string]time.Duration) {
 	for _, f := range s.filters[key] {
-		f.compute(t)
+		start := time.Now()
+		f.Compute(t)
+		durations[filterName(f)] += time.Since(start)
 	}
 }
 
+// filterName returns the name of the type of @f, e.g. FilterMA.
+func filterName(f Filter) string {
+	t := reflect.TypeOf(f)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
 // processTradesBlock is the 'main' function in the sense that all mathematical
 // computations are done here.
 func (s *FiltersBlockService) processTradesBlock(tb *dia.TradesBlock) {
 
 	log.Infoln("processTradesBlock starting")
+	blockStart := time.Now()
+	fb := s.computeFiltersBlock(tb)
+
+	if fb.FiltersBlockData.FiltersNumber != 0 && s.chanFiltersBlock != nil {
+		s.chanFiltersBlock <- fb
+	}
+	s.saveFilters()
+	metrics.FiltersBlockDuration.Observe(time.Since(blockStart).Seconds())
+	// c, err := s.datastore.GetCoins()
+	// if err == nil {
+	// for i, v := range c.Coins {
+	// 	log.Info("UpdateSymbolDetails on ", v.Symbol)
+	// 	s.datastore.UpdateSymbolDetails(v.Symbol, i+1)
+	// }
+	// }
+}
+
+// ComputeTradesBlock computes and saves the filters of @tb in the calling goroutine and returns the
+// filters block. It is meant for recomputations and must not be mixed with ProcessTradesBlock.
+func (s *FiltersBlockService) ComputeTradesBlock(tb *dia.TradesBlock) *dia.FiltersBlock {
+	fb := s.computeFiltersBlock(tb)
+	s.saveFilters()
+	return fb
+}
+
+// addActivity adds @t to the trading of @asset on its exchange.
+func (s *FiltersBlockService) addActivity(asset dia.Asset, t dia.Trade) {
+	activities, ok := s.activities[asset.Identifier()]
+	if !ok {
+		activities = make(map[string]*exchangeActivity)
+		s.activities[asset.Identifier()] = activities
+		s.assets[asset.Identifier()] = asset
+	}
+	a, ok := activities[t.Source]
+	if !ok {
+		a = &exchangeActivity{}
+		activities[t.Source] = a
+	}
+	a.add(t)
+}
+
+// aggregateFilters computes the filters on all exchanges of the assets traded in @tb from the pooled
+// @trades of the exchanges with a positive weight in the exchange rules, weighted by WeightedFilters.
+// It returns their points and the weights in effect.
+func (s *FiltersBlockService) aggregateFilters(tb *dia.TradesBlock, trades map[string][]dia.Trade, durations map[string]time.Duration) (points []dia.FilterPoint, weights []dia.ExchangeWeight) {
+	if s.firstBlock.IsZero() {
+		s.firstBlock = tb.TradesBlockData.BeginTime
+	}
+	ids := []string{}
+	for id := range s.activities {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		asset := s.assets[id]
+		assetWeights := exchangeWeights(s.filtersConfig.Exchanges.rules(asset), asset, s.activities[id], s.firstBlock, tb.TradesBlockData.EndTime)
+		if len(trades[id]) == 0 {
+			continue
+		}
+		weights = append(weights, assetWeights...)
+		exchangeWeight := make(map[string]float64)
+		for _, w := range assetWeights {
+			exchangeWeight[w.Exchange] = w.Weight
+		}
+		for _, f := range s.filters[id] {
+			start := time.Now()
+			wf, weighted := f.(WeightedFilter)
+			for _, t := range trades[id] {
+				w := exchangeWeight[t.Source]
+				if w <= 0 {
+					continue
+				}
+				if weighted {
+					wf.ComputeWeighted(t, w)
+				} else {
+					f.Compute(t)
+				}
+			}
+			f.FinalCompute(tb.TradesBlockData.EndTime)
+			durations[filterName(f)] += time.Since(start)
+			fp := f.FilterPointForBlock()
+			if fp != nil {
+				points = append(points, *fp)
+			}
+		}
+	}
+	return
+}
+
+// computeFiltersBlock computes the filters of @tb and returns the filters block. Its filter
+// points are sorted, so that the same trades blocks give the same filters blocks.
+func (s *FiltersBlockService) computeFiltersBlock(tb *dia.TradesBlock) *dia.FiltersBlock {
+	durations := make(map[string]time.Duration)
+	// trades of the assets whose filters on all exchanges are computed with exchange rules
+	trades := make(map[string][]dia.Trade)
 
 	for _, trade := range tb.TradesBlockData.Trades {
-		s.createFilters(trade.Symbol, "", tb.TradesBlockData.BeginTime)
-		s.createFilters(trade.Symbol, trade.Source, tb.TradesBlockData.BeginTime)
-		s.computeFilters(trade, trade.Symbol)
-		s.computeFilters(trade, trade.Symbol+trade.Source)
+		for _, asset := range tradeAssets(trade) {
+			s.createFilters(asset, "", tb.TradesBlockData.BeginTime)
+			s.createFilters(asset, trade.Source, tb.TradesBlockData.BeginTime)
+			if s.filtersConfig.Exchanges != nil {
+				s.addActivity(asset, trade)
+				trades[asset.Identifier()] = append(trades[asset.Identifier()], trade)
+			} else {
+				s.computeFilters(trade, asset.Identifier(), durations)
+			}
+			s.computeFilters(trade, asset.Identifier()+trade.Source, durations)
+		}
 	}
 
 	resultFilters := []dia.FilterPoint{}
-	for _, filters := range s.filters {
+	for key, filters := range s.filters {
+		if _, ok := s.activities[key]; ok {
+			continue
+		}
 		for _, f := range filters {
-			f.finalCompute(tb.TradesBlockData.EndTime)
-			fp := f.filterPointForBlock()
+			start := time.Now()
+			f.FinalCompute(tb.TradesBlockData.EndTime)
+			durations[filterName(f)] += time.Since(start)
+			fp := f.FilterPointForBlock()
 			if fp != nil {
 				resultFilters = append(resultFilters, *fp)
 			}
 		}
 	}
+	var weights []dia.ExchangeWeight
+	if s.filtersConfig.Exchanges != nil {
+		var points []dia.FilterPoint
+		points, weights = s.aggregateFilters(tb, trades, durations)
+		resultFilters = append(resultFilters, points...)
+	}
 
-	resultFilters = addMissingPoints(s.previousBlockFilters, resultFilters)
+	resultFilters = addMissingPoints(s.previousBlockFilters, resultFilters, tb.TradesBlockData.EndTime)
+	sort.Slice(resultFilters, func(i, j int) bool {
+		return filterPointKey(resultFilters[i]) < filterPointKey(resultFilters[j])
+	})
 
 	s.previousBlockFilters = resultFilters
 
@@ -167,6 +321,7 @@ func (s *FiltersBlockService) processTradesBlock(tb *dia.TradesBlock) {
 			EndTime:         tb.TradesBlockData.EndTime,
 			BeginTime:       tb.TradesBlockData.BeginTime,
 			TradesBlockHash: tb.BlockHash,
+			ExchangeWeights: weights,
 		},
 	}
 
@@ -177,23 +332,21 @@ func (s *FiltersBlockService) processTradesBlock(tb *dia.TradesBlock) {
 	}
 	fb.BlockHash = hash
 	log.Printf("Generating Filters block %v (size:%v)", hash, fb.FiltersBlockData.FiltersNumber)
-
-	if len(resultFilters) != 0 && s.chanFiltersBlock != nil {
-		s.chanFiltersBlock <- fb
+	for name, d := range durations {
+		metrics.FilterComputeDuration.WithLabelValues(name).Observe(d.Seconds())
 	}
+	metrics.BlockSize.WithLabelValues("filters").Observe(float64(fb.FiltersBlockData.FiltersNumber))
+	return fb
+}
+
+// saveFilters writes the values of all filters to the datastore.
+func (s *FiltersBlockService) saveFilters() {
 	for _, filters := range s.filters {
 		for _, f := range filters {
-			f.save(s.datastore)
+			f.Save(s.datastore)
 		}
 	}
 	s.datastore.Flush()
-	// c, err := s.datastore.GetCoins()
-	// if err == nil {
-	// for i, v := range c.Coins {
-	// 	log.Info("UpdateSymbolDetails on ", v.Symbol)
-	// 	s.datastore.UpdateSymbolDetails(v.Symbol, i+1)
-	// }
-	// }
 }
 
 // runs in a goroutine until s is closed
@@ -0,0 +1,110 @@
+package filters
+
+import (
+	"math"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// windows longer than this are aggregated by minute instead of by second
+const maxSecondBucketsWindow = 10 * time.Minute
+
+// priceBucket aggregates the trades of one resolution step of a priceWindow.
+type priceBucket struct {
+	time      time.Time
+	lastPrice float64
+	volume    float64
+	// sum of the prices weighted by their volume
+	turnover float64
+}
+
+// vwap returns the volume weighted average price of the bucket, or its last price if it has no volume.
+func (b priceBucket) vwap() float64 {
+	if b.volume == 0 {
+		return b.lastPrice
+	}
+	return b.turnover / b.volume
+}
+
+// priceWindow holds the trades of a sliding window aggregated by second, or by minute for
+// windows longer than maxSecondBucketsWindow. This bounds a window of a day to 1440 buckets,
+// while the start of the window is only precise to the minute.
+type priceWindow struct {
+	length     time.Duration
+	resolution time.Duration
+	buckets    []priceBucket
+}
+
+func newPriceWindow(seconds int) priceWindow {
+	w := priceWindow{length: time.Duration(seconds) * time.Second, resolution: time.Second}
+	if w.length > maxSecondBucketsWindow {
+		w.resolution = time.Minute
+	}
+	return w
+}
+
+// last returns the time of the latest trade in the window.
+func (w *priceWindow) last() time.Time {
+	if len(w.buckets) == 0 {
+		return time.Time{}
+	}
+	return w.buckets[len(w.buckets)-1].time
+}
+
+// add adds @trade to the window. Trades older than the latest bucket must be ignored by the caller.
+func (w *priceWindow) add(trade dia.Trade) {
+	t := trade.Time.Truncate(w.resolution)
+	volume := math.Abs(trade.Volume)
+	if n := len(w.buckets); n > 0 && w.buckets[n-1].time.Equal(t) {
+		b := &w.buckets[n-1]
+		b.lastPrice = trade.EstimatedUSDPrice
+		b.volume += volume
+		b.turnover += volume * trade.EstimatedUSDPrice
+		return
+	}
+	w.buckets = append(w.buckets, priceBucket{
+		time:      t,
+		lastPrice: trade.EstimatedUSDPrice,
+		volume:    volume,
+		turnover:  volume * trade.EstimatedUSDPrice,
+	})
+}
+
+// trim drops the buckets which left the window ending at @end, except the latest of them,
+// which holds the price at the start of the window.
+func (w *priceWindow) trim(end time.Time) {
+	start := end.Add(-w.length)
+	i := 0
+	for i < len(w.buckets)-1 && !w.buckets[i+1].time.After(start) {
+		i++
+	}
+	w.buckets = w.buckets[i:]
+}
+
+// cleanBuckets returns the buckets whose @price lies within the range of the prices kept by @removeOutliers.
+func cleanBuckets(buckets []priceBucket, price func(priceBucket) float64, removeOutliers func([]float64) []float64) []priceBucket {
+	if len(buckets) < 2 {
+		return buckets
+	}
+	prices := make([]float64, len(buckets))
+	for i, b := range buckets {
+		prices[i] = price(b)
+	}
+	kept := removeOutliers(prices)
+	if len(kept) == 0 {
+		return buckets
+	}
+	lower, upper := kept[0], kept[0]
+	for _, p := range kept {
+		lower = math.Min(lower, p)
+		upper = math.Max(upper, p)
+	}
+	result := []priceBucket{}
+	for _, b := range buckets {
+		if p := price(b); p >= lower && p <= upper {
+			result = append(result, b)
+		}
+	}
+	return result
+}
@@ -0,0 +1,454 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+const (
+	defaultMinMaturity      = 7 * 24 * time.Hour
+	defaultOrderbookMaxAge  = 15 * time.Minute
+	minStrikesPerTerm       = 3
+	maxConsecutiveZeroBids  = 2
+	optionExpirationHourUTC = 8
+)
+
+// optionVenue identifies the options of a venue by their instrument names.
+type optionVenue struct {
+	exchange string
+	name     *regexp.Regexp
+	// layout of the expiration date in the name, empty if the name does not carry the option meta
+	expirationLayout string
+	// inverse venues quote premiums in the base currency rather than USD
+	inverse bool
+}
+
+var optionVenues = []optionVenue{
+	// e.g. BTC-25JUN21-40000-C
+	{exchange: dia.Deribit, name: regexp.MustCompile(`^([A-Z]+)-(\d{1,2}[A-Z]{3}\d{2})-([\d.]+)-([CP])$`), expirationLayout: "2Jan06", inverse: true},
+	// e.g. BTC-USD-210625-40000-C
+	{exchange: dia.OKExExchange, name: regexp.MustCompile(`^([A-Z]+)-USD-(\d{6})-([\d.]+)-([CP])$`), expirationLayout: "060102", inverse: true},
+	// the on-chain venues name their options by contract address and cannot be told apart
+	{exchange: dia.Opyn, name: regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)},
+	{exchange: dia.Premia, name: regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)},
+}
+
+// VolatilityIndex is a volatility index of an underlying at a constant maturity in the methodology of
+// the Cboe VIX: the variance of each expiry is derived from the mid prices of its out of the money
+// options, and the variances of the expiries around the target maturity are interpolated to it.
+type VolatilityIndex struct {
+	Underlying string
+	// Exchanges are the option venues entering the index, all venues if empty.
+	Exchanges      []string
+	TargetMaturity time.Duration
+	// MinMaturity is the minimal time to expiry of an expiry entering the index.
+	MinMaturity time.Duration
+	// RiskFreeRate is annual and continuously compounded.
+	RiskFreeRate float64
+	// MaxAge is the maximal age of the order book data of an option.
+	MaxAge time.Duration
+}
+
+// NewVolatilityIndex returns the volatility index of @underlying at @targetMaturity from the options
+// of @exchanges, leaving out expiries within a week and order book data older than 15 minutes.
+func NewVolatilityIndex(underlying string, exchanges []string, targetMaturity time.Duration, riskFreeRate float64) *VolatilityIndex {
+	return &VolatilityIndex{
+		Underlying:     underlying,
+		Exchanges:      exchanges,
+		TargetMaturity: targetMaturity,
+		MinMaturity:    defaultMinMaturity,
+		RiskFreeRate:   riskFreeRate,
+		MaxAge:         defaultOrderbookMaxAge,
+	}
+}
+
+// Value returns the index at @t from the order book data stored in @ds.
+func (vi *VolatilityIndex) Value(ds models.Datastore, t time.Time) (float64, error) {
+	options, err := vi.Options(ds, t)
+	if err != nil {
+		return 0, err
+	}
+	return vi.Compute(options, t)
+}
+
+// Recompute returns the index every @step in [@starttime, @endtime] from the stored order book data,
+// e.g. to backtest changes of the methodology. Timestamps without enough data are left out.
+func (vi *VolatilityIndex) Recompute(ds models.Datastore, starttime time.Time, endtime time.Time, step time.Duration) ([]dia.CviDataPoint, error) {
+	if step <= 0 {
+		return nil, errors.New("step must be positive")
+	}
+	var points []dia.CviDataPoint
+	for t := starttime; !t.After(endtime); t = t.Add(step) {
+		value, err := vi.Value(ds, t)
+		if err != nil {
+			log.Warnf("volatility index of %s at %v: %v", vi.Underlying, t, err)
+			continue
+		}
+		points = append(points, dia.CviDataPoint{Timestamp: t, Value: value})
+	}
+	return points, nil
+}
+
+// Options returns the options on the underlying at the index' venues with their latest order book
+// datum before @t. Options whose meta has been removed from the datastore after expiration are
+// recovered from their names where the venue allows it.
+func (vi *VolatilityIndex) Options(ds models.Datastore, t time.Time) ([]dia.OptionMetaIndex, error) {
+	metas := make(map[string]dia.OptionMeta)
+	optionsMeta, err := ds.GetOptionMeta(vi.Underlying)
+	if err != nil {
+		log.Warn("GetOptionMeta: ", err)
+	}
+	for _, optionMeta := range optionsMeta {
+		metas[optionMeta.InstrumentName] = optionMeta
+	}
+
+	orderbooks, err := ds.GetOptionOrderbookData(t.Add(-vi.MaxAge), t)
+	if err != nil {
+		return nil, err
+	}
+	var options []dia.OptionMetaIndex
+	for _, orderbook := range orderbooks {
+		if !vi.includes(orderbook.InstrumentName) {
+			continue
+		}
+		optionMeta, ok := metas[orderbook.InstrumentName]
+		if !ok {
+			optionMeta, ok = optionMetaFromName(orderbook.InstrumentName)
+		}
+		if !ok || optionMeta.BaseCurrency != vi.Underlying {
+			continue
+		}
+		options = append(options, dia.OptionMetaIndex{OptionMeta: optionMeta, OptionOrderbookDatum: orderbook})
+	}
+	return options, nil
+}
+
+// Compute returns the index at @t from the order book data of @options. The result only depends on
+// its input, so that a recomputation over stored data is reproducible.
+func (vi *VolatilityIndex) Compute(options []dia.OptionMetaIndex, t time.Time) (float64, error) {
+	byExpiry := make(map[time.Time][]dia.OptionMetaIndex)
+	for _, option := range options {
+		expiration := option.OptionMeta.ExpirationTime
+		if expiration.Sub(t) < vi.MinMaturity || !vi.includes(option.OptionMeta.InstrumentName) {
+			continue
+		}
+		byExpiry[expiration] = append(byExpiry[expiration], option)
+	}
+
+	var terms []varianceTerm
+	for expiration, termOptions := range byExpiry {
+		years := expiration.Sub(t).Hours() / 24 / 365
+		variance, err := termVariance(termOptions, years, vi.RiskFreeRate)
+		if err != nil {
+			log.Debugf("variance of expiry %v: %v", expiration, err)
+			continue
+		}
+		terms = append(terms, varianceTerm{expiration: expiration, years: years, variance: variance})
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].expiration.Before(terms[j].expiration) })
+
+	near, next, err := selectTerms(terms, t, vi.TargetMaturity)
+	if err != nil {
+		return 0, err
+	}
+	return interpolateVariance(near, next, vi.TargetMaturity.Hours()/24/365)
+}
+
+// includes tells whether the option @instrumentName is traded at one of the index' venues.
+func (vi *VolatilityIndex) includes(instrumentName string) bool {
+	if len(vi.Exchanges) == 0 {
+		return true
+	}
+	for _, venue := range optionVenues {
+		if !venue.name.MatchString(instrumentName) {
+			continue
+		}
+		for _, exchange := range vi.Exchanges {
+			if exchange == venue.exchange {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// isInverse tells whether the premium of @instrumentName is quoted in its base currency.
+func isInverse(instrumentName string) bool {
+	for _, venue := range optionVenues {
+		if venue.name.MatchString(instrumentName) {
+			return venue.inverse
+		}
+	}
+	return false
+}
+
+// optionMetaFromName parses the meta of options whose name carries it. Options expire at 08:00 UTC.
+func optionMetaFromName(instrumentName string) (dia.OptionMeta, bool) {
+	for _, venue := range optionVenues {
+		match := venue.name.FindStringSubmatch(instrumentName)
+		if venue.expirationLayout == "" || match == nil {
+			continue
+		}
+		expiration, err := time.Parse(venue.expirationLayout, match[2])
+		if err != nil {
+			return dia.OptionMeta{}, false
+		}
+		strike, err := strconv.ParseFloat(match[3], 64)
+		if err != nil {
+			return dia.OptionMeta{}, false
+		}
+		optionType := dia.CallOption
+		if match[4] == "P" {
+			optionType = dia.PutOption
+		}
+		return dia.OptionMeta{
+			InstrumentName: instrumentName,
+			BaseCurrency:   match[1],
+			ExpirationTime: expiration.Add(optionExpirationHourUTC * time.Hour),
+			StrikePrice:    strike,
+			OptionType:     optionType,
+		}, true
+	}
+	return dia.OptionMeta{}, false
+}
+
+type varianceTerm struct {
+	expiration time.Time
+	years      float64
+	variance   float64
+}
+
+// selectTerms returns the latest expiry up to the target maturity and the earliest one after it.
+// If all expiries lie on one side of the target, the two closest to it are extrapolated.
+func selectTerms(terms []varianceTerm, t time.Time, targetMaturity time.Duration) (near varianceTerm, next varianceTerm, err error) {
+	if len(terms) < 2 {
+		return near, next, fmt.Errorf("%d expiries with enough options, 2 needed", len(terms))
+	}
+	target := t.Add(targetMaturity)
+	i := sort.Search(len(terms), func(i int) bool { return terms[i].expiration.After(target) })
+	switch i {
+	case 0:
+		return terms[0], terms[1], nil
+	case len(terms):
+		return terms[i-2], terms[i-1], nil
+	default:
+		return terms[i-1], terms[i], nil
+	}
+}
+
+// interpolateVariance interpolates the total variances of @near and @next linearly to @target years
+// and returns the annualized volatility in percent.
+func interpolateVariance(near varianceTerm, next varianceTerm, target float64) (float64, error) {
+	if next.years <= near.years {
+		return 0, errors.New("next term has to expire after near term")
+	}
+	w1 := (next.years - target) / (next.years - near.years)
+	w2 := (target - near.years) / (next.years - near.years)
+	variance := (near.years*near.variance*w1 + next.years*next.variance*w2) / target
+	if variance < 0 {
+		return 0, errors.New("negative interpolated variance")
+	}
+	return 100 * math.Sqrt(variance), nil
+}
+
+type optionQuote struct {
+	instrumentName string
+	strike         float64
+	optionType     dia.OptionType
+	mid            float64
+	spread         float64
+	inverse        bool
+}
+
+type strikeQuotes struct {
+	strike   float64
+	call     *optionQuote
+	put      *optionQuote
+	callMid  float64
+	putMid   float64
+	included bool
+}
+
+// termVariance returns the annualized variance of an expiry @years ahead at risk-free @rate:
+// sigma^2 = 2/T * sum_i dK_i/K_i^2 * exp(RT) * Q(K_i) - 1/T * (F/K_0 - 1)^2,
+// where Q(K_i) are the mid prices of the out of the money options and K_0 is the highest strike up to the forward F.
+func termVariance(options []dia.OptionMetaIndex, years float64, rate float64) (float64, error) {
+	var quotes []optionQuote
+	for _, option := range options {
+		bid, ask := option.BidPrice, option.AskPrice
+		if bid <= 0 || ask < bid {
+			continue
+		}
+		quotes = append(quotes, optionQuote{
+			instrumentName: option.OptionMeta.InstrumentName,
+			strike:         option.OptionMeta.StrikePrice,
+			optionType:     option.OptionMeta.OptionType,
+			mid:            (bid + ask) / 2,
+			spread:         ask - bid,
+			inverse:        isInverse(option.OptionMeta.InstrumentName),
+		})
+	}
+	sort.Slice(quotes, func(i, j int) bool { return quotes[i].instrumentName < quotes[j].instrumentName })
+
+	forward, err := forwardLevel(quotes, years, rate)
+	if err != nil {
+		return 0, err
+	}
+
+	// the forward values of the tightest quotes at each strike
+	byStrike := make(map[float64]*strikeQuotes)
+	for i := range quotes {
+		q := &quotes[i]
+		s, ok := byStrike[q.strike]
+		if !ok {
+			s = &strikeQuotes{strike: q.strike}
+			byStrike[q.strike] = s
+		}
+		value := q.mid * math.Exp(rate*years)
+		if q.inverse {
+			value = q.mid * forward
+		}
+		switch q.optionType {
+		case dia.CallOption:
+			if s.call == nil || q.spread/q.mid < s.call.spread/s.call.mid {
+				s.call, s.callMid = q, value
+			}
+		case dia.PutOption:
+			if s.put == nil || q.spread/q.mid < s.put.spread/s.put.mid {
+				s.put, s.putMid = q, value
+			}
+		}
+	}
+	var strikes []*strikeQuotes
+	for _, s := range byStrike {
+		strikes = append(strikes, s)
+	}
+	sort.Slice(strikes, func(i, j int) bool { return strikes[i].strike < strikes[j].strike })
+
+	k0 := sort.Search(len(strikes), func(i int) bool { return strikes[i].strike > forward }) - 1
+	if k0 < 0 {
+		return 0, errors.New("forward below the lowest strike")
+	}
+	if strikes[k0].call == nil && strikes[k0].put == nil {
+		return 0, errors.New("no quotes at the strike below the forward")
+	}
+	strikes[k0].included = true
+	// out of the money puts below and calls above K_0, until two consecutive strikes without bid
+	for i, zeroBids := k0-1, 0; i >= 0 && zeroBids < maxConsecutiveZeroBids; i-- {
+		if strikes[i].put == nil {
+			zeroBids++
+			continue
+		}
+		zeroBids = 0
+		strikes[i].included = true
+	}
+	for i, zeroBids := k0+1, 0; i < len(strikes) && zeroBids < maxConsecutiveZeroBids; i++ {
+		if strikes[i].call == nil {
+			zeroBids++
+			continue
+		}
+		zeroBids = 0
+		strikes[i].included = true
+	}
+
+	var included []*strikeQuotes
+	for _, s := range strikes {
+		if s.included {
+			included = append(included, s)
+		}
+	}
+	if len(included) < minStrikesPerTerm {
+		return 0, fmt.Errorf("%d strikes with out of the money quotes, %d needed", len(included), minStrikesPerTerm)
+	}
+
+	var sum float64
+	for i, s := range included {
+		var deltaK float64
+		switch i {
+		case 0:
+			deltaK = included[1].strike - s.strike
+		case len(included) - 1:
+			deltaK = s.strike - included[i-1].strike
+		default:
+			deltaK = (included[i+1].strike - included[i-1].strike) / 2
+		}
+		var q float64
+		switch {
+		case s.strike < strikes[k0].strike:
+			q = s.putMid
+		case s.strike > strikes[k0].strike:
+			q = s.callMid
+		case s.call != nil && s.put != nil:
+			q = (s.callMid + s.putMid) / 2
+		case s.call != nil:
+			q = s.callMid
+		default:
+			q = s.putMid
+		}
+		sum += deltaK / (s.strike * s.strike) * q
+	}
+	return 2/years*sum - math.Pow(forward/strikes[k0].strike-1, 2)/years, nil
+}
+
+// forwardLevel returns the forward from the put-call parity at the strike with the smallest difference
+// of call and put price. For USD premiums F = K + exp(RT) * (C - P), for premiums in the base currency
+// the forward values are c*F and p*F and hence F = K / (1 - (c - p)).
+// Quotes in USD and in the base currency are not mixed; the denomination with more strikes quoted on
+// both sides is used.
+func forwardLevel(quotes []optionQuote, years float64, rate float64) (float64, error) {
+	var forward float64
+	var pairs int
+	for _, inverse := range []bool{true, false} {
+		calls := make(map[float64]optionQuote)
+		puts := make(map[float64]optionQuote)
+		for _, q := range quotes {
+			if q.inverse != inverse {
+				continue
+			}
+			quotesByType := calls
+			if q.optionType == dia.PutOption {
+				quotesByType = puts
+			}
+			if existing, ok := quotesByType[q.strike]; !ok || q.spread/q.mid < existing.spread/existing.mid {
+				quotesByType[q.strike] = q
+			}
+		}
+
+		var n int
+		var minDiff, strike, diff float64
+		for k, call := range calls {
+			put, ok := puts[k]
+			if !ok {
+				continue
+			}
+			d := call.mid - put.mid
+			if n == 0 || math.Abs(d) < minDiff || (math.Abs(d) == minDiff && k < strike) {
+				minDiff, strike, diff = math.Abs(d), k, d
+			}
+			n++
+		}
+		if n == 0 || n <= pairs {
+			continue
+		}
+		f := strike + math.Exp(rate*years)*diff
+		if inverse {
+			if diff >= 1 {
+				continue
+			}
+			f = strike / (1 - diff)
+		}
+		forward, pairs = f, n
+	}
+	if pairs == 0 {
+		return 0, errors.New("no strike with both call and put quotes")
+	}
+	return forward, nil
+}
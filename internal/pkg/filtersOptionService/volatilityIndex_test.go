@@ -0,0 +1,67 @@
+package filters
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func blackScholes(optionType dia.OptionType, forward, strike, years, vol float64) float64 {
+	d1 := (math.Log(forward/strike) + vol*vol*years/2) / (vol * math.Sqrt(years))
+	d2 := d1 - vol*math.Sqrt(years)
+	n := func(x float64) float64 { return 0.5 * math.Erfc(-x/math.Sqrt2) }
+	if optionType == dia.CallOption {
+		return forward*n(d1) - strike*n(d2)
+	}
+	return strike*n(-d2) - forward*n(-d1)
+}
+
+func TestVolatilityIndex(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	const forward = 40000.0
+	var options []dia.OptionMetaIndex
+	// deribit options with a flat volatility of 60% expiring in 20 and 40 days, quoted in BTC
+	for _, expiration := range []time.Time{now.AddDate(0, 0, 20), now.AddDate(0, 0, 40)} {
+		years := expiration.Sub(now).Hours() / 24 / 365
+		for strike := 10000.0; strike <= 120000; strike += 1000 {
+			for _, optionType := range []dia.OptionType{dia.CallOption, dia.PutOption} {
+				premium := blackScholes(optionType, forward, strike, years, 0.6) / forward
+				if premium < 1e-4 {
+					continue
+				}
+				name := fmt.Sprintf("BTC-%s-%.0f-%s", strings.ToUpper(expiration.Format("2Jan06")), strike, map[dia.OptionType]string{dia.CallOption: "C", dia.PutOption: "P"}[optionType])
+				meta, ok := optionMetaFromName(name)
+				if !ok || !meta.ExpirationTime.Equal(expiration) || meta.StrikePrice != strike || meta.OptionType != optionType {
+					t.Fatalf("parsed %s into %+v", name, meta)
+				}
+				options = append(options, dia.OptionMetaIndex{
+					OptionMeta:           meta,
+					OptionOrderbookDatum: dia.OptionOrderbookDatum{InstrumentName: name, BidPrice: premium - 5e-5, AskPrice: premium + 5e-5},
+				})
+			}
+		}
+	}
+
+	vi := NewVolatilityIndex("BTC", []string{dia.Deribit}, 30*24*time.Hour, 0)
+	value, err := vi.Compute(options, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(value-60) > 1 {
+		t.Errorf("expected an index close to 60, got %v", value)
+	}
+	again, _ := vi.Compute(options, now)
+	if again != value {
+		t.Errorf("recomputation gave %v instead of %v", again, value)
+	}
+
+	// no okex options
+	vi.Exchanges = []string{dia.OKExExchange}
+	if _, err = vi.Compute(options, now); err == nil {
+		t.Error("expected error without options of the venue")
+	}
+}
@@ -0,0 +1,71 @@
+package fundingscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	utils "github.com/diadata-org/diadata/pkg/utils"
+)
+
+var bitmexAPI = "https://www.bitmex.com/api/v1"
+
+type bitmexFunding struct{}
+
+// bitmexInstrument is the part of an instrument of https://www.bitmex.com/api/v1/instrument we need.
+type bitmexInstrument struct {
+	Symbol                         string    `json:"symbol"`
+	Underlying                     string    `json:"underlying"`
+	QuoteCurrency                  string    `json:"quoteCurrency"`
+	IsInverse                      bool      `json:"isInverse"`
+	IsQuanto                       bool      `json:"isQuanto"`
+	UnderlyingToPositionMultiplier float64   `json:"underlyingToPositionMultiplier"`
+	FundingRate                    float64   `json:"fundingRate"`
+	IndicativeFundingRate          float64   `json:"indicativeFundingRate"`
+	FundingTimestamp               time.Time `json:"fundingTimestamp"`
+	// the interval is given as time after 2000-01-01, e.g. 2000-01-01T08:00:00.000Z
+	FundingInterval time.Time `json:"fundingInterval"`
+	OpenInterest    float64   `json:"openInterest"`
+	MarkPrice       float64   `json:"markPrice"`
+	Timestamp       time.Time `json:"timestamp"`
+}
+
+func (bitmexFunding) fundingRate(contract string) (*dia.FundingRate, error) {
+	body, err := utils.GetRequest(bitmexAPI + "/instrument?symbol=" + contract)
+	if err != nil {
+		return nil, err
+	}
+	var instruments []bitmexInstrument
+	err = json.Unmarshal(body, &instruments)
+	if err != nil {
+		return nil, err
+	}
+	if len(instruments) == 0 {
+		return nil, errors.New("unknown contract " + contract)
+	}
+	i := instruments[0]
+	f := &dia.FundingRate{
+		Underlying:           i.Underlying,
+		FundingRate:          i.FundingRate,
+		PredictedFundingRate: i.IndicativeFundingRate,
+		FundingTime:          i.FundingTimestamp,
+		FundingInterval:      i.FundingInterval.Sub(time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)),
+		OpenInterest:         i.OpenInterest,
+		MarkPrice:            i.MarkPrice,
+		Time:                 i.Timestamp,
+	}
+	if f.Underlying == "XBT" {
+		f.Underlying = "BTC"
+	}
+	switch {
+	case i.IsQuanto:
+		// the value of quanto contracts depends on the price of bitcoin, it is left unknown
+	case i.IsInverse && i.QuoteCurrency == "USD":
+		// a contract is worth one dollar
+		f.OpenInterestUSD = i.OpenInterest
+	case i.UnderlyingToPositionMultiplier > 0:
+		f.OpenInterestUSD = i.OpenInterest / i.UnderlyingToPositionMultiplier * i.MarkPrice
+	}
+	return f, nil
+}
@@ -0,0 +1,67 @@
+package fundingscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	utils "github.com/diadata-org/diadata/pkg/utils"
+)
+
+var deribitAPI = "https://www.deribit.com/api/v2"
+
+type deribitFunding struct{}
+
+// deribitTicker is the response of https://www.deribit.com/api/v2/public/ticker
+type deribitTicker struct {
+	Result struct {
+		InstrumentName string  `json:"instrument_name"`
+		Funding8h      float64 `json:"funding_8h"`
+		CurrentFunding float64 `json:"current_funding"`
+		OpenInterest   float64 `json:"open_interest"`
+		MarkPrice      float64 `json:"mark_price"`
+		Timestamp      int64   `json:"timestamp"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// fundingRate of a Deribit perpetual. Funding is paid continuously, so FundingRate is the funding
+// of the last 8 hours, PredictedFundingRate the current one and FundingTime the time of observation.
+func (deribitFunding) fundingRate(contract string) (*dia.FundingRate, error) {
+	body, err := utils.GetRequest(deribitAPI + "/public/ticker?instrument_name=" + contract)
+	if err != nil {
+		return nil, err
+	}
+	var ticker deribitTicker
+	err = json.Unmarshal(body, &ticker)
+	if err != nil {
+		return nil, err
+	}
+	if ticker.Error != nil {
+		return nil, errors.New(ticker.Error.Message)
+	}
+	t := ticker.Result
+	observed := time.Unix(0, t.Timestamp*1e6)
+	f := &dia.FundingRate{
+		Underlying:           strings.Split(strings.Split(contract, "-")[0], "_")[0],
+		FundingRate:          t.Funding8h,
+		PredictedFundingRate: t.CurrentFunding,
+		FundingTime:          observed,
+		FundingInterval:      8 * time.Hour,
+		OpenInterest:         t.OpenInterest,
+		MarkPrice:            t.MarkPrice,
+		Time:                 observed,
+	}
+	if strings.Contains(contract, "_") {
+		// linear contracts such as BTC_USDC-PERPETUAL have their open interest in the base currency
+		f.OpenInterestUSD = t.OpenInterest * t.MarkPrice
+	} else {
+		// inverse contracts have their open interest in USD
+		f.OpenInterestUSD = t.OpenInterest
+	}
+	return f, nil
+}
@@ -0,0 +1,92 @@
+package fundingscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	utils "github.com/diadata-org/diadata/pkg/utils"
+)
+
+var ftxAPI = "https://ftx.com/api"
+
+type ftxFunding struct{}
+
+// ftxResponse wraps the results of the FTX REST API
+type ftxResponse struct {
+	Success bool            `json:"success"`
+	Error   string          `json:"error"`
+	Result  json.RawMessage `json:"result"`
+}
+
+type ftxFuture struct {
+	Underlying      string  `json:"underlying"`
+	Perpetual       bool    `json:"perpetual"`
+	Mark            float64 `json:"mark"`
+	OpenInterest    float64 `json:"openInterest"`
+	OpenInterestUsd float64 `json:"openInterestUsd"`
+}
+
+type ftxFutureStats struct {
+	NextFundingRate float64   `json:"nextFundingRate"`
+	NextFundingTime time.Time `json:"nextFundingTime"`
+}
+
+type ftxFundingRate struct {
+	Rate float64   `json:"rate"`
+	Time time.Time `json:"time"`
+}
+
+// fundingRate of an FTX perpetual, which pays funding every hour.
+func (ftxFunding) fundingRate(contract string) (*dia.FundingRate, error) {
+	var future ftxFuture
+	err := ftxGet("/futures/"+contract, &future)
+	if err != nil {
+		return nil, err
+	}
+	if !future.Perpetual {
+		return nil, errors.New(contract + " is no perpetual")
+	}
+	var stats ftxFutureStats
+	err = ftxGet("/futures/"+contract+"/stats", &stats)
+	if err != nil {
+		return nil, err
+	}
+	var rates []ftxFundingRate
+	err = ftxGet("/funding_rates?future="+contract, &rates)
+	if err != nil {
+		return nil, err
+	}
+	f := &dia.FundingRate{
+		Underlying:           future.Underlying,
+		PredictedFundingRate: stats.NextFundingRate,
+		FundingInterval:      time.Hour,
+		OpenInterest:         future.OpenInterest,
+		OpenInterestUSD:      future.OpenInterestUsd,
+		MarkPrice:            future.Mark,
+	}
+	// rates are returned latest first
+	if len(rates) > 0 {
+		f.FundingRate = rates[0].Rate
+		f.FundingTime = rates[0].Time
+	}
+	return f, nil
+}
+
+// ftxGet unmarshals the result of the API @path into @result.
+func ftxGet(path string, result interface{}) error {
+	body, err := utils.GetRequest(ftxAPI + path)
+	if err != nil {
+		return err
+	}
+	var response ftxResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return err
+	}
+	if !response.Success {
+		return errors.New(response.Error)
+	}
+	return json.Unmarshal(response.Result, result)
+}
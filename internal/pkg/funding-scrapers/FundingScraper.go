@@ -0,0 +1,140 @@
+package fundingscrapers
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Determine frequency of scraping
+	refreshDelay = time.Minute
+)
+
+type nothing struct{}
+
+// fundingAPI fetches the funding of a perpetual contract from the REST API of an exchange.
+type fundingAPI interface {
+	fundingRate(contract string) (*dia.FundingRate, error)
+}
+
+// FundingScraper polls the funding rates and open interest of the perpetual contracts of an exchange.
+type FundingScraper struct {
+	// signaling channels
+	shutdown     chan nothing
+	shutdownDone chan nothing
+
+	// error handling; to read error or closed, first acquire read lock
+	// only cleanup method should hold write lock
+	errorLock       sync.RWMutex
+	error           error
+	closed          bool
+	ticker          *time.Ticker
+	exchange        string
+	contracts       []string
+	api             fundingAPI
+	chanFundingRate chan *dia.FundingRate
+}
+
+// SpawnFundingScraper returns a new FundingScraper for the perpetual @contracts of @exchange,
+// one of Bitmex, Deribit, FTX and Huobi. The instance is asynchronously scraping as soon as it is created.
+func SpawnFundingScraper(exchange string, contracts []string) (*FundingScraper, error) {
+	var api fundingAPI
+	switch exchange {
+	case "Bitmex":
+		api = bitmexFunding{}
+	case dia.Deribit:
+		api = deribitFunding{}
+	case dia.FTX:
+		api = ftxFunding{}
+	case dia.HuobiExchange:
+		api = newHuobiFunding()
+	default:
+		return nil, errors.New("no funding scraper for " + exchange)
+	}
+	s := &FundingScraper{
+		shutdown:        make(chan nothing),
+		shutdownDone:    make(chan nothing),
+		ticker:          time.NewTicker(refreshDelay),
+		exchange:        exchange,
+		contracts:       contracts,
+		api:             api,
+		chanFundingRate: make(chan *dia.FundingRate),
+	}
+
+	log.Infof("Funding scraper for %s is built and triggered", exchange)
+	go s.mainLoop()
+	return s, nil
+}
+
+// mainLoop runs in a goroutine until channel s is closed.
+func (s *FundingScraper) mainLoop() {
+	s.update()
+	for {
+		select {
+		case <-s.ticker.C:
+			s.update()
+		case <-s.shutdown: // user requested shutdown
+			log.Println("FundingScraper shutting down")
+			s.cleanup(nil)
+			return
+		}
+	}
+}
+
+// update sends the current funding of all contracts on the channel. Failing contracts are skipped until the next tick.
+func (s *FundingScraper) update() {
+	for _, contract := range s.contracts {
+		f, err := s.api.fundingRate(contract)
+		if err != nil {
+			log.Errorf("get funding rate of %s on %s: %v", contract, s.exchange, err)
+			continue
+		}
+		f.Exchange = s.exchange
+		f.Contract = contract
+		if f.Time.IsZero() {
+			f.Time = time.Now()
+		}
+		select {
+		case s.chanFundingRate <- f:
+		case <-s.shutdown:
+			return
+		}
+	}
+}
+
+// closes all connected Scrapers. Must only be called from mainLoop
+func (s *FundingScraper) cleanup(err error) {
+
+	s.errorLock.Lock()
+	defer s.errorLock.Unlock()
+
+	s.ticker.Stop()
+
+	if err != nil {
+		s.error = err
+	}
+	s.closed = true
+
+	close(s.shutdownDone) // signal that shutdown is complete
+}
+
+// Close closes any existing API connections
+func (s *FundingScraper) Close() error {
+	if s.closed {
+		return errors.New("FundingScraper: Already closed")
+	}
+	close(s.shutdown)
+	<-s.shutdownDone
+	s.errorLock.RLock()
+	defer s.errorLock.RUnlock()
+	return s.error
+}
+
+// Channel returns a channel that can be used to receive funding rates
+func (s *FundingScraper) Channel() chan *dia.FundingRate {
+	return s.chanFundingRate
+}
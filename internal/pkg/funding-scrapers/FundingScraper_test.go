@@ -0,0 +1,64 @@
+package fundingscrapers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// responses of the exchange APIs by request path, as sent by the exchanges
+var fundingResponses = map[string]string{
+	"/bitmex/instrument": `[{"symbol":"XBTUSD","underlying":"XBT","quoteCurrency":"USD","isInverse":true,"fundingRate":0.0001,
+		"indicativeFundingRate":0.00015,"fundingTimestamp":"2020-01-02T04:00:00.000Z","fundingInterval":"2000-01-01T08:00:00.000Z",
+		"openInterest":600000000,"markPrice":7000,"timestamp":"2020-01-02T03:04:05.000Z"}]`,
+	"/deribit/public/ticker": `{"jsonrpc":"2.0","result":{"instrument_name":"BTC-PERPETUAL","funding_8h":0.0002,"current_funding":0.00001,
+		"open_interest":100000000,"mark_price":7001,"timestamp":1577934245000}}`,
+	"/ftx/futures/BTC-PERP":       `{"success":true,"result":{"underlying":"BTC","perpetual":true,"mark":7002,"openInterest":10000,"openInterestUsd":70020000}}`,
+	"/ftx/futures/BTC-PERP/stats": `{"success":true,"result":{"nextFundingRate":0.00002,"nextFundingTime":"2020-01-02T04:00:00+00:00"}}`,
+	"/ftx/funding_rates":          `{"success":true,"result":[{"future":"BTC-PERP","rate":0.00003,"time":"2020-01-02T03:00:00+00:00"},{"future":"BTC-PERP","rate":0.1,"time":"2020-01-02T02:00:00+00:00"}]}`,
+	"/huobi/swap_funding_rate": `{"status":"ok","data":{"symbol":"BTC","contract_code":"BTC-USD","funding_rate":"0.0001","estimated_rate":"-0.0002",
+		"funding_time":"1577952000000"},"ts":1577934245000}`,
+	"/huobi/swap_open_interest": `{"status":"ok","data":[{"symbol":"BTC","contract_code":"BTC-USD","volume":5000,"amount":71.4}],"ts":1577934245000}`,
+	"/huobi/swap_contract_info": `{"status":"ok","data":[{"symbol":"BTC","contract_code":"BTC-USD","contract_size":100}],"ts":1577934245000}`,
+}
+
+func TestFundingRates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response, ok := fundingResponses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(response))
+	}))
+	defer server.Close()
+	bitmexAPI, deribitAPI, ftxAPI, huobiAPI = server.URL+"/bitmex", server.URL+"/deribit", server.URL+"/ftx", server.URL+"/huobi"
+
+	tests := []struct {
+		api                   fundingAPI
+		contract              string
+		rate, predicted       float64
+		interval              time.Duration
+		openInterestUSD       float64
+		fundingTime, observed time.Time
+	}{
+		{bitmexFunding{}, "XBTUSD", 0.0001, 0.00015, 8 * time.Hour, 600000000, time.Date(2020, 1, 2, 4, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)},
+		{deribitFunding{}, "BTC-PERPETUAL", 0.0002, 0.00001, 8 * time.Hour, 100000000, time.Unix(1577934245, 0), time.Unix(1577934245, 0)},
+		{ftxFunding{}, "BTC-PERP", 0.00003, 0.00002, time.Hour, 70020000, time.Date(2020, 1, 2, 3, 0, 0, 0, time.UTC), time.Time{}},
+		{newHuobiFunding(), "BTC-USD", 0.0001, -0.0002, 8 * time.Hour, 500000, time.Unix(1577952000, 0), time.Unix(1577934245, 0)},
+	}
+	for _, test := range tests {
+		f, err := test.api.fundingRate(test.contract)
+		if err != nil {
+			t.Errorf("%s: %v", test.contract, err)
+			continue
+		}
+		if f.Underlying != "BTC" || f.FundingRate != test.rate || f.PredictedFundingRate != test.predicted || f.FundingInterval != test.interval || f.OpenInterestUSD != test.openInterestUSD {
+			t.Errorf("%s: got %+v", test.contract, f)
+		}
+		if !f.FundingTime.Equal(test.fundingTime) || !f.Time.Equal(test.observed) {
+			t.Errorf("%s: got funding time %v and time %v, want %v and %v", test.contract, f.FundingTime, f.Time, test.fundingTime, test.observed)
+		}
+	}
+}
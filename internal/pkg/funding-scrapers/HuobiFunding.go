@@ -0,0 +1,121 @@
+package fundingscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	utils "github.com/diadata-org/diadata/pkg/utils"
+)
+
+var huobiAPI = "https://api.hbdm.com/swap-api/v1"
+
+// huobiFunding fetches the funding of Huobi's coin margined swaps, e.g. BTC-USD.
+type huobiFunding struct {
+	// contract sizes in USD, which do not change
+	contractSizes map[string]float64
+}
+
+type huobiResponse struct {
+	Status string          `json:"status"`
+	ErrMsg string          `json:"err_msg"`
+	Data   json.RawMessage `json:"data"`
+	Ts     int64           `json:"ts"`
+}
+
+type huobiFundingRate struct {
+	Symbol        string `json:"symbol"`
+	FundingRate   string `json:"funding_rate"`
+	EstimatedRate string `json:"estimated_rate"`
+	FundingTime   string `json:"funding_time"`
+}
+
+type huobiOpenInterest struct {
+	Volume float64 `json:"volume"` // in contracts
+}
+
+type huobiContractInfo struct {
+	ContractSize float64 `json:"contract_size"`
+}
+
+func newHuobiFunding() *huobiFunding {
+	return &huobiFunding{contractSizes: make(map[string]float64)}
+}
+
+// fundingRate of a Huobi swap, which pays funding every 8 hours.
+func (h *huobiFunding) fundingRate(contract string) (*dia.FundingRate, error) {
+	var funding huobiFundingRate
+	ts, err := huobiGet("/swap_funding_rate?contract_code="+contract, &funding)
+	if err != nil {
+		return nil, err
+	}
+	var openInterest []huobiOpenInterest
+	_, err = huobiGet("/swap_open_interest?contract_code="+contract, &openInterest)
+	if err != nil {
+		return nil, err
+	}
+	if len(openInterest) == 0 {
+		return nil, errors.New("no open interest for " + contract)
+	}
+	size, err := h.contractSize(contract)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &dia.FundingRate{
+		Underlying:      funding.Symbol,
+		FundingInterval: 8 * time.Hour,
+		OpenInterest:    openInterest[0].Volume,
+		OpenInterestUSD: openInterest[0].Volume * size,
+		Time:            time.Unix(0, ts*1e6),
+	}
+	f.FundingRate, err = strconv.ParseFloat(funding.FundingRate, 64)
+	if err != nil {
+		return nil, err
+	}
+	f.PredictedFundingRate, err = strconv.ParseFloat(funding.EstimatedRate, 64)
+	if err != nil {
+		return nil, err
+	}
+	fundingTime, err := strconv.ParseInt(funding.FundingTime, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	f.FundingTime = time.Unix(0, fundingTime*1e6)
+	return f, nil
+}
+
+func (h *huobiFunding) contractSize(contract string) (float64, error) {
+	if size, ok := h.contractSizes[contract]; ok {
+		return size, nil
+	}
+	var info []huobiContractInfo
+	_, err := huobiGet("/swap_contract_info?contract_code="+contract, &info)
+	if err != nil {
+		return 0, err
+	}
+	if len(info) == 0 {
+		return 0, errors.New("unknown contract " + contract)
+	}
+	h.contractSizes[contract] = info[0].ContractSize
+	return info[0].ContractSize, nil
+}
+
+// huobiGet unmarshals the data of the API @path into @data and returns the timestamp of the response in milliseconds.
+func huobiGet(path string, data interface{}) (int64, error) {
+	body, err := utils.GetRequest(huobiAPI + path)
+	if err != nil {
+		return 0, err
+	}
+	var response huobiResponse
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return 0, err
+	}
+	if response.Status != "ok" {
+		return 0, errors.New(response.ErrMsg)
+	}
+	return response.Ts, json.Unmarshal(response.Data, data)
+}
@@ -37,6 +37,7 @@ type OKExInstrumentDetail struct {
 }
 
 type OKExOptionsScraper struct {
+	*optionsScraperBase
 	Markets []string
 
 	// OKEx Options endpoint is a REST one and you are limited to 20 requests per 2 seconds. So you have
@@ -47,8 +48,9 @@ type OKExOptionsScraper struct {
 	ScraperIsRunningMu sync.Mutex
 	optionsWaitGroup   *sync.WaitGroup
 	DataStore          *models.DB
-	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
+	// ctx is canceled on Close to stop waiting for the rate limiter
+	ctx context.Context
 }
 
 type AllOKExOptionsScrapers struct {
@@ -115,12 +117,15 @@ func NewOKExOptionsScraper(pollFreq int8) *OKExOptionsScraper {
 
 	}
 	rl := rate.NewLimiter(rate.Every(2*time.Second), 10) // 10 request every 2 seconds
+	ctx, cancel := context.WithCancel(context.Background())
 	optionsScraper := &OKExOptionsScraper{
-		PollFrequency: pollFreq,
-		DataStore:     ds,
-		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
-		Ratelimiter:   rl, // if pollFreq = 1 second. can have 10 goroutines at the same time
+		optionsScraperBase: newOptionsScraperBase(),
+		PollFrequency:      pollFreq,
+		DataStore:          ds,
+		Ratelimiter:        rl, // if pollFreq = 1 second. can have 10 goroutines at the same time
+		ctx:                ctx,
 	}
+	optionsScraper.cleanup = cancel
 	optionsScraper.GetAndStoreOptionsMeta()
 	return optionsScraper
 }
@@ -211,19 +216,35 @@ func (s *OKExOptionsScraper) FetchInstruments() {
 func (s *OKExOptionsScraper) Scrape() {
 
 	for _, market := range s.Markets {
-		go s.ScrapeInstrument(market)
-
+		market := market
+		s.run(func() { s.pollInstrument(market) })
 	}
 
 }
 
-func (s *OKExOptionsScraper) ScrapeInstrument(market string) {
+// pollInstrument scrapes the order book of @market every PollFrequency seconds until the scraper is closed.
+func (s *OKExOptionsScraper) pollInstrument(market string) {
+	ticker := time.NewTicker(time.Duration(s.PollFrequency) * time.Second)
+	defer ticker.Stop()
+	for s.ScrapeInstrument(market) {
+		select {
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ScrapeInstrument sends the current order book datum of @market. It returns false once the scraper is shutting down.
+func (s *OKExOptionsScraper) ScrapeInstrument(market string) bool {
 
-	ctx := context.Background()
-	err := s.Ratelimiter.Wait(ctx) // This is a blocking call. Honors the rate limit
+	err := s.Ratelimiter.Wait(s.ctx) // This is a blocking call. Honors the rate limit
 	if err != nil {
-		log.Errorln("Error on ratelimit", err)
-		return
+		if s.isShutdown() {
+			return false
+		}
+		s.reportError(fmt.Errorf("okex rate limit for %s: %v", market, err))
+		return true
 	}
 
 	logger.Formatter = new(prefixed.TextFormatter)
@@ -235,27 +256,26 @@ func (s *OKExOptionsScraper) ScrapeInstrument(market string) {
 	// * change size query param to larger number for greater depth. the largest you can go to is 200
 	body, err := utils.GetRequest(url)
 	if err != nil {
-		logger.WithFields(logrus.Fields{"prefix": "OKEx", "market": market}).Error(err)
-		return
+		s.reportError(fmt.Errorf("okex order book of %s: %v", market, err))
+		return true
 	}
 
 	err = json.Unmarshal(body, &rawOB)
 	if err != nil {
-		logger.WithFields(logrus.Fields{"prefix": "OKEx", "market": market}).Error(err)
-		return
+		s.reportError(fmt.Errorf("okex order book of %s: %v", market, err))
+		return true
 	}
 
 	var obEntry dia.OptionOrderbookDatum
 	obEntry, err = s.parseObDatum(&rawOB, market)
 	if err != nil {
-		logger.WithFields(logrus.Fields{"prefix": "OKEx", "market": market}).Error(err)
-		return
+		s.reportError(fmt.Errorf("okex order book of %s: %v", market, err))
+		return true
 	}
 
-	log.Errorln("obEntry", obEntry)
-
-	s.chanOrderBook <- &obEntry
+	log.Debugln("obEntry", obEntry)
 
+	return s.publish(&obEntry)
 }
 
 func (s *OKExOptionsScraper) MetaOnOptionIsAvailable(option OKExInstrument) (available bool, err error) {
@@ -333,6 +353,3 @@ func (s *OKExOptionsScraper) GetAndStoreOptionsMeta() (err error) {
 	return nil
 }
 
-func (s *OKExOptionsScraper) Channel() chan *dia.OptionOrderbookDatum {
-	return s.chanOrderBook
-}
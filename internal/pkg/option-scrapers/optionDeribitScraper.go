@@ -2,6 +2,9 @@ package optionscrapers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
@@ -45,6 +48,7 @@ type DeribitInstrument struct {
 }
 
 type DeribitETHOptionScraper struct {
+	*optionsScraperBase
 	markets            []string
 	wsClient           *ws.Conn
 	PollFrequency      int8
@@ -52,7 +56,6 @@ type DeribitETHOptionScraper struct {
 	ScraperIsRunningMu sync.Mutex
 	optionsWaitGroup   *sync.WaitGroup
 	DataStore          *models.DB
-	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
 	refreshToken       string
 }
@@ -77,8 +80,8 @@ func NewDeribitETHOptionScraper() *DeribitETHOptionScraper {
 	}
 
 	s := &DeribitETHOptionScraper{
-		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
-		DataStore:     ds,
+		optionsScraperBase: newOptionsScraperBase(),
+		DataStore:          ds,
 	}
 	s.GetAndStoreOptionsMeta()
 
@@ -86,15 +89,20 @@ func NewDeribitETHOptionScraper() *DeribitETHOptionScraper {
 	SwConn, _, err := wsDialer.Dial(deribitAPI, nil)
 
 	if err != nil {
-		println(err.Error())
+		s.reportError(fmt.Errorf("dial %s: %v", deribitAPI, err))
 	}
 	s.wsClient = SwConn
+	s.cleanup = func() {
+		if s.wsClient != nil {
+			s.wsClient.Close()
+		}
+	}
 
 	return s
 
 }
 
-func (scraper *DeribitETHOptionScraper) subscribe() {
+func (scraper *DeribitETHOptionScraper) subscribe() error {
 
 	id := 0
 	request := &DeribitRequest{
@@ -111,9 +119,7 @@ func (scraper *DeribitETHOptionScraper) subscribe() {
 
 	request.Params = params
 	log.Info("Subscribing to Instrument ", request)
-	scraper.wsClient.WriteJSON(request)
-	id++
-
+	return scraper.wsClient.WriteJSON(request)
 }
 
 type DeribitOptionResponse struct {
@@ -133,15 +139,22 @@ type DeribitOptionResponse struct {
 	} `json:"params"`
 }
 
-func (scraper *DeribitETHOptionScraper) handleWSMessage() {
+// handleWSMessage reads a message and sends its order book datum. It returns false once the
+// connection is lost or the scraper is shutting down.
+func (scraper *DeribitETHOptionScraper) handleWSMessage() bool {
 	var response DeribitOptionResponse
 	var err error
 
 	err = scraper.wsClient.ReadJSON(&response)
 	if err != nil {
-		log.Errorln("Error reading wsclient", err)
-		return
-
+		if !scraper.isShutdown() {
+			scraper.reportError(fmt.Errorf("read deribit websocket: %v", err))
+		}
+		return false
+	}
+	// heartbeats and responses to requests
+	if response.Params.Data.InstrumentName == "" {
+		return true
 	}
 
 	var (
@@ -185,7 +198,7 @@ func (scraper *DeribitETHOptionScraper) handleWSMessage() {
 	}
 	log.Infoln("Got trade", o)
 
-	scraper.chanOrderBook <- &o
+	return scraper.publish(&o)
 
 	//optionMeta := dia.OptionMeta{
 	//	InstrumentName: response.Params.Data.InstrumentName,
@@ -199,24 +212,30 @@ func (scraper *DeribitETHOptionScraper) handleWSMessage() {
 
 }
 func (scraper *DeribitETHOptionScraper) Scrape() {
-
-	go scraper.heartBeat()
-	scraper.subscribe()
-	go func() {
-		for {
-			scraper.handleWSMessage()
-
+	if scraper.wsClient == nil {
+		scraper.reportError(errors.New("no connection to deribit"))
+		return
+	}
+	err := scraper.subscribe()
+	if err != nil {
+		scraper.reportError(fmt.Errorf("subscribe to deribit: %v", err))
+		return
+	}
+	scraper.run(scraper.heartBeat)
+	scraper.run(func() {
+		for scraper.handleWSMessage() {
 		}
-
-	}()
-
+	})
 }
 
 func (scraper *DeribitETHOptionScraper) heartBeat() {
 
 	t := time.NewTicker(3 * time.Second)
+	defer t.Stop()
 	for {
 		select {
+		case <-scraper.shutdown:
+			return
 		case <-t.C:
 			{
 				var params map[string]int
@@ -231,8 +250,11 @@ func (scraper *DeribitETHOptionScraper) heartBeat() {
 				params["interval"] = 30
 
 				request.Params = params
-				log.Errorln("set_heartbeat ", request)
-				scraper.wsClient.WriteJSON(request)
+				log.Debugln("set_heartbeat ", request)
+				if err := scraper.wsClient.WriteJSON(request); err != nil {
+					scraper.reportError(fmt.Errorf("send deribit heartbeat: %v", err))
+					return
+				}
 				id++
 
 				request = &DeribitRequest{
@@ -241,8 +263,11 @@ func (scraper *DeribitETHOptionScraper) heartBeat() {
 					Method:  "public/test",
 				}
 
-				log.Errorln("public/test ", request)
-				scraper.wsClient.WriteJSON(request)
+				log.Debugln("public/test ", request)
+				if err := scraper.wsClient.WriteJSON(request); err != nil {
+					scraper.reportError(fmt.Errorf("send deribit test request: %v", err))
+					return
+				}
 				id++
 			}
 		}
@@ -367,7 +392,3 @@ func (scraper *DeribitETHOptionScraper) GetAndStoreOptionsMeta() (err error) {
 
 	return nil
 }
-
-func (scraper *DeribitETHOptionScraper) Channel() chan *dia.OptionOrderbookDatum {
-	return scraper.chanOrderBook
-}
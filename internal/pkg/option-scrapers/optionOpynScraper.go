@@ -2,6 +2,7 @@ package optionscrapers
 
 import (
 	"encoding/json"
+	"fmt"
 	Otoken "github.com/diadata-org/diadata/internal/pkg/option-scrapers/opyncontracts/OpynToken"
 	"strconv"
 	"sync"
@@ -18,6 +19,9 @@ import (
 )
 
 const (
+	// time between two polls of the 0x order books of the oTokens
+	opynPollInterval = time.Minute
+
 	wsDial   = "wss://eth-mainnet.ws.alchemyapi.io/v2/GwTfLo_j5DW_GjJSr_XiEwPiYaEmBJvX"
 	restDial = "https://eth-mainnet.alchemyapi.io/v2/GwTfLo_j5DW_GjJSr_XiEwPiYaEmBJvX"
 )
@@ -97,6 +101,7 @@ type OpynInstrumentsResponse struct {
 }
 
 type OpynOptionScraper struct {
+	*optionsScraperBase
 	markets    []OptionAttrs
 	WsClient   *ethclient.Client
 	RestClient *ethclient.Client
@@ -106,7 +111,6 @@ type OpynOptionScraper struct {
 	ScraperIsRunningMu sync.Mutex
 	optionsWaitGroup   *sync.WaitGroup
 	DataStore          *models.DB
-	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
 }
 
@@ -131,10 +135,14 @@ func NewOpynETHOptionScraper() *OpynOptionScraper {
 	}
 
 	s := &OpynOptionScraper{
-		WsClient:      wsClient,
-		RestClient:    restClient,
-		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
-		DataStore:     ds,
+		optionsScraperBase: newOptionsScraperBase(),
+		WsClient:           wsClient,
+		RestClient:         restClient,
+		DataStore:          ds,
+	}
+	s.cleanup = func() {
+		wsClient.Close()
+		restClient.Close()
 	}
 	s.GetAndStoreOptionsMeta()
 
@@ -147,66 +155,85 @@ func NewOpynETHOptionScraper() *OpynOptionScraper {
 
 
 func (scraper *OpynOptionScraper) Scrape() {
-
-	go func() {
-		for _, market := range scraper.markets {
-			log.Infoln("Token Address", market.id)
-
-			var response OpynInstrumentsResponse
-
-			b, err := utils.GetRequest("https://api.0x.org/sra/v4/orderbook?baseToken=" + market.id + "&quoteToken=0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48&perPage=100")
-			if err != nil {
-				log.Errorln("Error", err)
+	scraper.run(func() {
+		ticker := time.NewTicker(opynPollInterval)
+		defer ticker.Stop()
+		for scraper.scrapeOrderbooks() {
+			select {
+			case <-scraper.shutdown:
+				return
+			case <-ticker.C:
 			}
-			json.Unmarshal(b, &response)
-
-			var (
-				resolvedAskPX, resolvedAskSize, resolvedBidSize, resolvedBidPX float64
-			)
-
-			for _, askOut := range response.Asks.Records {
-				log.Errorln("response.Asks.Records", len(response.Asks.Records))
-
-				makeAmount, _ := strconv.ParseFloat(askOut.Order.MakerAmount, 64)
-				takerAmount, _ := strconv.ParseFloat(askOut.Order.TakerAmount, 64)
+		}
+	})
+}
 
-				resolvedAskSize = makeAmount / 1e8
-				resolvedAskPX = (takerAmount / 1e6) / (makeAmount / 1e8)
+// scrapeOrderbooks sends the orders of all oTokens on 0x. It returns false once the scraper is shutting down.
+func (scraper *OpynOptionScraper) scrapeOrderbooks() bool {
+	for _, market := range scraper.markets {
+		log.Infoln("Token Address", market.id)
 
-				var o = dia.OptionOrderbookDatum{
-					InstrumentName:  market.id,
-					ObservationTime: market.expiryDate,
-					AskSize:         resolvedAskSize,
-					AskPrice:        resolvedAskPX,
-					BidPrice:        resolvedBidPX,
-					BidSize:         resolvedBidSize,
-				}
-				log.Infoln("Got trade", o)
-				scraper.chanOrderBook <- &o
+		var response OpynInstrumentsResponse
 
+		b, err := utils.GetRequest("https://api.0x.org/sra/v4/orderbook?baseToken=" + market.id + "&quoteToken=0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48&perPage=100")
+		if err != nil {
+			scraper.reportError(fmt.Errorf("0x order book of %s: %v", market.id, err))
+			continue
+		}
+		err = json.Unmarshal(b, &response)
+		if err != nil {
+			scraper.reportError(fmt.Errorf("0x order book of %s: %v", market.id, err))
+			continue
+		}
+		observationTime := time.Now()
+
+		var (
+			resolvedAskPX, resolvedAskSize, resolvedBidSize, resolvedBidPX float64
+		)
+
+		for _, askOut := range response.Asks.Records {
+			makeAmount, _ := strconv.ParseFloat(askOut.Order.MakerAmount, 64)
+			takerAmount, _ := strconv.ParseFloat(askOut.Order.TakerAmount, 64)
+
+			resolvedAskSize = makeAmount / 1e8
+			resolvedAskPX = (takerAmount / 1e6) / (makeAmount / 1e8)
+
+			var o = dia.OptionOrderbookDatum{
+				InstrumentName:  market.id,
+				ObservationTime: observationTime,
+				AskSize:         resolvedAskSize,
+				AskPrice:        resolvedAskPX,
+				BidPrice:        resolvedBidPX,
+				BidSize:         resolvedBidSize,
 			}
-			for _, bidOut := range response.Bids.Records {
-				makeAmount, _ := strconv.ParseFloat(bidOut.Order.MakerAmount, 64)
-				takerAmount, _ := strconv.ParseFloat(bidOut.Order.TakerAmount, 64)
-
-				resolvedBidSize = takerAmount / 1e8
-				resolvedBidPX = (makeAmount / 1e6) / (takerAmount / 1e8)
-
-				var o = dia.OptionOrderbookDatum{
-					InstrumentName:  market.id,
-					ObservationTime: market.expiryDate,
-					BidPrice:        resolvedBidPX,
-					BidSize:         resolvedBidSize,
-				}
-				log.Infoln("Got trade", o)
-				scraper.chanOrderBook <- &o
-
+			log.Infoln("Got trade", o)
+			if !scraper.publish(&o) {
+				return false
 			}
 
 		}
+		for _, bidOut := range response.Bids.Records {
+			makeAmount, _ := strconv.ParseFloat(bidOut.Order.MakerAmount, 64)
+			takerAmount, _ := strconv.ParseFloat(bidOut.Order.TakerAmount, 64)
+
+			resolvedBidSize = takerAmount / 1e8
+			resolvedBidPX = (makeAmount / 1e6) / (takerAmount / 1e8)
+
+			var o = dia.OptionOrderbookDatum{
+				InstrumentName:  market.id,
+				ObservationTime: observationTime,
+				BidPrice:        resolvedBidPX,
+				BidSize:         resolvedBidSize,
+			}
+			log.Infoln("Got trade", o)
+			if !scraper.publish(&o) {
+				return false
+			}
 
-	}()
+		}
 
+	}
+	return true
 }
 
 func (scraper *OpynOptionScraper) getOPYNInstruments() (options []OptionAttrs) {
@@ -331,7 +358,3 @@ func (scraper *OpynOptionScraper) GetAndStoreOptionsMeta() (err error) {
 
 	return nil
 }
-
-func (scraper *OpynOptionScraper) Channel() chan *dia.OptionOrderbookDatum {
-	return scraper.chanOrderBook
-}
@@ -1,6 +1,7 @@
 package optionscrapers
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"sync"
@@ -14,6 +15,7 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/event"
 	"golang.org/x/time/rate"
 )
 
@@ -30,6 +32,7 @@ type OptionAttrs struct {
 }
 
 type PremiaScraper struct {
+	*optionsScraperBase
 	markets    []OptionAttrs
 	WsClient   *ethclient.Client
 	RestClient *ethclient.Client
@@ -39,7 +42,6 @@ type PremiaScraper struct {
 	ScraperIsRunningMu sync.Mutex
 	optionsWaitGroup   *sync.WaitGroup
 	DataStore          *models.DB
-	chanOrderBook      chan *dia.OptionOrderbookDatum
 	Ratelimiter        *rate.Limiter
 }
 
@@ -63,10 +65,14 @@ func NewPremiaETHOptionScraper() *PremiaScraper {
 	}
 
 	s := &PremiaScraper{
-		WsClient:      wsClient,
-		RestClient:    restClient,
-		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
-		DataStore:     ds,
+		optionsScraperBase: newOptionsScraperBase(),
+		WsClient:           wsClient,
+		RestClient:         restClient,
+		DataStore:          ds,
+	}
+	s.cleanup = func() {
+		wsClient.Close()
+		restClient.Close()
 	}
 	//s.GetAndStoreOptionsMeta()
 
@@ -76,81 +82,71 @@ func NewPremiaETHOptionScraper() *PremiaScraper {
 
 }
 
-func (scrapper *PremiaScraper) subscribe() (chan *PremiaMarket.PremiaMarketOrderCreated, error) {
+func (scrapper *PremiaScraper) subscribe() (chan *PremiaMarket.PremiaMarketOrderCreated, event.Subscription, error) {
 
 	sink := make(chan *PremiaMarket.PremiaMarketOrderCreated)
 
-	PremiaMarket.NewPremiaMarketFilterer(PremiaMarketAddress, scrapper.WsClient)
-
 	optionFilterer, err := PremiaMarket.NewPremiaMarketFilterer(PremiaMarketAddress, scrapper.WsClient)
 	if err != nil {
-		log.Fatal(err)
+		return nil, nil, err
 	}
 	var hash [][32]byte
 
 	subscribed, err := optionFilterer.WatchOrderCreated(&bind.WatchOpts{}, sink, hash, []common.Address{}, []common.Address{})
 	if err != nil {
-		log.Error("error in get watching channel: ", err)
+		return nil, nil, err
 	}
 
 	log.Infoln("subscribed", subscribed)
 
-	return sink, nil
+	return sink, subscribed, nil
 }
 
 func (scrapper *PremiaScraper) Scrape() {
 
 	log.Infoln("Scrape")
 
-	sink, _ := scrapper.subscribe()
-
-	go func() {
+	sink, sub, err := scrapper.subscribe()
+	if err != nil {
+		scrapper.reportError(fmt.Errorf("subscribe to premia orders: %v", err))
+		return
+	}
 
+	scrapper.run(func() {
+		defer sub.Unsubscribe()
 		for {
-			orderCreated, ok := <-sink
-			if ok {
-
-				var (
-					resolvedAskPX, resolvedAskSize, resolvedBidSize, resolvedBidPX float64
-				)
-
-				if orderCreated.Side == 0 {
-
-					resolvedAskSize = float64(orderCreated.PricePerUnit.Int64())
-					resolvedAskPX = float64(orderCreated.Amount.Int64()) / math.Exp(float64(orderCreated.Decimals))
-
-					var o = dia.OptionOrderbookDatum{
-						InstrumentName: orderCreated.OptionContract.String(),
-						ExpirationTime: time.Unix(orderCreated.ExpirationTime.Int64(), 0),
-						AskSize:        resolvedAskSize,
-						AskPrice:       resolvedAskPX,
-						BidPrice:       resolvedBidPX,
-						BidSize:        resolvedBidSize,
-					}
-					log.Println("Got trade", o)
-					scrapper.chanOrderBook <- &o
-
-				} else {
-					resolvedBidSize = float64(orderCreated.PricePerUnit.Int64())
-					resolvedBidPX = float64(orderCreated.Amount.Int64()) / math.Exp(float64(orderCreated.Decimals))
-
-					var o = dia.OptionOrderbookDatum{
-						InstrumentName: orderCreated.OptionContract.String(),
-						ExpirationTime: time.Unix(orderCreated.ExpirationTime.Int64(), 0),
-						BidPrice:       resolvedBidPX,
-						BidSize:        resolvedBidSize,
-					}
-					log.Println("Got trade", o)
-					scrapper.chanOrderBook <- &o
-
+			select {
+			case <-scrapper.shutdown:
+				return
+			case err := <-sub.Err():
+				scrapper.reportError(fmt.Errorf("premia order subscription: %v", err))
+				return
+			case orderCreated := <-sink:
+				if !scrapper.publish(premiaOrderbookDatum(orderCreated)) {
+					return
 				}
-
 			}
-
 		}
+	})
 
-	}()
+}
 
+// premiaOrderbookDatum returns the side of the order book of the option given by a new order.
+func premiaOrderbookDatum(orderCreated *PremiaMarket.PremiaMarketOrderCreated) *dia.OptionOrderbookDatum {
+	o := dia.OptionOrderbookDatum{
+		InstrumentName:  orderCreated.OptionContract.String(),
+		ObservationTime: time.Now(),
+		ExpirationTime:  time.Unix(orderCreated.ExpirationTime.Int64(), 0),
+	}
+	if orderCreated.Side == 0 {
+		o.AskSize = float64(orderCreated.PricePerUnit.Int64())
+		o.AskPrice = float64(orderCreated.Amount.Int64()) / math.Exp(float64(orderCreated.Decimals))
+	} else {
+		o.BidSize = float64(orderCreated.PricePerUnit.Int64())
+		o.BidPrice = float64(orderCreated.Amount.Int64()) / math.Exp(float64(orderCreated.Decimals))
+	}
+	log.Println("Got trade", o)
+	return &o
 }
 
 func (scrapper *PremiaScraper) FetchMarkets() {
@@ -223,7 +219,3 @@ func (scrapper *PremiaScraper) FetchInstruments() {
 
 // 	return
 // }
-
-func (scrapper *PremiaScraper) Channel() chan *dia.OptionOrderbookDatum {
-	return scrapper.chanOrderBook
-}
@@ -1,6 +1,10 @@
 package optionscrapers
 
 import (
+	"errors"
+	"io"
+	"sync"
+
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/sirupsen/logrus"
 )
@@ -13,8 +17,8 @@ var blockchains map[string]dia.BlockChain
 func init() {
 
 	blockchains = make(map[string]dia.BlockChain)
-	blockchains[dia.Bitcoin] = dia.BlockChain{Name: dia.BinanceExchange, NativeToken: "BTC", VerificationMechanism: dia.PROOF_OF_WORK}
-	blockchains[dia.Ethereum] = dia.BlockChain{Name: dia.BinanceExchange, NativeToken: "ETH", VerificationMechanism: dia.PROOF_OF_WORK}
+	blockchains[dia.Bitcoin] = dia.BlockChain{Name: dia.Bitcoin, NativeToken: "BTC", VerificationMechanism: dia.PROOF_OF_WORK}
+	blockchains[dia.Ethereum] = dia.BlockChain{Name: dia.Ethereum, ChainID: 1, NativeToken: "ETH", VerificationMechanism: dia.PROOF_OF_WORK}
 	// TODO move all this to single json
 	Exchanges = make(map[string]dia.Exchange)
 	Exchanges[dia.OKExExchange] = dia.Exchange{Name: dia.OKExExchange, Centralized: true}
@@ -27,11 +31,118 @@ func init() {
 /* OptionsScraper provides common methods needed to get Option orderBook information from
 exchange APIs.*/
 type OptionsScraper interface {
-	//io.Closer
+	// Close stops the scraper, closes its channels and returns its last error
+	io.Closer
 	FetchInstruments()
 	Scrape()
-	// Channel returns a channel that can be used to receive trades
+	// Channel returns a channel that can be used to receive order book data
 	Channel() chan *dia.OptionOrderbookDatum
+	// Errors returns a channel on which errors of the scraper are reported. Errors are
+	// dropped while the channel is not read.
+	Errors() chan error
+}
+
+const errorChannelSize = 16
+
+type nothing struct{}
+
+// optionsScraperBase implements the lifecycle shared by the OptionsScrapers. Goroutines started
+// with run are stopped on Close, publishing order book data never blocks a closing scraper.
+type optionsScraperBase struct {
+	chanOrderBook chan *dia.OptionOrderbookDatum
+	chanError     chan error
+	shutdown      chan nothing
+	// cleanup is called on Close after shutdown was signaled, e.g. to unblock reads on a connection
+	cleanup   func()
+	wg        sync.WaitGroup
+	closeLock sync.Mutex
+	closed    bool
+	errorLock sync.RWMutex
+	error     error
+}
+
+func newOptionsScraperBase() *optionsScraperBase {
+	return &optionsScraperBase{
+		chanOrderBook: make(chan *dia.OptionOrderbookDatum),
+		chanError:     make(chan error, errorChannelSize),
+		shutdown:      make(chan nothing),
+	}
+}
+
+// run runs @f in a goroutine which is waited for on Close.
+func (b *optionsScraperBase) run(f func()) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		f()
+	}()
+}
+
+// publish sends @datum on the order book channel. It returns false if the scraper is shutting down.
+func (b *optionsScraperBase) publish(datum *dia.OptionOrderbookDatum) bool {
+	select {
+	case b.chanOrderBook <- datum:
+		return true
+	case <-b.shutdown:
+		return false
+	}
+}
+
+// isShutdown tells whether Close has been called.
+func (b *optionsScraperBase) isShutdown() bool {
+	select {
+	case <-b.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+// reportError logs @err, keeps it as the last error and sends it on the error channel if it has room.
+func (b *optionsScraperBase) reportError(err error) {
+	log.Error(err)
+	b.errorLock.Lock()
+	b.error = err
+	b.errorLock.Unlock()
+	select {
+	case b.chanError <- err:
+	default:
+	}
+}
+
+// Error returns the last error of the scraper.
+func (b *optionsScraperBase) Error() error {
+	b.errorLock.RLock()
+	defer b.errorLock.RUnlock()
+	return b.error
+}
+
+func (b *optionsScraperBase) Channel() chan *dia.OptionOrderbookDatum {
+	return b.chanOrderBook
+}
+
+func (b *optionsScraperBase) Errors() chan error {
+	return b.chanError
+}
+
+// Close stops all goroutines of the scraper, closes its channels and returns its last error.
+func (b *optionsScraperBase) Close() error {
+	b.closeLock.Lock()
+	if b.closed {
+		b.closeLock.Unlock()
+		return errors.New("options scraper already closed")
+	}
+	b.closed = true
+	b.closeLock.Unlock()
+
+	close(b.shutdown)
+	if b.cleanup != nil {
+		b.cleanup()
+	}
+	b.wg.Wait()
+	close(b.chanOrderBook)
+	close(b.chanError)
+	return b.Error()
 }
 
 func New(exchange string, key string, secret string) OptionsScraper {
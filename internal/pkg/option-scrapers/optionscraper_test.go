@@ -0,0 +1,41 @@
+package optionscrapers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestOptionsScraperBaseClose(t *testing.T) {
+	b := newOptionsScraperBase()
+	cleanedUp := false
+	b.cleanup = func() { cleanedUp = true }
+	// a goroutine blocked on publishing, as nobody reads the channel
+	b.run(func() {
+		for b.publish(&dia.OptionOrderbookDatum{InstrumentName: "BTC-25JUN21-40000-C"}) {
+		}
+	})
+	b.reportError(errors.New("connection lost"))
+	if err := <-b.Errors(); err == nil || err.Error() != "connection lost" {
+		t.Errorf("got error %v", err)
+	}
+
+	closed := make(chan error)
+	go func() { closed <- b.Close() }()
+	select {
+	case err := <-closed:
+		if err == nil || !cleanedUp {
+			t.Errorf("expected the last error and a cleanup, got %v, %v", err, cleanedUp)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("close blocked")
+	}
+	if _, ok := <-b.Channel(); ok {
+		t.Error("expected a closed order book channel")
+	}
+	if b.Close() == nil {
+		t.Error("expected error on second close")
+	}
+}
@@ -0,0 +1,146 @@
+package optionGreeksService
+
+import (
+	"errors"
+	"math"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+const (
+	minVolatility   = 1e-6
+	maxVolatility   = 20.0
+	maxIterations   = 100
+	priceTolerance  = 1e-12
+	volTolerance    = 1e-10
+	daysPerYear     = 365
+	volatilityPoint = 0.01
+)
+
+var (
+	// ErrNoImpliedVolatility is returned for prices outside of the no-arbitrage bounds.
+	ErrNoImpliedVolatility = errors.New("price violates the no-arbitrage bounds")
+	// ErrInvalidParameters is returned for non-positive spot, strike or time to expiry.
+	ErrInvalidParameters = errors.New("spot, strike and time to expiry must be positive")
+)
+
+// black76 is the Black-76 model of an option on the forward F = S*exp(r*T), which for
+// an underlying without yield is equivalent to Black-Scholes.
+type black76 struct {
+	optionType dia.OptionType
+	spot       float64
+	strike     float64
+	t          float64 // time to expiry in years
+	rate       float64
+}
+
+func newBlack76(optionType dia.OptionType, spot, strike, t, rate float64) (black76, error) {
+	if spot <= 0 || strike <= 0 || t <= 0 {
+		return black76{}, ErrInvalidParameters
+	}
+	if optionType != dia.CallOption && optionType != dia.PutOption {
+		return black76{}, errors.New("unknown option type")
+	}
+	return black76{optionType: optionType, spot: spot, strike: strike, t: t, rate: rate}, nil
+}
+
+func (m black76) discount() float64 {
+	return math.Exp(-m.rate * m.t)
+}
+
+func (m black76) forward() float64 {
+	return m.spot * math.Exp(m.rate*m.t)
+}
+
+func (m black76) d1d2(vol float64) (float64, float64) {
+	sqrtT := math.Sqrt(m.t)
+	d1 := (math.Log(m.forward()/m.strike) + vol*vol*m.t/2) / (vol * sqrtT)
+	return d1, d1 - vol*sqrtT
+}
+
+// price returns the premium of the option at volatility @vol.
+func (m black76) price(vol float64) float64 {
+	d1, d2 := m.d1d2(vol)
+	if m.optionType == dia.CallOption {
+		return m.discount() * (m.forward()*normCDF(d1) - m.strike*normCDF(d2))
+	}
+	return m.discount() * (m.strike*normCDF(-d2) - m.forward()*normCDF(-d1))
+}
+
+// bounds returns the no-arbitrage bounds of the premium.
+func (m black76) bounds() (lower float64, upper float64) {
+	if m.optionType == dia.CallOption {
+		return m.discount() * math.Max(m.forward()-m.strike, 0), m.spot
+	}
+	return m.discount() * math.Max(m.strike-m.forward(), 0), m.discount() * m.strike
+}
+
+// vega is the derivative of the price by the volatility.
+func (m black76) vega(vol float64) float64 {
+	d1, _ := m.d1d2(vol)
+	return m.spot * normPDF(d1) * math.Sqrt(m.t)
+}
+
+// impliedVolatility solves price(vol) = @premium with Newton's method, safeguarded by
+// bisection on a bracket of the root, which always converges as the price increases with vol.
+func (m black76) impliedVolatility(premium float64) (float64, error) {
+	lower, upper := m.bounds()
+	if premium <= lower || premium >= upper {
+		return 0, ErrNoImpliedVolatility
+	}
+	lo, hi := minVolatility, maxVolatility
+	if m.price(lo) > premium || m.price(hi) < premium {
+		return 0, ErrNoImpliedVolatility
+	}
+	// Brenner-Subrahmanyam approximation as initial guess
+	vol := math.Sqrt(2*math.Pi/m.t) * premium / m.spot
+	if vol <= lo || vol >= hi {
+		vol = 0.5
+	}
+	for i := 0; i < maxIterations; i++ {
+		diff := m.price(vol) - premium
+		if math.Abs(diff) < priceTolerance {
+			return vol, nil
+		}
+		if diff > 0 {
+			hi = vol
+		} else {
+			lo = vol
+		}
+		next := vol - diff/m.vega(vol)
+		if math.IsNaN(next) || next <= lo || next >= hi {
+			next = (lo + hi) / 2
+		}
+		if math.Abs(next-vol) < volTolerance {
+			return next, nil
+		}
+		vol = next
+	}
+	return vol, nil
+}
+
+// greeks returns delta, gamma, vega per volatility point and theta per calendar day at volatility @vol.
+func (m black76) greeks(vol float64) (delta, gamma, vega, theta float64) {
+	d1, d2 := m.d1d2(vol)
+	sqrtT := math.Sqrt(m.t)
+	gamma = normPDF(d1) / (m.spot * vol * sqrtT)
+	vega = m.vega(vol) * volatilityPoint
+	decay := -m.spot * normPDF(d1) * vol / (2 * sqrtT)
+	if m.optionType == dia.CallOption {
+		delta = normCDF(d1)
+		theta = decay - m.rate*m.strike*m.discount()*normCDF(d2)
+	} else {
+		delta = normCDF(d1) - 1
+		theta = decay + m.rate*m.strike*m.discount()*normCDF(-d2)
+	}
+	theta /= daysPerYear
+	return
+}
+
+func normCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+func normPDF(x float64) float64 {
+	return math.Exp(-x*x/2) / math.Sqrt(2*math.Pi)
+}
@@ -0,0 +1,91 @@
+package optionGreeksService
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestBlack76(t *testing.T) {
+	call, _ := newBlack76(dia.CallOption, 100, 100, 1, 0.05)
+	put, _ := newBlack76(dia.PutOption, 100, 100, 1, 0.05)
+	if p := call.price(0.2); math.Abs(p-10.4506) > 1e-4 {
+		t.Errorf("got call price %v", p)
+	}
+	if p := put.price(0.2); math.Abs(p-5.5735) > 1e-4 {
+		t.Errorf("got put price %v", p)
+	}
+
+	delta, gamma, vega, theta := call.greeks(0.2)
+	if math.Abs(delta-0.6368) > 1e-4 || math.Abs(gamma-0.018762) > 1e-6 || math.Abs(vega-0.37524) > 1e-5 || math.Abs(theta*daysPerYear+6.4140) > 1e-4 {
+		t.Errorf("got call greeks %v %v %v %v", delta, gamma, vega, theta)
+	}
+	delta, _, _, theta = put.greeks(0.2)
+	if math.Abs(delta+0.3632) > 1e-4 || math.Abs(theta*daysPerYear+1.6579) > 1e-4 {
+		t.Errorf("got put greeks %v %v", delta, theta)
+	}
+}
+
+func TestImpliedVolatility(t *testing.T) {
+	for _, optionType := range []dia.OptionType{dia.CallOption, dia.PutOption} {
+		for _, strike := range []float64{20, 80, 100, 125, 400} {
+			for _, vol := range []float64{0.05, 0.3, 1, 3} {
+				m, _ := newBlack76(optionType, 100, strike, 0.1, 0.01)
+				premium := m.price(vol)
+				iv, err := m.impliedVolatility(premium)
+				if err != nil {
+					// far from the money the premium has no time value left
+					if lower, _ := m.bounds(); premium-lower > 1e-8 {
+						t.Errorf("type %d strike %v vol %v premium %v: %v", optionType, strike, vol, premium, err)
+					}
+					continue
+				}
+				if math.Abs(m.price(iv)-premium) > 1e-8*math.Max(premium, 1) {
+					t.Errorf("type %d strike %v vol %v: got %v", optionType, strike, vol, iv)
+				}
+			}
+		}
+	}
+
+	m, _ := newBlack76(dia.CallOption, 100, 80, 0.1, 0.01)
+	for _, premium := range []float64{10, 100} {
+		if _, err := m.impliedVolatility(premium); err != ErrNoImpliedVolatility {
+			t.Errorf("premium %v outside of the bounds: got %v", premium, err)
+		}
+	}
+}
+
+func TestCompute(t *testing.T) {
+	now := time.Now()
+	meta := dia.OptionMeta{InstrumentName: "BTC-25JUN21-40000-C", BaseCurrency: "BTC", ExpirationTime: now.Add(30 * 24 * time.Hour), StrikePrice: 40000, OptionType: dia.CallOption}
+	m, _ := newBlack76(dia.CallOption, 38000, 40000, 30.0/daysPerYear, 0.01)
+	bid, ask := m.price(0.7), m.price(0.8)
+	// deribit quotes the premium in BTC
+	ob := dia.OptionOrderbookDatum{InstrumentName: meta.InstrumentName, ObservationTime: now, BidPrice: bid / 38000, AskPrice: ask / 38000}
+
+	g, err := Compute(meta, ob, 38000, 0.01)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(g.BidIV-0.7) > 1e-6 || math.Abs(g.AskIV-0.8) > 1e-6 || g.MidIV < g.BidIV || g.MidIV > g.AskIV {
+		t.Errorf("got volatilities %v %v %v", g.BidIV, g.MidIV, g.AskIV)
+	}
+	if g.Delta <= 0 || g.Delta >= 1 || g.Gamma <= 0 || g.Vega <= 0 || g.Theta >= 0 {
+		t.Errorf("got greeks %+v", g)
+	}
+
+	// on-chain venues quote in USD
+	meta.InstrumentName = "0x2a8def4c0a1fb3dc0c8b3bb3d2cbb74f8d46bb55"
+	ob.BidPrice, ob.AskPrice = bid, 0
+	g, err = Compute(meta, ob, 38000, 0.01)
+	if err != nil || math.Abs(g.BidIV-0.7) > 1e-6 || g.MidIV != 0 || g.AskIV != 0 {
+		t.Errorf("got %+v, %v for a bid only", g, err)
+	}
+
+	ob.BidPrice = 0
+	if _, err = Compute(meta, ob, 38000, 0.01); err == nil {
+		t.Error("expected error without prices")
+	}
+}
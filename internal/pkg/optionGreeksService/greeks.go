@@ -0,0 +1,63 @@
+package optionGreeksService
+
+import (
+	"errors"
+	"regexp"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// Deribit (e.g. BTC-25JUN21-40000-C) and OKEx (e.g. BTC-USD-210625-40000-C) quote premiums
+// in the base currency, the on-chain venues Opyn and Premia in USD.
+var premiumInBaseCurrency = regexp.MustCompile(`^[A-Z]+-(\d{1,2}[A-Z]{3}\d{2}|USD-\d{6})-[\d.]+-[CP]$`)
+
+// Compute returns the implied volatilities and greeks of the option @meta with order book @ob, given
+// the USD @spot price of its base currency and the annual, continuously compounded risk-free @rate.
+func Compute(meta dia.OptionMeta, ob dia.OptionOrderbookDatum, spot float64, rate float64) (dia.OptionGreeks, error) {
+	g := dia.OptionGreeks{
+		InstrumentName:  meta.InstrumentName,
+		BaseCurrency:    meta.BaseCurrency,
+		OptionType:      meta.OptionType,
+		StrikePrice:     meta.StrikePrice,
+		ExpirationTime:  meta.ExpirationTime,
+		ObservationTime: ob.ObservationTime,
+		SpotPrice:       spot,
+		RiskFreeRate:    rate,
+	}
+	t := meta.ExpirationTime.Sub(ob.ObservationTime).Hours() / 24 / daysPerYear
+	m, err := newBlack76(meta.OptionType, spot, meta.StrikePrice, t, rate)
+	if err != nil {
+		return g, err
+	}
+
+	bid, ask := ob.BidPrice, ob.AskPrice
+	if premiumInBaseCurrency.MatchString(meta.InstrumentName) {
+		bid, ask = bid*spot, ask*spot
+	}
+	if bid > 0 {
+		g.BidIV, _ = m.impliedVolatility(bid)
+	}
+	if ask > 0 {
+		g.AskIV, _ = m.impliedVolatility(ask)
+	}
+	if bid > 0 && ask >= bid {
+		g.MidIV, _ = m.impliedVolatility((bid + ask) / 2)
+	}
+
+	vol := g.MidIV
+	if vol == 0 {
+		switch {
+		case g.BidIV > 0 && g.AskIV > 0:
+			vol = (g.BidIV + g.AskIV) / 2
+		case g.BidIV > 0:
+			vol = g.BidIV
+		default:
+			vol = g.AskIV
+		}
+	}
+	if vol == 0 {
+		return g, errors.New("no implied volatility for " + meta.InstrumentName)
+	}
+	g.Delta, g.Gamma, g.Vega, g.Theta = m.greeks(vol)
+	return g, nil
+}
@@ -0,0 +1,348 @@
+package writers
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// ArchiveColumns is the schema of the files written by the ArchiveWriter.
+var ArchiveColumns = []string{"time", "exchange", "market", "symbol", "price", "volume", "foreignTradeID", "raw"}
+
+// ArchiveRecord is a row of an archive file. Messages which are archived unparsed,
+// such as the lines of the futures scrapers, only carry Time, Exchange, Market and Raw.
+type ArchiveRecord struct {
+	Time           time.Time
+	Exchange       string
+	Market         string
+	Symbol         string
+	Price          float64
+	Volume         float64
+	ForeignTradeID string
+	Raw            string
+}
+
+// ArchiveManifest describes an archive file once it is complete. It is written next
+// to the file as <file>.manifest.json.
+type ArchiveManifest struct {
+	File      string
+	Exchange  string
+	Market    string
+	Columns   []string
+	Records   int64
+	Bytes     int64
+	FirstTime time.Time
+	LastTime  time.Time
+	SHA256    string
+}
+
+// ArchiveWriter - an implementation of the Writer interface which writes gzip-compressed
+// CSV files with the columns ArchiveColumns, one file per exchange and market at a time.
+// Files are named exchange-market-yyyy-mm-dd-n.csv.gz and rotated at midnight UTC and once
+// they exceed maxBytes. Rows are buffered, so Close has to be called before exiting.
+type ArchiveWriter struct {
+	dir      string
+	maxBytes int64
+	now      func() time.Time
+
+	mu      sync.Mutex
+	streams map[string]archiveStream
+	files   map[string]*archiveFile
+}
+
+type archiveStream struct {
+	exchange string
+	market   string
+}
+
+// archiveFile is the file currently written for a stream.
+type archiveFile struct {
+	day      string
+	path     string
+	file     *os.File
+	counter  *countingWriter
+	gz       *gzip.Writer
+	csv      *csv.Writer
+	manifest ArchiveManifest
+}
+
+// countingWriter counts and hashes the compressed bytes written to a file.
+type countingWriter struct {
+	w     io.Writer
+	n     int64
+	sha   hash.Hash
+	multi io.Writer
+}
+
+func newCountingWriter(w io.Writer) *countingWriter {
+	c := &countingWriter{w: w, sha: sha256.New()}
+	c.multi = io.MultiWriter(w, c.sha)
+	return c
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.multi.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// NewArchiveWriter returns an ArchiveWriter writing to @dir. Files are rotated once they
+// exceed @maxBytes compressed bytes; 0 rotates by day only.
+func NewArchiveWriter(dir string, maxBytes int64) (*ArchiveWriter, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	return &ArchiveWriter{
+		dir:      dir,
+		maxBytes: maxBytes,
+		now:      time.Now,
+		streams:  make(map[string]archiveStream),
+		files:    make(map[string]*archiveFile),
+	}, nil
+}
+
+// GetWriteFileName - returns the name of the stream of @exchange and @market, which is passed to Write.
+// The files of the stream are chosen by the ArchiveWriter.
+func (w *ArchiveWriter) GetWriteFileName(exchange string, market string) string {
+	name := strings.Replace(exchange+"-"+market, "/", "_", -1)
+	w.mu.Lock()
+	w.streams[name] = archiveStream{exchange: exchange, market: market}
+	w.mu.Unlock()
+	return name
+}
+
+// Write - archives @line as raw message of the stream @filename, stamped with the current time.
+func (w *ArchiveWriter) Write(line string, filename string) (int, error) {
+	w.mu.Lock()
+	stream, ok := w.streams[filename]
+	if !ok {
+		stream, ok = w.streams[filepath.Base(filename)]
+	}
+	w.mu.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("unknown stream %s, use GetWriteFileName", filename)
+	}
+	err := w.WriteRecord(ArchiveRecord{
+		Time:     w.now(),
+		Exchange: stream.exchange,
+		Market:   stream.market,
+		Raw:      strings.TrimSuffix(line, "\n"),
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(line), nil
+}
+
+// WriteTrade archives @t.
+func (w *ArchiveWriter) WriteTrade(t *dia.Trade) error {
+	return w.WriteRecord(ArchiveRecord{
+		Time:           t.Time,
+		Exchange:       t.Source,
+		Market:         t.Pair,
+		Symbol:         t.Symbol,
+		Price:          t.Price,
+		Volume:         t.Volume,
+		ForeignTradeID: t.ForeignTradeID,
+	})
+}
+
+// WriteRecord appends @r to the current file of its exchange and market.
+func (w *ArchiveWriter) WriteRecord(r ArchiveRecord) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	name := strings.Replace(r.Exchange+"-"+r.Market, "/", "_", -1)
+	day := w.now().UTC().Format("2006-01-02")
+	f, ok := w.files[name]
+	if ok && (f.day != day || (w.maxBytes > 0 && f.counter.n >= w.maxBytes)) {
+		err := f.close()
+		delete(w.files, name)
+		if err != nil {
+			return err
+		}
+		ok = false
+	}
+	if !ok {
+		var err error
+		f, err = w.create(name, day, r)
+		if err != nil {
+			return err
+		}
+		w.files[name] = f
+	}
+	return f.write(r)
+}
+
+// create opens the next file of the stream @name on @day.
+func (w *ArchiveWriter) create(name string, day string, r ArchiveRecord) (*archiveFile, error) {
+	var path string
+	for n := 0; ; n++ {
+		path = filepath.Join(w.dir, fmt.Sprintf("%s-%s-%d.csv.gz", name, day, n))
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			break
+		}
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return nil, err
+	}
+	counter := newCountingWriter(file)
+	gz := gzip.NewWriter(counter)
+	f := &archiveFile{
+		day:     day,
+		path:    path,
+		file:    file,
+		counter: counter,
+		gz:      gz,
+		csv:     csv.NewWriter(gz),
+		manifest: ArchiveManifest{
+			File:     filepath.Base(path),
+			Exchange: r.Exchange,
+			Market:   r.Market,
+			Columns:  ArchiveColumns,
+		},
+	}
+	err = f.csv.Write(ArchiveColumns)
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *archiveFile) write(r ArchiveRecord) error {
+	err := f.csv.Write([]string{
+		r.Time.UTC().Format(time.RFC3339Nano),
+		r.Exchange,
+		r.Market,
+		r.Symbol,
+		strconv.FormatFloat(r.Price, 'g', -1, 64),
+		strconv.FormatFloat(r.Volume, 'g', -1, 64),
+		r.ForeignTradeID,
+		r.Raw,
+	})
+	if err != nil {
+		return err
+	}
+	// hand the row to the compressor, so that the size of the file is known
+	f.csv.Flush()
+	if err = f.csv.Error(); err != nil {
+		return err
+	}
+	if f.manifest.Records == 0 || r.Time.Before(f.manifest.FirstTime) {
+		f.manifest.FirstTime = r.Time
+	}
+	if r.Time.After(f.manifest.LastTime) {
+		f.manifest.LastTime = r.Time
+	}
+	f.manifest.Records++
+	return nil
+}
+
+// close completes the file and writes its manifest.
+func (f *archiveFile) close() error {
+	err := f.gz.Close()
+	if err != nil {
+		f.file.Close()
+		return err
+	}
+	err = f.file.Close()
+	if err != nil {
+		return err
+	}
+	f.manifest.Bytes = f.counter.n
+	f.manifest.SHA256 = hex.EncodeToString(f.counter.sha.Sum(nil))
+	data, err := json.MarshalIndent(f.manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(f.path+".manifest.json", data, 0644)
+}
+
+// Close completes all open files.
+func (w *ArchiveWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var err error
+	for name, f := range w.files {
+		if closeErr := f.close(); closeErr != nil {
+			err = closeErr
+		}
+		delete(w.files, name)
+	}
+	return err
+}
+
+// ReadArchive calls @fn with every record of the archive file @filename.
+func ReadArchive(filename string, fn func(ArchiveRecord) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	reader := csv.NewReader(gz)
+	reader.ReuseRecord = true
+	header, err := reader.Read()
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(header, ArchiveColumns) {
+		return errors.New("unknown archive columns: " + strings.Join(header, ","))
+	}
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r := ArchiveRecord{
+			Exchange:       row[1],
+			Market:         row[2],
+			Symbol:         row[3],
+			ForeignTradeID: row[6],
+			Raw:            row[7],
+		}
+		r.Time, err = time.Parse(time.RFC3339Nano, row[0])
+		if err != nil {
+			return err
+		}
+		r.Price, err = strconv.ParseFloat(row[4], 64)
+		if err != nil {
+			return err
+		}
+		r.Volume, err = strconv.ParseFloat(row[5], 64)
+		if err != nil {
+			return err
+		}
+		err = fn(r)
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package writers
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestArchiveWriter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	w, err := NewArchiveWriter(dir, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2021, 5, 1, 23, 59, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+
+	trade := &dia.Trade{Symbol: "BTC", Pair: "BTC/USDT", Price: 57000.5, Volume: -0.25, Time: now, ForeignTradeID: "1", Source: "Binance"}
+	if err = w.WriteTrade(trade); err != nil {
+		t.Fatal(err)
+	}
+	// a gzip header exceeds one byte, so the next record opens a new file
+	if err = w.WriteTrade(trade); err != nil {
+		t.Fatal(err)
+	}
+	stream := w.GetWriteFileName("ftx", "BTC-PERP")
+	if _, err = w.Write(`{"type": "update"}`+"\n", stream); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(time.Hour)
+	if err = w.WriteTrade(trade); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.csv.gz"))
+	if len(files) != 4 {
+		t.Fatalf("wrote %v, want 4 files", files)
+	}
+	var records []ArchiveRecord
+	for _, name := range []string{"Binance-BTC_USDT-2021-05-01-0.csv.gz", "Binance-BTC_USDT-2021-05-01-1.csv.gz", "Binance-BTC_USDT-2021-05-02-0.csv.gz", "ftx-BTC-PERP-2021-05-01-0.csv.gz"} {
+		err = ReadArchive(filepath.Join(dir, name), func(r ArchiveRecord) error {
+			records = append(records, r)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadFile(filepath.Join(dir, name+".manifest.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var manifest ArchiveManifest
+		if err = json.Unmarshal(data, &manifest); err != nil || manifest.Records != 1 || manifest.SHA256 == "" {
+			t.Errorf("unexpected manifest %+v: %v", manifest, err)
+		}
+	}
+	if len(records) != 4 {
+		t.Fatalf("read %d records, want 4", len(records))
+	}
+	if r := records[0]; r.Price != trade.Price || r.Volume != trade.Volume || r.Market != trade.Pair || !r.Time.Equal(trade.Time) {
+		t.Errorf("trade read back as %+v", r)
+	}
+	if r := records[3]; r.Exchange != "ftx" || r.Market != "BTC-PERP" || r.Raw != `{"type": "update"}` {
+		t.Errorf("raw message read back as %+v", r)
+	}
+}
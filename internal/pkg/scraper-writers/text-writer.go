@@ -3,17 +3,20 @@ package writers
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 	"time"
 )
 
 // FileWriter - One implementation of the Writer interface. This one will write to txt files, and generate file names like yyyy-mm-dd-exchange-market.txt.
-type FileWriter struct{}
+type FileWriter struct {
+	Dir string // directory of the files, the working directory if empty
+}
 
 // GetWriteFileName - Will generate the file name for you of the format yyyy-mm-dd-exchange-market.txt. New files will be created at midnight because the scrapers are calling this method each time before writing to file.
 func (f *FileWriter) GetWriteFileName(exchange string, market string) string {
 	now := time.Now()
-	return f.clean(fmt.Sprintf("%v-%v-%v-%v-%v.txt", now.Year(), int(now.Month()), now.Day(), exchange, market))
+	return filepath.Join(f.Dir, f.clean(fmt.Sprintf("%v-%v-%v-%v-%v.txt", now.Year(), int(now.Month()), now.Day(), exchange, market)))
 }
 
 // Write - Will write to the filename the line.
@@ -1,8 +1,26 @@
 package writers
 
+import "fmt"
+
 // Writer is an interface that is responsible for generating dynamic file names (to create new files at midnight) and writing data to them
 type Writer interface {
 	GetWriteFileName(exchange string, market string) string // will return the name of the file in which it will write
 	Write(line string, filename string) (int, error)        // returns number of bytes written or error
 	// rationale for making a line a pointer - is because it can be very large. filename will always be small.
 }
+
+// NewWriter returns a writer of the kind @kind writing to @dir: "text" for a FileWriter
+// or "archive" for an ArchiveWriter rotating files at @maxBytes.
+func NewWriter(kind string, dir string, maxBytes int64) (Writer, error) {
+	switch kind {
+	case "text":
+		return &FileWriter{Dir: dir}, nil
+	case "archive":
+		w, err := NewArchiveWriter(dir, maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		return w, nil
+	}
+	return nil, fmt.Errorf("unknown writer %s", kind)
+}
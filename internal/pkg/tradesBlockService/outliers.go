@@ -0,0 +1,153 @@
+package tradesBlockService
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// referenceRefresh is the time after which a foreign quotation is looked up again.
+const referenceRefresh = 5 * time.Minute
+
+// OutlierConfig configures the quarantine of trades whose price deviates from the other markets of their asset.
+type OutlierConfig struct {
+	// MaxDeviation is the maximal relative deviation of a trade's EstimatedUSDPrice from
+	// the cross-exchange median and the reference price. The checks are disabled if 0.
+	// A trade is only compared with the median if most other exchanges agree with it.
+	MaxDeviation float64
+	// MedianWindow is the time the latest price of an exchange counts in the cross-exchange median.
+	MedianWindow time.Duration
+	// MinExchanges is the minimal number of other exchanges with a price in the window
+	// for the median check to apply.
+	MinExchanges int
+	// ReferenceSource is the source of the foreign quotations, e.g. Coingecko or CoinMarketCap,
+	// trades are compared with. Quotations are looked up by symbol. No comparison if empty.
+	ReferenceSource string
+	// ReferenceMaxAge is the maximal age of a foreign quotation used as reference.
+	ReferenceMaxAge time.Duration
+}
+
+// exchangePrice is the latest price of an asset on an exchange, be it accepted or not.
+type exchangePrice struct {
+	price float64
+	time  time.Time
+}
+
+// referencePrice is a cached foreign quotation.
+type referencePrice struct {
+	price   float64
+	time    time.Time
+	fetched time.Time
+}
+
+// outlierDetector compares trades with the latest prices of their asset on other exchanges
+// and with foreign quotations. All times are trade times, so that replays give the same results.
+type outlierDetector struct {
+	config     OutlierConfig
+	datastore  models.Datastore
+	prices     map[string]map[string]exchangePrice
+	references map[string]referencePrice
+}
+
+func newOutlierDetector(config OutlierConfig, datastore models.Datastore) *outlierDetector {
+	return &outlierDetector{
+		config:     config,
+		datastore:  datastore,
+		prices:     make(map[string]map[string]exchangePrice),
+		references: make(map[string]referencePrice),
+	}
+}
+
+// check returns the reason for which @t is quarantined together with the price it was compared
+// with, or an empty reason if it is accepted. All trades enter the cross-exchange median, so that
+// the median follows genuine moves of the market as the exchanges trade at the new level.
+func (d *outlierDetector) check(t dia.Trade) (reason string, reference float64) {
+	if d.config.MaxDeviation <= 0 {
+		return
+	}
+	asset := t.Quote().Identifier()
+	median, ok := d.median(asset, t.Source, t.Time)
+	if _, known := d.prices[asset]; !known {
+		d.prices[asset] = make(map[string]exchangePrice)
+	}
+	d.prices[asset][t.Source] = exchangePrice{price: t.EstimatedUSDPrice, time: t.Time}
+
+	if ok && deviates(t.EstimatedUSDPrice, median, d.config.MaxDeviation) {
+		return metrics.ReasonMedianDeviation, median
+	}
+	if price, ok := d.reference(t.Symbol, t.Time); ok && deviates(t.EstimatedUSDPrice, price, d.config.MaxDeviation) {
+		return metrics.ReasonReferenceDeviation, price
+	}
+	return
+}
+
+// median returns the median of the latest prices of @asset on other exchanges than @exchange
+// within the median window around @t. There is no median if these exchanges disagree, i.e.
+// if not more than half of their prices lie within the maximal deviation from the median, as
+// while the market moves.
+func (d *outlierDetector) median(asset string, exchange string, t time.Time) (float64, bool) {
+	prices := []float64{}
+	for e, p := range d.prices[asset] {
+		if e == exchange {
+			continue
+		}
+		if age := t.Sub(p.time); math.Abs(age.Seconds()) <= d.config.MedianWindow.Seconds() {
+			prices = append(prices, p.price)
+		}
+	}
+	if len(prices) == 0 || len(prices) < d.config.MinExchanges {
+		return 0, false
+	}
+	sort.Float64s(prices)
+	n := len(prices)
+	median := prices[n/2]
+	if n%2 == 0 {
+		median = (prices[n/2-1] + prices[n/2]) / 2
+	}
+	agreeing := 0
+	for _, p := range prices {
+		if !deviates(p, median, d.config.MaxDeviation) {
+			agreeing++
+		}
+	}
+	if 2*agreeing <= n {
+		return 0, false
+	}
+	return median, true
+}
+
+// reference returns the latest foreign quotation of @symbol at @t, if it is recent enough.
+func (d *outlierDetector) reference(symbol string, t time.Time) (float64, bool) {
+	if d.config.ReferenceSource == "" {
+		return 0, false
+	}
+	ref, ok := d.references[symbol]
+	if !ok || t.Sub(ref.fetched) > referenceRefresh || t.Before(ref.fetched) {
+		ref = referencePrice{fetched: t}
+		fq, err := d.datastore.GetForeignQuotationInflux(symbol, d.config.ReferenceSource, t)
+		if err != nil {
+			log.Warnf("reference price of %s on %s: %v", symbol, d.config.ReferenceSource, err)
+		} else {
+			ref.price = fq.Price
+			ref.time = fq.Time
+		}
+		d.references[symbol] = ref
+	}
+	if ref.price <= 0 || t.Sub(ref.time) > d.config.ReferenceMaxAge {
+		return 0, false
+	}
+	return ref.price, true
+}
+
+// deviates returns true if @price deviates from @reference by more than @maxDeviation relative to @reference.
+func deviates(price float64, reference float64, maxDeviation float64) bool {
+	if reference <= 0 {
+		return false
+	}
+	return math.Abs(price-reference)/reference > maxDeviation
+}
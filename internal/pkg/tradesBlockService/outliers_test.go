@@ -0,0 +1,56 @@
+package tradesBlockService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
+)
+
+func TestOutlierDetector(t *testing.T) {
+	d := newOutlierDetector(OutlierConfig{MaxDeviation: 0.1, MedianWindow: 10 * time.Minute, MinExchanges: 2}, nil)
+	now := time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC)
+	trade := func(exchange string, price float64, t time.Time) dia.Trade {
+		return dia.Trade{Symbol: "BTC", Source: exchange, EstimatedUSDPrice: price, Time: t}
+	}
+
+	// no reference while fewer than two other exchanges have a price
+	for _, tr := range []dia.Trade{trade("Binance", 100, now), trade("Kraken", 102, now), trade("Bitfinex", 108, now)} {
+		if reason, _ := d.check(tr); reason != "" {
+			t.Errorf("expected %s to be accepted, got %s", tr.Source, reason)
+		}
+	}
+	// median of 100, 102 and 108 is 102
+	reason, reference := d.check(trade("CoinBase", 130, now.Add(time.Minute)))
+	if reason != metrics.ReasonMedianDeviation || reference != 102 {
+		t.Errorf("expected quarantine against 102, got %s %v", reason, reference)
+	}
+	if reason, _ := d.check(trade("CoinBase", 105, now.Add(time.Minute))); reason != "" {
+		t.Errorf("expected trade within the deviation to be accepted, got %s", reason)
+	}
+	// a crash reaching the exchanges one after the other
+	crash := now.Add(2 * time.Minute)
+	for _, exchange := range []string{"Binance", "Kraken"} {
+		if reason, _ := d.check(trade(exchange, 88, crash)); reason != metrics.ReasonMedianDeviation {
+			t.Errorf("expected %s moving before most exchanges to be quarantined, got %q", exchange, reason)
+		}
+	}
+	// the quarantined prices count, 88, 88 and 108 agree on 88
+	if reason, _ := d.check(trade("CoinBase", 90, crash)); reason != "" {
+		t.Errorf("expected trade at the new level of most exchanges to be accepted, got %s", reason)
+	}
+	if reason, _ := d.check(trade("Binance", 89, crash.Add(time.Second))); reason != "" {
+		t.Errorf("expected the first exchange to move to be accepted after the move, got %s", reason)
+	}
+	// the other exchanges disagree between 89, 120 and 140
+	d.check(trade("Bitfinex", 140, crash))
+	d.check(trade("CoinBase", 120, crash))
+	if reason, _ := d.check(trade("Kraken", 100, crash)); reason != "" {
+		t.Errorf("expected no quarantine while the other exchanges disagree, got %s", reason)
+	}
+	// prices leave the window
+	if reason, _ := d.check(trade("Binance", 200, now.Add(time.Hour))); reason != "" {
+		t.Errorf("expected trade without recent prices of other exchanges to be accepted, got %s", reason)
+	}
+}
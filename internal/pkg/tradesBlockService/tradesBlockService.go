@@ -9,6 +9,7 @@ import (
 
 	"github.com/cnf/structhash"
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
 	models "github.com/diadata-org/diadata/pkg/model"
 	log "github.com/sirupsen/logrus"
 )
@@ -38,11 +39,37 @@ type TradesBlockService struct {
 	closed          bool
 	started         bool
 	BlockDuration   int64
-	currentBlock    *dia.TradesBlock
 	datastore       models.Datastore
+	outliers        *outlierDetector
+	lateness        LatenessConfig
+	// blocks waiting for the watermark to pass their end, by begin time
+	openBlocks map[int64]*dia.TradesBlock
+	// late trades of finalized blocks, by begin time of the block
+	lateTrades      map[int64][]dia.Trade
+	chanCorrections chan *dia.TradesBlock
+	// end of the latest finalized block
+	finalizedUntil time.Time
+	// latest trade time, but not in the future
+	maxTradeTime time.Time
 }
 
-func NewTradesBlockService(datastore models.Datastore, blockDuration int64) *TradesBlockService {
+// LatenessConfig configures how long blocks wait for trades arriving out of order.
+type LatenessConfig struct {
+	// AllowedLateness is the time a block is kept open after its end. Blocks are finalized
+	// once the watermark, the latest trade time minus AllowedLateness, passes their end.
+	// Every block is published AllowedLateness after its end. On-chain trades carry the time of
+	// their block and arrive after the confirmation delay of their chain, so with a shorter
+	// AllowedLateness they are published as corrections.
+	AllowedLateness time.Duration
+	// CorrectionWindow is the time after the watermark passed the end of a finalized block in which
+	// its late trades are published as corrections. Later trades are dropped.
+	CorrectionWindow time.Duration
+}
+
+// NewTradesBlockService returns a service collecting trades into blocks of @blockDuration seconds.
+// Trades deviating from other markets as configured in @outlierConfig are quarantined, late trades
+// are handled as configured in @lateness.
+func NewTradesBlockService(datastore models.Datastore, blockDuration int64, outlierConfig OutlierConfig, lateness LatenessConfig) *TradesBlockService {
 	s := &TradesBlockService{
 		shutdown:        make(chan nothing),
 		shutdownDone:    make(chan nothing),
@@ -50,9 +77,13 @@ func NewTradesBlockService(datastore models.Datastore, blockDuration int64) *Tra
 		chanTradesBlock: make(chan *dia.TradesBlock),
 		error:           nil,
 		started:         false,
-		currentBlock:    nil,
 		BlockDuration:   blockDuration,
 		datastore:       datastore,
+		outliers:        newOutlierDetector(outlierConfig, datastore),
+		lateness:        lateness,
+		openBlocks:      make(map[int64]*dia.TradesBlock),
+		lateTrades:      make(map[int64][]dia.Trade),
+		chanCorrections: make(chan *dia.TradesBlock),
 	}
 	go s.mainLoop()
 	return s
@@ -86,30 +117,161 @@ func (ps *TradesBlockService) Channel() chan *dia.TradesBlock {
 	return ps.chanTradesBlock
 }
 
-func (s *TradesBlockService) finaliseCurrentBlock() {
+// Corrections returns the channel of the corrections of finalized blocks. A correction holds the
+// late trades of the block with the same BeginTime, which was not published if it had no trades in
+// time. It must be read like Channel.
+func (ps *TradesBlockService) Corrections() chan *dia.TradesBlock {
+	return ps.chanCorrections
+}
 
-	sort.Slice(s.currentBlock.TradesBlockData.Trades, func(i, j int) bool {
-		return s.currentBlock.TradesBlockData.Trades[i].Time.Before(s.currentBlock.TradesBlockData.Trades[j].Time)
+// BuildTradesBlock returns the sealed block of @trades beginning at @begin. Like the blocks of the
+// service, it only depends on the set of trades, not on their order.
+func BuildTradesBlock(trades []dia.Trade, begin time.Time, blockDuration int64) *dia.TradesBlock {
+	b := &dia.TradesBlock{
+		TradesBlockData: dia.TradesBlockData{
+			Trades:    trades,
+			EndTime:   begin.Add(time.Duration(blockDuration) * time.Second),
+			BeginTime: begin,
+		},
+	}
+	seal(b)
+	return b
+}
+
+// seal sorts the trades of @b and sets its hash. Trades of the same time are ordered by exchange,
+// pair, foreign trade ID, price and volume, so that the block does not depend on the order the trades arrived in.
+func seal(b *dia.TradesBlock) {
+	trades := b.TradesBlockData.Trades
+	sort.Slice(trades, func(i, j int) bool {
+		if !trades[i].Time.Equal(trades[j].Time) {
+			return trades[i].Time.Before(trades[j].Time)
+		}
+		if trades[i].Source != trades[j].Source {
+			return trades[i].Source < trades[j].Source
+		}
+		if trades[i].Pair != trades[j].Pair {
+			return trades[i].Pair < trades[j].Pair
+		}
+		if trades[i].ForeignTradeID != trades[j].ForeignTradeID {
+			return trades[i].ForeignTradeID < trades[j].ForeignTradeID
+		}
+		if trades[i].Price != trades[j].Price {
+			return trades[i].Price < trades[j].Price
+		}
+		return trades[i].Volume < trades[j].Volume
 	})
 
-	hash, err := structhash.Hash(s.currentBlock.TradesBlockData, 1)
+	hash, err := structhash.Hash(b.TradesBlockData, 1)
 	if err != nil {
 		log.Printf("error on hash")
 		hash = "hashError"
 	}
-	s.currentBlock.BlockHash = hash
-	s.currentBlock.TradesBlockData.TradesNumber = len(s.currentBlock.TradesBlockData.Trades)
-	s.chanTradesBlock <- s.currentBlock
+	b.BlockHash = hash
+	b.TradesBlockData.TradesNumber = len(b.TradesBlockData.Trades)
+}
+
+// watermark returns the time up to which blocks are finalized.
+func (s *TradesBlockService) watermark() time.Time {
+	return s.maxTradeTime.Add(-s.lateness.AllowedLateness)
+}
+
+// addTrade adds @t to its block. If the block was finalized already, @t is kept for a correction
+// within the correction window. Afterwards it is dropped and false returned.
+func (s *TradesBlockService) addTrade(t dia.Trade) bool {
+	begin := (t.Time.Unix() / s.BlockDuration) * s.BlockDuration
+	end := time.Unix(begin+s.BlockDuration, 0)
+	switch {
+	case !end.After(s.finalizedUntil) && s.watermark().Sub(end) > s.lateness.CorrectionWindow:
+		log.Debugf("ignore trade should be in previous block %v", t)
+		metrics.TradesLate.WithLabelValues(t.Source, metrics.LateDropped).Inc()
+		metrics.TradesIgnored.WithLabelValues(t.Source, metrics.ReasonLateBlock).Inc()
+		return false
+	case !end.After(s.finalizedUntil):
+		metrics.TradesLate.WithLabelValues(t.Source, metrics.LateCorrection).Inc()
+		s.lateTrades[begin] = append(s.lateTrades[begin], t)
+	default:
+		if t.Time.Unix() < (s.maxTradeTime.Unix()/s.BlockDuration)*s.BlockDuration {
+			metrics.TradesLate.WithLabelValues(t.Source, metrics.LateOpenBlock).Inc()
+		}
+		b, ok := s.openBlocks[begin]
+		if !ok {
+			b = &dia.TradesBlock{
+				TradesBlockData: dia.TradesBlockData{
+					Trades:    []dia.Trade{},
+					EndTime:   end,
+					BeginTime: time.Unix(begin, 0),
+				},
+			}
+			s.openBlocks[begin] = b
+		}
+		b.TradesBlockData.Trades = append(b.TradesBlockData.Trades, t)
+	}
+
+	tradeTime := t.Time
+	if now := time.Now(); tradeTime.After(now) {
+		tradeTime = now
+	}
+	if tradeTime.After(s.maxTradeTime) {
+		s.maxTradeTime = tradeTime
+		s.finaliseBlocks()
+	}
+	return true
+}
+
+// finaliseBlocks publishes the open blocks the watermark passed in the order of their begin
+// time, followed by the corrections of the late trades collected meanwhile.
+func (s *TradesBlockService) finaliseBlocks() {
+	watermark := s.watermark()
+	begins := []int64{}
+	for begin, b := range s.openBlocks {
+		if !b.TradesBlockData.EndTime.After(watermark) {
+			begins = append(begins, begin)
+		}
+	}
+	if len(begins) == 0 {
+		return
+	}
+	sort.Slice(begins, func(i, j int) bool { return begins[i] < begins[j] })
+	for _, begin := range begins {
+		b := s.openBlocks[begin]
+		delete(s.openBlocks, begin)
+		seal(b)
+		metrics.BlockSize.WithLabelValues("trades").Observe(float64(b.TradesBlockData.TradesNumber))
+		log.Info("finalised block beginTime:", b.TradesBlockData.BeginTime, " nb trades:", b.TradesBlockData.TradesNumber)
+		s.chanTradesBlock <- b
+		s.finalizedUntil = b.TradesBlockData.EndTime
+	}
+	// the late trades are stored before their corrections are recomputed from the stored trades
+	s.datastore.Flush()
+	s.publishCorrections()
+}
+
+// publishCorrections publishes the late trades of finalized blocks, one correction per block.
+func (s *TradesBlockService) publishCorrections() {
+	begins := []int64{}
+	for begin := range s.lateTrades {
+		begins = append(begins, begin)
+	}
+	sort.Slice(begins, func(i, j int) bool { return begins[i] < begins[j] })
+	for _, begin := range begins {
+		b := BuildTradesBlock(s.lateTrades[begin], time.Unix(begin, 0), s.BlockDuration)
+		log.Infof("correction of block beginTime: %v with %d late trades", b.TradesBlockData.BeginTime, b.TradesBlockData.TradesNumber)
+		s.chanCorrections <- b
+	}
+	s.lateTrades = make(map[int64][]dia.Trade)
 }
 
 func (s *TradesBlockService) process(t dia.Trade) {
 
 	var ignoreTrade bool
-	baseToken := t.BaseToken()
-	if baseToken != "USD" {
-		val, err := s.datastore.GetPriceUSD(baseToken)
+	// reason for which the trade is ignored first
+	var reason string
+	baseToken := t.Base()
+	if baseToken.Symbol != "USD" {
+		val, err := s.datastore.GetAssetPriceUSD(baseToken)
 		if err != nil {
-			log.Error("Cant find base token ", baseToken, " in redis ", err, " ignoring ", t)
+			log.Error("Cant find base token ", baseToken.Identifier(), " in redis ", err, " ignoring ", t)
+			reason = metrics.ReasonMissingBasePrice
 			ignoreTrade = true
 		} else {
 			t.EstimatedUSDPrice = t.Price * val
@@ -122,46 +284,33 @@ func (s *TradesBlockService) process(t dia.Trade) {
 	if _, ok := stablecoins[t.Symbol]; ok {
 		if math.Abs(t.EstimatedUSDPrice-1) > tol {
 			log.Errorf("price for stablecoin %s diverges by %v", t.Symbol, math.Abs(t.EstimatedUSDPrice-1))
+			if !ignoreTrade {
+				reason = metrics.ReasonStablecoinDeviation
+			}
 			ignoreTrade = true
 		}
 	}
-	// Comment Philipp: We could make another check here. Store CG and/or CMC quotation in redis cache
-	// and compare with estimatedUSDPrice. If deviation is too large ignore trade. If we do so,
-	// we should already think about how to do it best with regards to historic values, as these are coming up.
-
+	// Compare with the other exchanges and the foreign quotations of the asset
+	var reference float64
 	if !ignoreTrade {
-		s.datastore.SaveTradeInflux(&t)
-	}
-
-	if s.currentBlock != nil &&
-		s.currentBlock.TradesBlockData.BeginTime.After(t.Time) {
-		log.Debugf("ignore trade should be in previous block %v", t)
-		ignoreTrade = true
+		reason, reference = s.outliers.check(t)
+		if reason != "" {
+			log.Warnf("price %v of %s on %s deviates from %v: %s", t.EstimatedUSDPrice, t.Symbol, t.Source, reference, reason)
+			ignoreTrade = true
+		}
 	}
 
 	if !ignoreTrade {
-
-		if s.currentBlock == nil || s.currentBlock.TradesBlockData.EndTime.Before(t.Time) {
-			if s.currentBlock != nil {
-				s.finaliseCurrentBlock()
-			}
-
-			b := &dia.TradesBlock{
-				TradesBlockData: dia.TradesBlockData{
-					Trades:    []dia.Trade{},
-					EndTime:   time.Unix((t.Time.Unix()/s.BlockDuration)*s.BlockDuration+s.BlockDuration, 0),
-					BeginTime: time.Unix((t.Time.Unix()/s.BlockDuration)*s.BlockDuration, 0),
-				},
-			}
-			if s.currentBlock != nil {
-				log.Info("created new block beginTime:", b.TradesBlockData.BeginTime, "previous block nb trades:", len(s.currentBlock.TradesBlockData.Trades))
-			}
-			s.currentBlock = b
-			s.datastore.Flush()
+		// trades dropped as late are quarantined, so that the stored trades are those of the blocks and their corrections
+		if s.addTrade(t) {
+			s.datastore.SaveTradeInflux(&t)
+		} else {
+			s.datastore.SaveQuarantinedTradeInflux(&t, metrics.ReasonLateBlock, 0)
 		}
-		s.currentBlock.TradesBlockData.Trades = append(s.currentBlock.TradesBlockData.Trades, t)
 	} else {
+		s.datastore.SaveQuarantinedTradeInflux(&t, reason, reference)
 		log.Debugf("ignore trade  %v", t)
+		metrics.TradesIgnored.WithLabelValues(t.Source, reason).Inc()
 	}
 }
 
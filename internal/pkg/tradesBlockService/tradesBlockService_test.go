@@ -0,0 +1,72 @@
+package tradesBlockService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// fakeDatastore stands in for the datastore, which is only flushed when blocks are finalized.
+type fakeDatastore struct {
+	models.Datastore
+}
+
+func (fakeDatastore) Flush() error {
+	return nil
+}
+
+func TestLateTrades(t *testing.T) {
+	s := NewTradesBlockService(fakeDatastore{}, 120, OutlierConfig{}, LatenessConfig{AllowedLateness: 30 * time.Second, CorrectionWindow: 10 * time.Minute})
+	defer s.Close()
+	var blocks, corrections []*dia.TradesBlock
+	collect := func(t dia.Trade) {
+		done := make(chan nothing)
+		go func() {
+			for {
+				select {
+				case b := <-s.Channel():
+					blocks = append(blocks, b)
+				case b := <-s.Corrections():
+					corrections = append(corrections, b)
+				case <-done:
+					return
+				}
+			}
+		}()
+		s.addTrade(t)
+		done <- nothing{}
+	}
+	begin := time.Unix(1600000080, 0)
+	trade := func(seconds int) dia.Trade {
+		return dia.Trade{Source: "Uniswap", Time: begin.Add(time.Duration(seconds) * time.Second)}
+	}
+
+	collect(trade(10))
+	collect(trade(130))
+	// within the allowed lateness the first block is still open
+	collect(trade(100))
+	if len(blocks) != 0 {
+		t.Fatalf("expected no finalized block, got %d", len(blocks))
+	}
+	collect(trade(150))
+	if len(blocks) != 1 || blocks[0].TradesBlockData.TradesNumber != 2 || !blocks[0].TradesBlockData.BeginTime.Equal(begin) {
+		t.Fatalf("expected the first block with 2 trades, got %v", blocks)
+	}
+	// late trade of the finalized block is published with the next block
+	collect(trade(110))
+	collect(trade(400))
+	if len(blocks) != 2 || len(corrections) != 1 || corrections[0].TradesBlockData.TradesNumber != 1 || !corrections[0].TradesBlockData.BeginTime.Equal(begin) {
+		t.Fatalf("expected a correction of the first block, got %d blocks and corrections %v", len(blocks), corrections)
+	}
+	// beyond the correction window
+	collect(trade(1500))
+	if s.addTrade(trade(50)) {
+		t.Error("expected trade beyond the correction window to be dropped")
+	}
+	collect(trade(1700))
+	if len(corrections) != 1 {
+		t.Errorf("expected trade beyond the correction window to be dropped, got %d corrections", len(corrections))
+	}
+}
@@ -0,0 +1,41 @@
+package dia
+
+import (
+	"strings"
+)
+
+// Identifier returns the key under which @a is stored: blockchain and address for
+// tokens with an address, the symbol otherwise. Hex addresses are compared
+// case-insensitively.
+func (a Asset) Identifier() string {
+	if a.Address == "" {
+		return a.Symbol
+	}
+	address := a.Address
+	if strings.HasPrefix(address, "0x") {
+		address = strings.ToLower(address)
+	}
+	return a.Blockchain + "-" + address
+}
+
+// HasAddress reports whether @a is identified by its address rather than its symbol.
+func (a Asset) HasAddress() bool {
+	return a.Address != ""
+}
+
+// Quote returns the quote asset of @t. Trades without QuoteAsset are identified by their symbol.
+func (t *Trade) Quote() Asset {
+	if t.QuoteAsset != (Asset{}) {
+		return t.QuoteAsset
+	}
+	return Asset{Symbol: t.Symbol}
+}
+
+// Base returns the base asset of @t. Trades without BaseAsset are identified by the
+// base token parsed from their pair.
+func (t *Trade) Base() Asset {
+	if t.BaseAsset != (Asset{}) {
+		return t.BaseAsset
+	}
+	return Asset{Symbol: t.BaseToken()}
+}
@@ -2,6 +2,7 @@ package dia
 
 import (
 	"os/user"
+	"sort"
 	"strings"
 	"time"
 
@@ -55,42 +56,31 @@ const (
 	Ethereum = "Ethereum"
 )
 
+// exchanges holds the metadata of all exchanges known to the system. It is
+// populated through RegisterExchange, usually from init functions of the
+// scrapers package or of in-house scraper packages.
+var exchanges = make(map[string]Exchange)
+
+// RegisterExchange makes @exchange known to all services enumerating exchanges.
+// Registering an exchange name a second time overwrites its metadata.
+func RegisterExchange(exchange Exchange) {
+	exchanges[exchange.Name] = exchange
+}
+
+// GetExchange returns the metadata of the registered exchange @name.
+func GetExchange(name string) (Exchange, bool) {
+	exchange, ok := exchanges[name]
+	return exchange, ok
+}
+
+// Exchanges returns the names of all registered exchanges in alphabetical order.
 func Exchanges() []string {
-	return []string{
-		BalancerExchange,
-		BancorExchange,
-		BinanceExchange,
-		BitBayExchange,
-		BitfinexExchange,
-		BitMaxExchange,
-		BittrexExchange,
-		CoinBaseExchange,
-		CurveFIExchange,
-		CREX24Exchange,
-		DforceExchange,
-		GateIOExchange,
-		GnosisExchange,
-		HitBTCExchange,
-		HuobiExchange,
-		KrakenExchange,
-		KuCoinExchange,
-		KyberExchange,
-		LBankExchange,
-		LoopringExchange,
-		MakerExchange,
-		OKExExchange,
-		PanCakeSwap,
-		QuoineExchange,
-		SimexExchange,
-		STEXExchange,
-		SushiSwapExchange,
-		UniswapExchange,
-		UniswapExchangeV3,
-		ZBExchange,
-		ZeroxExchange,
-		UnknownExchange,
-		DfynNetwork,
+	names := make([]string, 0, len(exchanges))
+	for name := range exchanges {
+		names = append(names, name)
 	}
+	sort.Strings(names)
+	return names
 }
 
 type ConfigApi struct {
@@ -104,9 +94,12 @@ type ConfigConnector struct {
 
 type BlockChain struct {
 	Name                  string
+	ChainID               int64 // EIP-155 chain ID of EVM chains
 	GenesisDate           time.Time
 	NativeToken           string
 	VerificationMechanism VerificationMechanism
+	// Number of blocks on top of a block before its trades are considered final
+	ConfirmationDepth uint64
 }
 
 func GetConfig(exchange string) (*ConfigApi, error) {
@@ -0,0 +1,23 @@
+package dia
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRegisterExchange(t *testing.T) {
+	RegisterExchange(Exchange{Name: "TestExchangeB", Centralized: true})
+	RegisterExchange(Exchange{Name: "TestExchangeA", WatchdogDelay: 60})
+	RegisterExchange(Exchange{Name: "TestExchangeA", WatchdogDelay: 120, RequiresAPIKey: true})
+
+	if got := Exchanges(); !reflect.DeepEqual(got, []string{"TestExchangeA", "TestExchangeB"}) {
+		t.Errorf("Exchanges() = %v", got)
+	}
+	exchange, ok := GetExchange("TestExchangeA")
+	if !ok || exchange.WatchdogDelay != 120 || !exchange.RequiresAPIKey {
+		t.Errorf("GetExchange returned %v, %v", exchange, ok)
+	}
+	if _, ok := GetExchange("TestExchangeC"); ok {
+		t.Error("GetExchange found unregistered exchange")
+	}
+}
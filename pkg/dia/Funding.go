@@ -0,0 +1,84 @@
+package dia
+
+import (
+	"errors"
+	"time"
+)
+
+// FundingIndex is the funding of an underlying across exchanges: the average of the 8 hour
+// funding rates of its perpetuals, weighted by their open interest in USD.
+type FundingIndex struct {
+	Underlying      string
+	Rate8h          float64
+	PredictedRate8h float64
+	OpenInterestUSD float64
+	Time            time.Time
+	Components      []FundingIndexComponent
+}
+
+// FundingIndexComponent is the contribution of a perpetual to a FundingIndex.
+type FundingIndexComponent struct {
+	Exchange        string
+	Contract        string
+	Rate8h          float64
+	PredictedRate8h float64
+	OpenInterestUSD float64
+	Weight          float64
+	Time            time.Time
+}
+
+// FundingRate8h returns the funding rate scaled to a funding interval of 8 hours,
+// the most common one, so that the rates of different exchanges are comparable.
+func (f *FundingRate) FundingRate8h() float64 {
+	return f.scale8h(f.FundingRate)
+}
+
+// PredictedFundingRate8h returns the predicted funding rate scaled to a funding interval of 8 hours.
+func (f *FundingRate) PredictedFundingRate8h() float64 {
+	return f.scale8h(f.PredictedFundingRate)
+}
+
+func (f *FundingRate) scale8h(rate float64) float64 {
+	if f.FundingInterval <= 0 {
+		return rate
+	}
+	return rate * float64(8*time.Hour) / float64(f.FundingInterval)
+}
+
+// NewFundingIndex returns the funding index of @underlying at @t from the latest @rates of its perpetuals.
+// Rates without open interest in USD are left out, unless no rate has one; then all are weighted equally.
+func NewFundingIndex(underlying string, rates []FundingRate, t time.Time) (*FundingIndex, error) {
+	index := &FundingIndex{Underlying: underlying, Time: t}
+	for _, f := range rates {
+		if f.OpenInterestUSD > 0 {
+			index.OpenInterestUSD += f.OpenInterestUSD
+		}
+	}
+	for _, f := range rates {
+		var weight float64
+		switch {
+		case index.OpenInterestUSD > 0 && f.OpenInterestUSD > 0:
+			weight = f.OpenInterestUSD / index.OpenInterestUSD
+		case index.OpenInterestUSD == 0:
+			weight = 1 / float64(len(rates))
+		default:
+			continue
+		}
+		c := FundingIndexComponent{
+			Exchange:        f.Exchange,
+			Contract:        f.Contract,
+			Rate8h:          f.FundingRate8h(),
+			PredictedRate8h: f.PredictedFundingRate8h(),
+			OpenInterestUSD: f.OpenInterestUSD,
+			Weight:          weight,
+			Time:            f.Time,
+		}
+		index.Rate8h += weight * c.Rate8h
+		index.PredictedRate8h += weight * c.PredictedRate8h
+		index.Components = append(index.Components, c)
+	}
+	if len(index.Components) == 0 {
+		return nil, errors.New("no funding rates of " + underlying)
+	}
+	return index, nil
+}
@@ -0,0 +1,41 @@
+package dia
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFundingIndex(t *testing.T) {
+	now := time.Now()
+	rates := []FundingRate{
+		{Exchange: "Bitmex", Contract: "XBTUSD", FundingRate: 0.0001, PredictedFundingRate: 0.0002, FundingInterval: 8 * time.Hour, OpenInterestUSD: 300},
+		{Exchange: FTX, Contract: "BTC-PERP", FundingRate: 0.00005, PredictedFundingRate: -0.00001, FundingInterval: time.Hour, OpenInterestUSD: 100},
+		{Exchange: "Bitmex", Contract: "ETHUSD", FundingRate: 0.01, FundingInterval: 8 * time.Hour},
+	}
+
+	index, err := NewFundingIndex("BTC", rates, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(index.Components) != 2 || index.OpenInterestUSD != 400 {
+		t.Errorf("got %d components with open interest %v, want 2 with 400", len(index.Components), index.OpenInterestUSD)
+	}
+	// FTX pays hourly, its 8 hour rate is 0.0004
+	if math.Abs(index.Rate8h-(0.75*0.0001+0.25*0.0004)) > 1e-12 {
+		t.Errorf("got rate %v", index.Rate8h)
+	}
+	if math.Abs(index.PredictedRate8h-(0.75*0.0002-0.25*0.00008)) > 1e-12 {
+		t.Errorf("got predicted rate %v", index.PredictedRate8h)
+	}
+
+	// without open interest all rates count equally
+	index, err = NewFundingIndex("BTC", rates[2:], now)
+	if err != nil || index.Rate8h != 0.01 || index.Components[0].Weight != 1 {
+		t.Errorf("got %+v, %v without open interest", index, err)
+	}
+
+	if _, err = NewFundingIndex("BTC", nil, now); err == nil {
+		t.Error("expected error without rates")
+	}
+}
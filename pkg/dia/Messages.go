@@ -21,6 +21,7 @@ const (
 	ETHEREUM                                = "Ethereum"
 	FLOW                                    = "Flow"
 	BINANCESMARTCHAIN                       = "BinanceSmartChain"
+	POLYGON                                 = "Polygon"
 )
 
 type VerificationMechanism string
@@ -209,15 +210,17 @@ type EthereumBlockData struct {
 	TxHash      common.Hash        `json:"tx_hash"`
 	ReceiptHash common.Hash        `json:"receipt_hash"`
 	UncleHash   common.Hash        `json:"uncle_hash"`
+	Hash        common.Hash        `json:"hash"`
 	Extra       []byte             `json:"extra"`
 }
 
 type Exchange struct {
-	Name          string
-	Centralized   bool
-	Contract      common.Address
-	BlockChain    BlockChain
-	WatchdogDelay int
+	Name           string
+	Centralized    bool
+	Contract       common.Address
+	BlockChain     BlockChain
+	WatchdogDelay  int
+	RequiresAPIKey bool
 }
 
 type Supply struct {
@@ -229,11 +232,23 @@ type Supply struct {
 	Time              time.Time
 }
 
+// Asset identifies a token by the blockchain it lives on and its address there.
+// Assets traded on centralized exchanges may only carry a Symbol.
+type Asset struct {
+	Symbol     string
+	Name       string
+	Address    string
+	Decimals   uint8
+	Blockchain string
+}
+
 type Pair struct {
 	Symbol      string
 	ForeignName string
 	Exchange    string
 	Ignore      bool
+	QuoteAsset  Asset
+	BaseAsset   Asset
 }
 
 type Pairs []Pair
@@ -248,6 +263,65 @@ type Trade struct {
 	ForeignTradeID    string
 	EstimatedUSDPrice float64 // will be filled by the TradeBlock Service
 	Source            string
+	// Assets of the pair, if known. See Quote() and Base().
+	QuoteAsset Asset
+	BaseAsset  Asset
+}
+
+// Sides of a futures trade, given from the point of view of the taker.
+const (
+	FuturesBuy  = "buy"
+	FuturesSell = "sell"
+)
+
+// FuturesTrade is a trade of a futures contract on Exchange.
+type FuturesTrade struct {
+	Exchange   string
+	Contract   string // name of the contract on the exchange, e.g. XBTUSD
+	Underlying string // e.g. BTC
+	Expiry     time.Time
+	Perpetual  bool    // perpetual contracts have no Expiry
+	Price      float64 // in the quote currency of the contract
+	Size       float64 // number of contracts traded
+	Side       string  // FuturesBuy or FuturesSell, empty if the exchange gave no known side
+	Time       time.Time
+	// ForeignTradeID identifies the trade on the exchange
+	ForeignTradeID string
+}
+
+// FundingRate is the funding of a perpetual futures contract on Exchange, observed at Time.
+type FundingRate struct {
+	Exchange   string
+	Contract   string // name of the contract on the exchange, e.g. XBTUSD
+	Underlying string // e.g. BTC
+	// FundingRate is the latest fixed funding rate, paid at FundingTime.
+	// PredictedFundingRate is the exchange's estimate for the following period.
+	FundingRate          float64
+	PredictedFundingRate float64
+	FundingTime          time.Time
+	FundingInterval      time.Duration
+	// OpenInterest is given in the unit of the exchange, OpenInterestUSD is its notional value.
+	OpenInterest    float64
+	OpenInterestUSD float64
+	MarkPrice       float64
+	Time            time.Time
+}
+
+// OrderBookLevel is a single price level of an order book.
+type OrderBookLevel struct {
+	Price  float64
+	Volume float64 // Quantity of Quote token available at Price
+}
+
+// OrderBookSnapshot contains the top levels of an order book on Source.
+// Bids are sorted by descending price, Asks by ascending price.
+type OrderBookSnapshot struct {
+	Symbol string
+	Pair   string
+	Bids   []OrderBookLevel
+	Asks   []OrderBookLevel
+	Time   time.Time
+	Source string
 }
 
 type ItinToken struct {
@@ -299,11 +373,46 @@ type OptionMetaForward struct {
 	ExpirationTime            time.Time
 }
 
+// OptionGreeks are the implied volatilities and greeks of an option, derived from its order book
+// at ObservationTime, the spot price of its base currency and a risk-free rate.
+type OptionGreeks struct {
+	InstrumentName  string
+	BaseCurrency    string
+	OptionType      OptionType
+	StrikePrice     float64
+	ExpirationTime  time.Time
+	ObservationTime time.Time
+	SpotPrice       float64
+	RiskFreeRate    float64 // annual, continuously compounded
+	// annualized implied volatilities of the bid, ask and mid price, 0 if the price
+	// is missing or violates the no-arbitrage bounds
+	BidIV float64
+	AskIV float64
+	MidIV float64
+	// greeks at the mid volatility, or at the mean of bid and ask volatility if there is no mid.
+	// Vega is given per volatility point, Theta per calendar day.
+	Delta float64
+	Gamma float64
+	Vega  float64
+	Theta float64
+}
+
 type CviDataPoint struct {
 	Timestamp time.Time
 	Value     float64
 }
 
+// VolatilityIndexPoint is a value of the volatility index of Underlying at a constant maturity
+// of Maturity days. Label tells apart the results of different methodologies or option venue sets,
+// e.g. a backtest from a historical recomputation.
+type VolatilityIndexPoint struct {
+	Underlying string
+	Maturity   int
+	Label      string
+	Value      float64
+	Timestamp  time.Time
+}
+
 type DefiProtocol struct {
 	Name                 string
 	Address              string
@@ -349,15 +458,27 @@ type FiltersBlockData struct {
 	EndTime         time.Time
 	FilterPoints    []FilterPoint
 	FiltersNumber   int
+	// ExchangeWeights are the weights of the exchanges in the filters on all exchanges, if exchange rules are configured
+	ExchangeWeights []ExchangeWeight `json:",omitempty"`
 }
 
 type FilterPoint struct {
 	Symbol string
+	Asset  Asset
 	Value  float64
 	Name   string
 	Time   time.Time
 }
 
+// ExchangeWeight is the weight of an exchange in the filters of an asset on all exchanges.
+// Exchanges excluded by the exchange rules have the weight 0.
+type ExchangeWeight struct {
+	Symbol   string
+	Asset    Asset
+	Exchange string
+	Weight   float64
+}
+
 type IndexBlock struct {
 	BlockHash      string
 	IndexBlockData IndexBlockData
@@ -469,6 +590,45 @@ func (e *Trade) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// MarshalBinary -
+func (e *OrderBookSnapshot) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary -
+func (e *OrderBookSnapshot) UnmarshalBinary(data []byte) error {
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalBinary -
+func (e *FuturesTrade) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary -
+func (e *FuturesTrade) UnmarshalBinary(data []byte) error {
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	return nil
+}
+
+// MarshalBinary -
+func (e *OptionOrderbookDatum) MarshalBinary() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// UnmarshalBinary -
+func (e *OptionOrderbookDatum) UnmarshalBinary(data []byte) error {
+	if err := json.Unmarshal(data, &e); err != nil {
+		return err
+	}
+	return nil
+}
+
 // MarshalBinary -
 func (e *TradesBlock) MarshalBinary() ([]byte, error) {
 	return json.Marshal(e)
@@ -0,0 +1,48 @@
+package dia
+
+import (
+	"errors"
+	"math"
+)
+
+// MidPrice returns the arithmetic mean of best bid and best ask
+func (ob *OrderBookSnapshot) MidPrice() (float64, error) {
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return 0, errors.New("order book has an empty side")
+	}
+	return (ob.Bids[0].Price + ob.Asks[0].Price) / 2, nil
+}
+
+// Spread returns the difference between best ask and best bid relative to the mid price
+func (ob *OrderBookSnapshot) Spread() (float64, error) {
+	mid, err := ob.MidPrice()
+	if err != nil {
+		return 0, err
+	}
+	if mid == 0 {
+		return 0, errors.New("zero mid price")
+	}
+	return (ob.Asks[0].Price - ob.Bids[0].Price) / mid, nil
+}
+
+// Depth returns the volume on either side of the book which is offered at a price
+// deviating less than @band from the mid price. @band is a fraction, i.e. 0.02 for 2%.
+func (ob *OrderBookSnapshot) Depth(band float64) (bidDepth float64, askDepth float64, err error) {
+	mid, err := ob.MidPrice()
+	if err != nil {
+		return
+	}
+	for _, level := range ob.Bids {
+		if math.Abs(mid-level.Price) > band*mid {
+			break
+		}
+		bidDepth += level.Volume
+	}
+	for _, level := range ob.Asks {
+		if math.Abs(level.Price-mid) > band*mid {
+			break
+		}
+		askDepth += level.Volume
+	}
+	return
+}
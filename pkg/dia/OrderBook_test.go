@@ -0,0 +1,31 @@
+package dia
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOrderBookMetrics(t *testing.T) {
+	ob := &OrderBookSnapshot{
+		Bids: []OrderBookLevel{{Price: 99, Volume: 1}, {Price: 98.5, Volume: 2}, {Price: 90, Volume: 10}},
+		Asks: []OrderBookLevel{{Price: 101, Volume: 3}, {Price: 101.5, Volume: 4}, {Price: 110, Volume: 10}},
+	}
+
+	mid, err := ob.MidPrice()
+	if err != nil || mid != 100 {
+		t.Errorf("error mid price %v %v", mid, err)
+	}
+	spread, err := ob.Spread()
+	if err != nil || math.Abs(spread-0.02) > 1e-9 {
+		t.Errorf("error spread %v %v", spread, err)
+	}
+	bidDepth, askDepth, err := ob.Depth(0.02)
+	if err != nil || bidDepth != 3 || askDepth != 7 {
+		t.Errorf("error depth %v %v %v", bidDepth, askDepth, err)
+	}
+
+	empty := &OrderBookSnapshot{Bids: ob.Bids}
+	if _, err := empty.Spread(); err == nil {
+		t.Error("expected error for one-sided book")
+	}
+}
@@ -7,6 +7,9 @@ import (
 
 // BaseToken returns the base token of a trading pair
 func (t *Trade) BaseToken() string {
+	if t.BaseAsset.Symbol != "" {
+		return t.BaseAsset.Symbol
+	}
 
 	pair := strings.ToUpper(t.Pair)
 	if len(pair) > 3 {
@@ -51,6 +54,7 @@ func SwapTrade(t Trade) (Trade, error) {
 	baseToken := (&t).BaseToken()
 	t.Symbol = baseToken
 	t.Pair = baseToken + "-" + symbol
+	t.QuoteAsset, t.BaseAsset = t.BaseAsset, t.QuoteAsset
 	t.Volume = -t.Price * t.Volume
 	t.Price = 1 / t.Price
 
@@ -43,3 +43,26 @@ func TestBitfinexAndHitBTCSecondPairAreUSDT(t *testing.T) {
 		t.Errorf("error base token %v", r)
 	}
 }
+
+func TestTradeAssets(t *testing.T) {
+	uni := Asset{Symbol: "UNI", Address: "0x1f9840a85d5aF5bf1D1762F925BDADdC4201F984", Blockchain: Ethereum, Decimals: 18}
+	weth := Asset{Symbol: "WETH", Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Blockchain: Ethereum, Decimals: 18}
+	trade := Trade{Symbol: "UNI", Pair: "UNI-WETH", Price: 0.01, Volume: 10, QuoteAsset: uni, BaseAsset: weth, Source: UniswapExchange}
+	if trade.BaseToken() != "WETH" || trade.Base() != weth || trade.Quote() != uni {
+		t.Errorf("unexpected assets %v and %v", trade.Quote(), trade.Base())
+	}
+	if id := uni.Identifier(); id != "Ethereum-0x1f9840a85d5af5bf1d1762f925bdaddc4201f984" {
+		t.Errorf("unexpected identifier %s", id)
+	}
+	if id := (&Trade{Symbol: "BTC", Pair: "XBTUSD", Source: KrakenExchange}).Quote().Identifier(); id != "BTC" {
+		t.Errorf("unexpected identifier %s of trade without assets", id)
+	}
+
+	swapped, err := SwapTrade(trade)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if swapped.Symbol != "WETH" || swapped.Quote() != weth || swapped.Base() != uni {
+		t.Errorf("assets not swapped: %+v", swapped)
+	}
+}
@@ -0,0 +1,232 @@
+package dia
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+var (
+	// VolSurfaceMoneyness are the strikes over forward of the grid of a VolSurface.
+	VolSurfaceMoneyness = []float64{0.5, 0.6, 0.7, 0.75, 0.8, 0.85, 0.9, 0.95, 1, 1.05, 1.1, 1.15, 1.2, 1.25, 1.3, 1.4, 1.5}
+	// VolSurfaceTenors are the days to expiry of the grid of a VolSurface.
+	VolSurfaceTenors = []float64{7, 14, 30, 60, 90, 180, 270, 365}
+)
+
+const daysPerYear = 365
+
+// VolSurface is the implied volatility surface of the options on BaseCurrency at Time.
+// Vols[i][j] is the annualized volatility at Tenors[i] days and moneyness (strike over forward) Moneyness[j].
+// Within a smile, volatility is interpolated linearly in log-moneyness and extrapolated flat.
+// Between expiries, total variance is interpolated linearly in time; tenors outside of the
+// listed expiries are left out.
+type VolSurface struct {
+	BaseCurrency  string
+	Time          time.Time
+	SpotPrice     float64
+	Moneyness     []float64
+	Tenors        []float64
+	Vols          [][]float64
+	Smiles        []VolSmile
+	TermStructure []VolTermPoint
+	Skew          []VolSkewPoint
+}
+
+// VolSmile are the implied volatilities of the options of an expiry, out of the money where possible.
+type VolSmile struct {
+	ExpirationTime time.Time
+	Tenor          float64 // days to expiry
+	Forward        float64
+	Points         []VolSmilePoint
+}
+
+// VolSmilePoint is the implied volatility at a strike.
+type VolSmilePoint struct {
+	InstrumentName string
+	StrikePrice    float64
+	Moneyness      float64
+	Vol            float64
+}
+
+// VolTermPoint is the at-the-money volatility of an expiry.
+type VolTermPoint struct {
+	ExpirationTime time.Time
+	Tenor          float64
+	ATMVol         float64
+}
+
+// VolSkewPoint is the 25-delta skew of an expiry: the volatilities at the strikes of a put
+// with delta -0.25 and a call with delta 0.25, and the risk reversal Call25Vol - Put25Vol.
+type VolSkewPoint struct {
+	ExpirationTime time.Time
+	Tenor          float64
+	Put25Vol       float64
+	Call25Vol      float64
+	RiskReversal25 float64
+}
+
+// NewVolSurface returns the volatility surface of @baseCurrency at @t from the latest @greeks of its options.
+func NewVolSurface(baseCurrency string, greeks []OptionGreeks, t time.Time) (*VolSurface, error) {
+	surface := &VolSurface{BaseCurrency: baseCurrency, Time: t, Moneyness: VolSurfaceMoneyness}
+	var latest time.Time
+	for _, g := range greeks {
+		if g.ObservationTime.After(latest) {
+			latest = g.ObservationTime
+			surface.SpotPrice = g.SpotPrice
+		}
+	}
+	surface.Smiles = newVolSmiles(greeks, t)
+	if len(surface.Smiles) == 0 {
+		return nil, errors.New("no implied volatilities of " + baseCurrency)
+	}
+
+	for _, smile := range surface.Smiles {
+		surface.TermStructure = append(surface.TermStructure, VolTermPoint{
+			ExpirationTime: smile.ExpirationTime,
+			Tenor:          smile.Tenor,
+			ATMVol:         smile.vol(0),
+		})
+		put25, call25, ok := smile.delta25()
+		if ok {
+			surface.Skew = append(surface.Skew, VolSkewPoint{
+				ExpirationTime: smile.ExpirationTime,
+				Tenor:          smile.Tenor,
+				Put25Vol:       put25,
+				Call25Vol:      call25,
+				RiskReversal25: call25 - put25,
+			})
+		}
+	}
+
+	first, last := surface.Smiles[0], surface.Smiles[len(surface.Smiles)-1]
+	for _, tenor := range VolSurfaceTenors {
+		if tenor < first.Tenor || tenor > last.Tenor {
+			continue
+		}
+		row := make([]float64, len(surface.Moneyness))
+		for j, m := range surface.Moneyness {
+			row[j] = surface.vol(tenor, math.Log(m))
+		}
+		surface.Tenors = append(surface.Tenors, tenor)
+		surface.Vols = append(surface.Vols, row)
+	}
+	return surface, nil
+}
+
+// vol interpolates the total variance of the smiles adjacent to @tenor at log-moneyness @k.
+func (s *VolSurface) vol(tenor float64, k float64) float64 {
+	i := sort.Search(len(s.Smiles), func(i int) bool { return s.Smiles[i].Tenor >= tenor })
+	if s.Smiles[i].Tenor == tenor || i == 0 {
+		return s.Smiles[i].vol(k)
+	}
+	before, after := s.Smiles[i-1], s.Smiles[i]
+	w1 := math.Pow(before.vol(k), 2) * before.Tenor
+	w2 := math.Pow(after.vol(k), 2) * after.Tenor
+	w := w1 + (w2-w1)*(tenor-before.Tenor)/(after.Tenor-before.Tenor)
+	return math.Sqrt(w / tenor)
+}
+
+// newVolSmiles groups the volatilities of @greeks by expiry, sorted by tenor and each by strike.
+// At each strike, the volatility of the out of the money option is used if available.
+func newVolSmiles(greeks []OptionGreeks, t time.Time) []VolSmile {
+	type strikeVols struct {
+		point VolSmilePoint
+		otm   bool
+	}
+	byExpiry := make(map[time.Time]map[float64]strikeVols)
+	forwards := make(map[time.Time]float64)
+	for _, g := range greeks {
+		tenor := g.ExpirationTime.Sub(t).Hours() / 24
+		vol := g.MidIV
+		if vol == 0 && g.BidIV > 0 && g.AskIV > 0 {
+			vol = (g.BidIV + g.AskIV) / 2
+		}
+		if tenor <= 0 || vol <= 0 || g.SpotPrice <= 0 || g.StrikePrice <= 0 {
+			continue
+		}
+		forward := g.SpotPrice * math.Exp(g.RiskFreeRate*tenor/daysPerYear)
+		forwards[g.ExpirationTime] = forward
+		otm := (g.OptionType == CallOption) == (g.StrikePrice >= forward)
+		if byExpiry[g.ExpirationTime] == nil {
+			byExpiry[g.ExpirationTime] = make(map[float64]strikeVols)
+		}
+		if existing, ok := byExpiry[g.ExpirationTime][g.StrikePrice]; ok && existing.otm && !otm {
+			continue
+		}
+		byExpiry[g.ExpirationTime][g.StrikePrice] = strikeVols{
+			point: VolSmilePoint{InstrumentName: g.InstrumentName, StrikePrice: g.StrikePrice, Vol: vol},
+			otm:   otm,
+		}
+	}
+
+	var smiles []VolSmile
+	for expiration, strikes := range byExpiry {
+		smile := VolSmile{
+			ExpirationTime: expiration,
+			Tenor:          expiration.Sub(t).Hours() / 24,
+			Forward:        forwards[expiration],
+		}
+		for _, s := range strikes {
+			s.point.Moneyness = s.point.StrikePrice / smile.Forward
+			smile.Points = append(smile.Points, s.point)
+		}
+		sort.Slice(smile.Points, func(i, j int) bool { return smile.Points[i].StrikePrice < smile.Points[j].StrikePrice })
+		smiles = append(smiles, smile)
+	}
+	sort.Slice(smiles, func(i, j int) bool { return smiles[i].Tenor < smiles[j].Tenor })
+	return smiles
+}
+
+// vol returns the volatility at log-moneyness @k, interpolated linearly and extrapolated flat.
+func (s VolSmile) vol(k float64) float64 {
+	points := s.Points
+	if k <= math.Log(points[0].Moneyness) {
+		return points[0].Vol
+	}
+	for i := 1; i < len(points); i++ {
+		k1, k2 := math.Log(points[i-1].Moneyness), math.Log(points[i].Moneyness)
+		if k <= k2 {
+			return points[i-1].Vol + (points[i].Vol-points[i-1].Vol)*(k-k1)/(k2-k1)
+		}
+	}
+	return points[len(points)-1].Vol
+}
+
+// delta25 returns the volatilities at the strikes of the 25-delta put and call, if both lie within the smile.
+func (s VolSmile) delta25() (put float64, call float64, ok bool) {
+	lo, hi := math.Log(s.Points[0].Moneyness), math.Log(s.Points[len(s.Points)-1].Moneyness)
+	putK, okPut := s.solveDelta(-0.25, lo, hi)
+	callK, okCall := s.solveDelta(0.25, lo, hi)
+	if !okPut || !okCall {
+		return 0, 0, false
+	}
+	return s.vol(putK), s.vol(callK), true
+}
+
+// solveDelta finds the log-moneyness in [@lo, @hi] at which the option has @delta by bisection,
+// calls for positive and puts for negative delta. Delta decreases with the strike for both.
+func (s VolSmile) solveDelta(delta float64, lo float64, hi float64) (float64, bool) {
+	f := func(k float64) float64 {
+		t := s.Tenor / daysPerYear
+		vol := s.vol(k)
+		d1 := (-k + vol*vol*t/2) / (vol * math.Sqrt(t))
+		callDelta := 0.5 * math.Erfc(-d1/math.Sqrt2)
+		if delta < 0 {
+			return callDelta - 1 - delta
+		}
+		return callDelta - delta
+	}
+	if f(lo) < 0 || f(hi) > 0 {
+		return 0, false
+	}
+	for i := 0; i < 100 && hi-lo > 1e-10; i++ {
+		mid := (lo + hi) / 2
+		if f(mid) > 0 {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2, true
+}
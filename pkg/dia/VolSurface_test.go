@@ -0,0 +1,69 @@
+package dia
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestVolSurface(t *testing.T) {
+	now := time.Date(2021, 6, 1, 8, 0, 0, 0, time.UTC)
+	var greeks []OptionGreeks
+	// two expiries with a smile of 0.6 at the money rising by 0.1 per 10% of moneyness, and
+	// in-the-money options with a different volatility, which are to be ignored
+	for _, days := range []int{10, 40} {
+		for _, strike := range []float64{30000, 35000, 40000, 45000, 50000} {
+			vol := 0.6 + math.Abs(strike/40000-1)
+			if days == 40 {
+				vol += 0.1
+			}
+			for _, optionType := range []OptionType{CallOption, PutOption} {
+				g := OptionGreeks{
+					InstrumentName:  "option",
+					OptionType:      optionType,
+					StrikePrice:     strike,
+					ExpirationTime:  now.AddDate(0, 0, days),
+					ObservationTime: now,
+					SpotPrice:       40000,
+					MidIV:           vol,
+				}
+				if (optionType == CallOption) != (strike >= 40000) {
+					g.MidIV = 2
+				}
+				greeks = append(greeks, g)
+			}
+		}
+	}
+
+	surface, err := NewVolSurface("BTC", greeks, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(surface.Smiles) != 2 || len(surface.Smiles[0].Points) != 5 || surface.Smiles[0].Tenor != 10 {
+		t.Fatalf("got smiles %+v", surface.Smiles)
+	}
+	if len(surface.TermStructure) != 2 || math.Abs(surface.TermStructure[0].ATMVol-0.6) > 1e-12 || math.Abs(surface.TermStructure[1].ATMVol-0.7) > 1e-12 {
+		t.Errorf("got term structure %+v", surface.TermStructure)
+	}
+	// only the 14 and 30 day tenors lie within the expiries
+	if len(surface.Tenors) != 2 || surface.Tenors[0] != 14 || surface.Tenors[1] != 30 {
+		t.Fatalf("got tenors %v", surface.Tenors)
+	}
+	atm := 8
+	w := 0.36*10 + (0.49*40-0.36*10)*(30-10)/30.0
+	if math.Abs(surface.Vols[1][atm]-math.Sqrt(w/30)) > 1e-12 {
+		t.Errorf("got interpolated atm vol %v", surface.Vols[1][atm])
+	}
+	// flat extrapolation beyond the lowest strike
+	if math.Abs(surface.Smiles[0].vol(math.Log(0.5))-0.85) > 1e-12 {
+		t.Errorf("got extrapolated vol %v", surface.Smiles[0].vol(math.Log(0.5)))
+	}
+	// the 25-delta strikes of the second expiry lie beyond its highest strike
+	if len(surface.Skew) != 1 || surface.Skew[0].Put25Vol <= 0.6 || surface.Skew[0].Call25Vol <= 0.6 {
+		t.Errorf("got skew %+v", surface.Skew)
+	}
+
+	if _, err = NewVolSurface("BTC", nil, now); err == nil {
+		t.Error("expected error without greeks")
+	}
+}
@@ -48,6 +48,7 @@ func GetBlockDataOnChain(blockNumber int64, client *ethclient.Client) (dia.Block
 	ethblockdata.Time = block.Time()
 	ethblockdata.TxHash = block.TxHash()
 	ethblockdata.UncleHash = block.UncleHash()
+	ethblockdata.Hash = block.Hash()
 
 	blockdata.BlockchainName = dia.ETHEREUM
 	blockdata.BlockNumber = int64(ethblockdata.Number)
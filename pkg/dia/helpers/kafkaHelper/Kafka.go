@@ -36,6 +36,9 @@ const (
 	TopicIndexBlockDaily = 11
 	retryDelay           = 2 * time.Second
 	TopicOptionOrderBook          = 13
+	TopicOrderBooks      = 14
+	TopicFuturesTrades   = 15
+	TopicTradesBlockCorrections = 16
 
 )
 
@@ -51,9 +54,13 @@ func GetTopic(topic int) string {
 
 func getTopic(topic int) string {
 	topicMap := map[int]string{
-		1: "filtersBlock",
-		2: "trades",
-		3: "tradesBlock",
+		1:  "filtersBlock",
+		2:  "trades",
+		3:  "tradesBlock",
+		13: "optionOrderBook",
+		14: "orderBooks",
+		15: "futuresTrades",
+		16: "tradesBlockCorrections",
 	}
 	result, ok := topicMap[topic]
 	if !ok {
@@ -306,12 +313,18 @@ func GetElements(topic int, offset int64, nbElements int) ([]interface{}, error)
 				if err == nil {
 					result = append(result, e)
 				}
-			case TopicTradesBlock:
+			case TopicTradesBlock, TopicTradesBlockCorrections:
 				var e dia.TradesBlock
 				err = e.UnmarshalBinary(b2)
 				if err == nil {
 					result = append(result, e)
 				}
+			case TopicOrderBooks:
+				var e dia.OrderBookSnapshot
+				err = e.UnmarshalBinary(b2)
+				if err == nil {
+					result = append(result, e)
+				}
 			default:
 				return nil, errors.New("Missing case unknown topic in switch... function GetElements / Kafka.go")
 			}
@@ -0,0 +1,140 @@
+// Package metrics defines the prometheus metrics of the trades pipeline, from the
+// collectors to the tradesBlockService and the filtersBlockService.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+)
+
+// Reasons for which the tradesBlockService ignores a trade.
+const (
+	ReasonStablecoinDeviation = "stablecoin_deviation"
+	ReasonMissingBasePrice    = "missing_base_price"
+	ReasonLateBlock           = "late_block"
+	ReasonMedianDeviation     = "median_deviation"
+	ReasonReferenceDeviation  = "reference_deviation"
+)
+
+// Kinds of failed requests of the shared http client.
+const (
+	HTTPError       = "error"
+	HTTPRateLimited = "rate_limited"
+	HTTPServerError = "server_error"
+	HTTPCircuitOpen = "circuit_open"
+)
+
+// Handlings of late trades by the tradesBlockService.
+const (
+	LateOpenBlock  = "open_block"
+	LateCorrection = "correction"
+	LateDropped    = "dropped"
+)
+
+var (
+	// TradesReceived counts the trades received by a collector from its scraper.
+	TradesReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_trades_received_total",
+		Help: "Trades received from the scraper, per exchange and pair.",
+	}, []string{"exchange", "pair"})
+
+	// TradeKafkaLatency is the time from the execution of a trade until it is written to kafka.
+	TradeKafkaLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dia_trade_kafka_latency_seconds",
+		Help:    "Time from the execution of a trade until it is written to kafka.",
+		Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30, 60, 300, 1800},
+	}, []string{"exchange"})
+
+	// WatchdogRestarts counts the restarts of pairs and exchange connections by the collector's supervisor.
+	WatchdogRestarts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_watchdog_restarts_total",
+		Help: "Restarts by the collector's watchdog, per exchange and scope (pair or exchange).",
+	}, []string{"exchange", "scope"})
+
+	// StalePairs is the number of pairs of an exchange which stopped or did not trade within the watchdog delay.
+	StalePairs = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "dia_watchdog_stale_pairs",
+		Help: "Pairs which stopped or did not trade within the watchdog delay, per exchange.",
+	}, []string{"exchange"})
+
+	// TradesIgnored counts the trades ignored by the tradesBlockService.
+	TradesIgnored = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_trades_ignored_total",
+		Help: "Trades ignored by the tradesBlockService, per exchange and reason.",
+	}, []string{"exchange", "reason"})
+
+	// TradesLate counts the trades arriving after the start of a later block, by how they were handled.
+	TradesLate = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_trades_late_total",
+		Help: "Trades arriving after the start of a later block, per exchange and handling (open_block, correction, dropped).",
+	}, []string{"exchange", "handling"})
+
+	// BlockSize is the number of trades of a trades block and of filter points of a filters block.
+	BlockSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dia_block_size",
+		Help:    "Number of trades in a trades block and of filter points in a filters block.",
+		Buckets: prometheus.ExponentialBuckets(1, 4, 10),
+	}, []string{"block"})
+
+	// FilterComputeDuration is the time spent in each kind of filter per trades block.
+	FilterComputeDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dia_filter_compute_seconds",
+		Help:    "Time spent computing a kind of filter for a trades block.",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 10),
+	}, []string{"filter"})
+
+	// FiltersBlockDuration is the time to process a trades block into a filters block.
+	FiltersBlockDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "dia_filters_block_seconds",
+		Help:    "Time to process a trades block into a filters block, including writes to the datastore.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 10),
+	})
+
+	// HTTPRequests counts the requests of the shared http client of pkg/utils, including retries.
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_http_requests_total",
+		Help: "Requests sent by the shared http client, including retries, per host.",
+	}, []string{"host"})
+
+	// HTTPRetries counts the retried requests of the shared http client.
+	HTTPRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_http_retries_total",
+		Help: "Retries of failed requests by the shared http client, per host.",
+	}, []string{"host"})
+
+	// HTTPFailures counts the failed requests of the shared http client by kind.
+	HTTPFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_http_failures_total",
+		Help: "Failed requests of the shared http client, per host and kind (error, rate_limited, server_error, circuit_open).",
+	}, []string{"host", "kind"})
+
+	// HTTPLatency is the time until the response headers of a request of the shared http client arrive.
+	HTTPLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "dia_http_request_seconds",
+		Help:    "Time until the response headers of a request of the shared http client arrive, per host.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 4, 8),
+	}, []string{"host"})
+
+	// DBWriteErrors counts the failed writes to influx and redis.
+	DBWriteErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "dia_db_write_errors_total",
+		Help: "Failed writes to the datastore, per store (influx or redis) and operation.",
+	}, []string{"store", "operation"})
+)
+
+// Handler returns the http handler serving all metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// Serve serves the metrics on /metrics at @addr, e.g. :9090. It is meant to be
+// run in a goroutine and only returns if the server fails.
+func Serve(addr string) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler())
+	log.Error("serve metrics: ", http.ListenAndServe(addr, mux))
+}
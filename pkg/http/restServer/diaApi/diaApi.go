@@ -101,6 +101,35 @@ func (env *Env) GetQuotation(c *gin.Context) {
 	}
 }
 
+// GetAssetQuotation godoc
+// @Summary Get quotation of a token by its address
+// @Description GetAssetQuotation
+// @Tags dia
+// @Accept  json
+// @Produce  json
+// @Param   blockchain     path    string     true        "Name of the blockchain, e.g. Ethereum"
+// @Param   address     path    string     true        "Address of the token"
+// @Success 200 {object} models.Quotation "success"
+// @Failure 404 {object} restApi.APIError "Asset not found"
+// @Failure 500 {object} restApi.APIError "error"
+// @Router /v1/assetQuotation/:blockchain/:address: [get]
+func (env *Env) GetAssetQuotation(c *gin.Context) {
+	asset := dia.Asset{
+		Blockchain: c.Param("blockchain"),
+		Address:    c.Param("address"),
+	}
+	q, err := env.DataStore.GetAssetQuotation(asset)
+	if err != nil {
+		if err == redis.Nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+		} else {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+		}
+	} else {
+		c.JSON(http.StatusOK, q)
+	}
+}
+
 func (env *Env) GetPaxgQuotationOunces(c *gin.Context) {
 	q, err := env.DataStore.GetPaxgQuotationOunces()
 	if err != nil {
@@ -1192,6 +1221,263 @@ func (env *Env) GetStockQuotation(c *gin.Context) {
 	}
 }
 
+// -----------------------------------------------------------------------------
+// ORDER BOOKS
+// -----------------------------------------------------------------------------
+
+// GetOrderBook returns spread and depth of the order book of @pair on @exchange.
+// Without query parameters the most recent snapshot of the last 24h is returned.
+// Optional query parameters dateInit and dateFinal return all snapshots in the time range.
+func (env *Env) GetOrderBook(c *gin.Context) {
+	exchange := c.Param("exchange")
+	pair := c.Param("pair")
+	dateInit := c.DefaultQuery("dateInit", "noRange")
+	dateFinal := c.Query("dateFinal")
+
+	if dateInit == "noRange" {
+		endtime := time.Now()
+		starttime := endtime.AddDate(0, 0, -1)
+		metrics, err := env.DataStore.GetOrderBookMetrics(exchange, pair, starttime, endtime)
+		if err != nil {
+			restApi.SendError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if len(metrics) == 0 {
+			restApi.SendError(c, http.StatusNotFound, errors.New("no order book found"))
+			return
+		}
+		c.JSON(http.StatusOK, metrics[0])
+		return
+	}
+
+	starttime, err := utils.StrToUnixtime(dateInit)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	endtime, err := utils.StrToUnixtime(dateFinal)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	metrics, err := env.DataStore.GetOrderBookMetrics(exchange, pair, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, metrics)
+}
+
+// -----------------------------------------------------------------------------
+// FUTURES
+// -----------------------------------------------------------------------------
+
+// GetFuturesTrades returns the trades of the futures @contract on @exchange, latest first.
+// Without query parameters the trades of the last hour are returned.
+// Optional query parameters dateInit and dateFinal return all trades in the time range.
+func (env *Env) GetFuturesTrades(c *gin.Context) {
+	exchange := c.Param("exchange")
+	contract := c.Param("contract")
+	dateInit := c.DefaultQuery("dateInit", "noRange")
+	dateFinal := c.Query("dateFinal")
+
+	endtime := time.Now()
+	starttime := endtime.Add(-time.Hour)
+	if dateInit != "noRange" {
+		var err error
+		starttime, err = utils.StrToUnixtime(dateInit)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+		endtime, err = utils.StrToUnixtime(dateFinal)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	trades, err := env.DataStore.GetFuturesTrades(exchange, contract, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, trades)
+}
+
+// GetFundingRates returns the funding rates and open interest of the perpetual @contract on @exchange, latest first.
+// Without query parameters the funding of the last 24 hours is returned.
+// Optional query parameters dateInit and dateFinal return the funding in the time range.
+func (env *Env) GetFundingRates(c *gin.Context) {
+	exchange := c.Param("exchange")
+	contract := c.Param("contract")
+	dateInit := c.DefaultQuery("dateInit", "noRange")
+	dateFinal := c.Query("dateFinal")
+
+	endtime := time.Now()
+	starttime := endtime.AddDate(0, 0, -1)
+	if dateInit != "noRange" {
+		var err error
+		starttime, err = utils.StrToUnixtime(dateInit)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+		endtime, err = utils.StrToUnixtime(dateFinal)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	rates, err := env.DataStore.GetFundingRates(exchange, contract, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, rates)
+}
+
+// GetFundingIndex returns the funding index of @underlying across exchanges, the average of the
+// 8 hour funding rates of its perpetuals weighted by open interest in USD.
+// Optional query parameter time returns the index at the given unix time.
+func (env *Env) GetFundingIndex(c *gin.Context) {
+	underlying := c.Param("underlying")
+	date := c.DefaultQuery("time", "noRange")
+
+	timestamp := time.Now()
+	if date != "noRange" {
+		var err error
+		timestamp, err = utils.StrToUnixtime(date)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	index, err := env.DataStore.GetFundingIndex(underlying, timestamp)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, index)
+}
+
+// -----------------------------------------------------------------------------
+// OPTIONS
+// -----------------------------------------------------------------------------
+
+// GetLatestOptionGreeks returns the latest implied volatilities and greeks of all options on @baseCurrency.
+// Optional query parameter time returns the greeks at the given unix time.
+func (env *Env) GetLatestOptionGreeks(c *gin.Context) {
+	baseCurrency := c.Param("baseCurrency")
+	date := c.DefaultQuery("time", "noRange")
+
+	timestamp := time.Now()
+	if date != "noRange" {
+		var err error
+		timestamp, err = utils.StrToUnixtime(date)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	greeks, err := env.DataStore.GetLatestOptionGreeks(baseCurrency, timestamp)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, greeks)
+}
+
+// GetOptionGreeks returns the implied volatilities and greeks of the option @instrument, latest first.
+// Without query parameters the greeks of the last 24 hours are returned.
+// Optional query parameters dateInit and dateFinal return the greeks in the time range.
+func (env *Env) GetOptionGreeks(c *gin.Context) {
+	instrument := c.Param("instrument")
+	dateInit := c.DefaultQuery("dateInit", "noRange")
+	dateFinal := c.Query("dateFinal")
+
+	endtime := time.Now()
+	starttime := endtime.AddDate(0, 0, -1)
+	if dateInit != "noRange" {
+		var err error
+		starttime, err = utils.StrToUnixtime(dateInit)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+		endtime, err = utils.StrToUnixtime(dateFinal)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	greeks, err := env.DataStore.GetOptionGreeks(instrument, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, greeks)
+}
+
+// GetVolSurface returns the implied volatility surface of the options on @baseCurrency on a grid of
+// moneyness and tenor, together with the at-the-money term structure and the 25-delta skew.
+// Optional query parameter time returns the surface at the given unix time.
+func (env *Env) GetVolSurface(c *gin.Context) {
+	baseCurrency := c.Param("baseCurrency")
+	date := c.DefaultQuery("time", "noRange")
+
+	timestamp := time.Now()
+	if date != "noRange" {
+		var err error
+		timestamp, err = utils.StrToUnixtime(date)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	surface, err := env.DataStore.GetVolSurface(baseCurrency, timestamp)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	c.JSON(http.StatusOK, surface)
+}
+
+// GetVolatilityIndex returns the values of the volatility index of @underlying in a time range,
+// the last 24h by default. Optional query parameters maturity (in days, default 30) and label
+// (default "default") select the index.
+func (env *Env) GetVolatilityIndex(c *gin.Context) {
+	underlying := c.Param("underlying")
+	label := c.DefaultQuery("label", "default")
+	dateInit := c.DefaultQuery("dateInit", "noRange")
+	dateFinal := c.Query("dateFinal")
+
+	maturity, err := strconv.Atoi(c.DefaultQuery("maturity", "30"))
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	endtime := time.Now()
+	starttime := endtime.AddDate(0, 0, -1)
+	if dateInit != "noRange" {
+		starttime, err = utils.StrToUnixtime(dateInit)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+		endtime, err = utils.StrToUnixtime(dateFinal)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotFound, err)
+			return
+		}
+	}
+	values, err := env.DataStore.GetVolatilityIndexInflux(underlying, maturity, label, starttime, endtime)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, values)
+}
+
 // -----------------------------------------------------------------------------
 // FOREIGN QUOTATIONS
 // -----------------------------------------------------------------------------
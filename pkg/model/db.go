@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
 	"github.com/go-redis/redis"
 	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
 	log "github.com/sirupsen/logrus"
@@ -22,6 +23,9 @@ type Datastore interface {
 	SetPriceEUR(symbol string, price float64) error
 	GetPriceUSD(symbol string) (float64, error)
 	GetQuotation(symbol string) (*Quotation, error)
+	SetAssetPriceUSD(asset dia.Asset, price float64) error
+	GetAssetPriceUSD(asset dia.Asset) (float64, error)
+	GetAssetQuotation(asset dia.Asset) (*Quotation, error)
 	SetQuotation(quotation *Quotation) error
 	SetQuotationEUR(quotation *Quotation) error
 	GetLatestSupply(string) (*dia.Supply, error)
@@ -40,14 +44,19 @@ type Datastore interface {
 	GetLastTradeTimeForExchange(symbol string, exchange string) (*time.Time, error)
 	SetLastTradeTimeForExchange(symbol string, exchange string, t time.Time) error
 	SaveTradeInflux(t *dia.Trade) error
+	SaveQuarantinedTradeInflux(t *dia.Trade, reason string, reference float64) error
 	GetTradeInflux(string, string, time.Time) (*dia.Trade, error)
 	SaveFilterInflux(filter string, symbol string, exchange string, value float64, t time.Time) error
 	GetLastTrades(symbol string, exchange string, maxTrades int) ([]dia.Trade, error)
 	GetLastTradesAllExchanges(string, int) ([]dia.Trade, error)
 	GetAllTrades(t time.Time, maxTrades int) ([]dia.Trade, error)
+	GetTradesInflux(symbols []string, starttime time.Time, endtime time.Time) ([]dia.Trade, error)
+	MarkTradeSeen(t *dia.Trade, window time.Duration) (bool, error)
 	Flush() error
 	GetFilterPoints(filter string, exchange string, symbol string, scale string, starttime time.Time, endtime time.Time) (*Points, error)
 	SetFilter(filterName string, symbol string, exchange string, value float64, t time.Time) error
+	SaveRecomputedFilterInflux(run string, filter string, symbol string, exchange string, value float64, t time.Time) error
+	GetFilterValuesInflux(exchange string, starttime time.Time, endtime time.Time) ([]dia.FilterPoint, error)
 	GetLastPriceBefore(symbol string, filter string, exchange string, timestamp time.Time) (Price, error)
 	SetAvailablePairsForExchange(exchange string, pairs []dia.Pair) error
 	GetAvailablePairsForExchange(exchange string) ([]dia.Pair, error)
@@ -132,6 +141,28 @@ type Datastore interface {
 	SetStockQuotation(sq StockQuotation) error
 	GetStockQuotation(source string, symbol string, timeInit time.Time, timeFinal time.Time) ([]StockQuotation, error)
 	GetStockSymbols() (map[Stock]string, error)
+
+	// Order book methods
+	SaveOrderBookSnapshotInflux(ob *dia.OrderBookSnapshot) error
+	GetOrderBookMetrics(exchange string, pair string, starttime time.Time, endtime time.Time) ([]OrderBookMetrics, error)
+
+	// Futures methods
+	SaveFuturesTradeInflux(t *dia.FuturesTrade) error
+	GetFuturesTrades(exchange string, contract string, starttime time.Time, endtime time.Time) ([]dia.FuturesTrade, error)
+	SaveFundingRateInflux(f *dia.FundingRate) error
+	GetFundingRates(exchange string, contract string, starttime time.Time, endtime time.Time) ([]dia.FundingRate, error)
+	GetFundingIndex(underlying string, t time.Time) (*dia.FundingIndex, error)
+
+	// Option greeks methods
+	SaveOptionGreeksInflux(g dia.OptionGreeks) error
+	GetOptionGreeks(instrumentName string, starttime time.Time, endtime time.Time) ([]dia.OptionGreeks, error)
+	GetLatestOptionGreeks(baseCurrency string, t time.Time) ([]dia.OptionGreeks, error)
+	GetVolSurface(baseCurrency string, t time.Time) (*dia.VolSurface, error)
+
+	// Volatility index methods
+	GetOptionOrderbookData(starttime time.Time, endtime time.Time) ([]dia.OptionOrderbookDatum, error)
+	SaveVolatilityIndexInflux(v dia.VolatilityIndexPoint) error
+	GetVolatilityIndexInflux(underlying string, maturity int, label string, starttime time.Time, endtime time.Time) ([]dia.VolatilityIndexPoint, error)
 }
 
 const (
@@ -149,7 +180,9 @@ type DB struct {
 const (
 	influxDbName                         = "dia"
 	influxDbTradesTable                  = "trades"
+	influxDbTradesQuarantineTable        = "tradesQuarantine"
 	influxDbFiltersTable                 = "filters"
+	influxDbFiltersRecomputedTable       = "filtersRecomputed"
 	influxDbOptionsTable                 = "options"
 	influxDbCVITable                     = "cvi"
 	influxDbETHCVITable                  = "cviETH"
@@ -162,6 +195,11 @@ const (
 	influxDbCryptoIndexConstituentsTable = "cryptoindexconstituents"
 	influxDbGithubCommitTable            = "githubcommits"
 	influxDbStockQuotationsTable         = "stockquotations"
+	influxDbOrderBooksTable              = "orderbooks"
+	influxDbFuturesTradesTable           = "futuresTrades"
+	influxDbFundingRatesTable            = "fundingRates"
+	influxDbOptionGreeksTable            = "optionGreeks"
+	influxDbVolatilityIndexTable         = "volatilityIndex"
 )
 
 // queryInfluxDB convenience function to query the database
@@ -221,6 +259,7 @@ func NewDataStoreWithOptions(withRedis bool, withInflux bool) (*DB, error) {
 			Password: "", // no password set
 			DB:       0,  // use default DB
 		})
+		countRedisWriteErrors(r)
 
 		pong2, err := r.Ping().Result()
 		if err != nil {
@@ -251,6 +290,39 @@ func NewDataStoreWithOptions(withRedis bool, withInflux bool) (*DB, error) {
 	return &DB{r, ci, bp, 0}, nil
 }
 
+// redisWriteCommands are the redis commands counted in the write errors metric.
+var redisWriteCommands = map[string]bool{
+	"set": true, "setex": true, "setnx": true, "mset": true, "del": true, "expire": true,
+	"hset": true, "hmset": true, "hdel": true, "sadd": true, "srem": true,
+	"zadd": true, "zrem": true, "zremrangebyscore": true, "zremrangebyrank": true,
+	"lpush": true, "rpush": true, "ltrim": true, "incr": true, "incrby": true,
+}
+
+// countRedisWriteErrors counts the failed write commands of @r, including those of pipelines.
+func countRedisWriteErrors(r *redis.Client) {
+	count := func(cmd redis.Cmder) {
+		if err := cmd.Err(); err != nil && err != redis.Nil && redisWriteCommands[cmd.Name()] {
+			metrics.DBWriteErrors.WithLabelValues("redis", cmd.Name()).Inc()
+		}
+	}
+	r.WrapProcess(func(process func(redis.Cmder) error) func(redis.Cmder) error {
+		return func(cmd redis.Cmder) error {
+			err := process(cmd)
+			count(cmd)
+			return err
+		}
+	})
+	r.WrapProcessPipeline(func(process func([]redis.Cmder) error) func([]redis.Cmder) error {
+		return func(cmds []redis.Cmder) error {
+			err := process(cmds)
+			for _, cmd := range cmds {
+				count(cmd)
+			}
+			return err
+		}
+	})
+}
+
 func createBatchInflux() clientInfluxdb.BatchPoints {
 	bp, err := clientInfluxdb.NewBatchPoints(clientInfluxdb.BatchPointsConfig{
 		Database:  influxDbName,
@@ -294,6 +366,7 @@ func (db *DB) WriteBatchInflux() error {
 	err := db.influxClient.Write(db.influxBatchPoints)
 	if err != nil {
 		log.Errorln("WriteBatchInflux: ", err)
+		metrics.DBWriteErrors.WithLabelValues("influx", "write").Inc()
 	} else {
 		db.influxPointsInBatch = 0
 	}
@@ -442,25 +515,54 @@ func (db *DB) GetVolumeInflux(symbol string, starttime time.Time, endtime time.T
 
 func (db *DB) SaveTradeInflux(t *dia.Trade) error {
 	// Create a point and add to batch
+	tags, fields := tradeTagsAndFields(t)
+	pt, err := clientInfluxdb.NewPoint(influxDbTradesTable, tags, fields, t.Time)
+	if err != nil {
+		log.Errorln("NewTradeInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// SaveQuarantinedTradeInflux adds a trade rejected for @reason to the influx batch of quarantined trades.
+// @reference is the price the trade was compared with, or 0.
+func (db *DB) SaveQuarantinedTradeInflux(t *dia.Trade, reason string, reference float64) error {
+	tags, fields := tradeTagsAndFields(t)
+	tags["reason"] = reason
+	fields["reference"] = reference
+	pt, err := clientInfluxdb.NewPoint(influxDbTradesQuarantineTable, tags, fields, t.Time)
+	if err != nil {
+		log.Errorln("NewQuarantinedTradeInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// tradeTagsAndFields returns the tags and fields @t is stored with in influx.
+func tradeTagsAndFields(t *dia.Trade) (map[string]string, map[string]interface{}) {
 	tags := map[string]string{
 		"symbol":   t.Symbol,
 		"exchange": t.Source,
 		"pair":     t.Pair,
 	}
+	// series of tokens sharing a symbol are told apart by their address
+	if quote := t.Quote(); quote.HasAddress() {
+		tags["quoteAddress"] = quote.Address
+		tags["quoteBlockchain"] = quote.Blockchain
+	}
+	if base := t.Base(); base.HasAddress() {
+		tags["baseAddress"] = base.Address
+		tags["baseBlockchain"] = base.Blockchain
+	}
 	fields := map[string]interface{}{
 		"price":             t.Price,
 		"volume":            t.Volume,
 		"estimatedUSDPrice": t.EstimatedUSDPrice,
 		"foreignTradeID":    t.ForeignTradeID,
 	}
-
-	pt, err := clientInfluxdb.NewPoint(influxDbTradesTable, tags, fields, t.Time)
-	if err != nil {
-		log.Errorln("NewTradeInflux:", err)
-	} else {
-		db.addPoint(pt)
-	}
-	return err
+	return tags, fields
 }
 
 func (db *DB) GetTradeInflux(symbol string, exchange string, timestamp time.Time) (*dia.Trade, error) {
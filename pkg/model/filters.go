@@ -1,7 +1,13 @@
 package models
 
 import (
+	"encoding/json"
+	"fmt"
 	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
 )
 
 func (db *DB) SetFilter(filter string, symbol string, exchange string, volume float64, t time.Time) error {
@@ -9,3 +15,50 @@ func (db *DB) SetFilter(filter string, symbol string, exchange string, volume fl
 	err := db.setZSETValue(getKeyFilterZSET(getKey(filter, symbol, exchange)), volume, t.Unix(), BiggestWindow)
 	return err
 }
+
+// SaveRecomputedFilterInflux adds a filter value of the recomputation @run to the influx batch. Recomputed
+// values are kept apart from the production values in their own table.
+func (db *DB) SaveRecomputedFilterInflux(run string, filter string, symbol string, exchange string, value float64, t time.Time) error {
+	tags := map[string]string{"run": run, "filter": filter, "symbol": symbol, "exchange": exchange}
+	fields := map[string]interface{}{
+		"value":        value,
+		"allExchanges": exchange == "",
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbFiltersRecomputedTable, tags, fields, t)
+	if err != nil {
+		log.Errorln("NewRecomputedFilterInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// GetFilterValuesInflux returns the values of all filters on @exchange, or on all exchanges if
+// @exchange is empty, in the time range [@starttime, @endtime), ordered by time per filter and symbol.
+func (db *DB) GetFilterValuesInflux(exchange string, starttime time.Time, endtime time.Time) ([]dia.FilterPoint, error) {
+	retval := []dia.FilterPoint{}
+	q := fmt.Sprintf("SELECT value FROM %s WHERE exchange='%s' and time>=%d and time<%d group by filter, symbol",
+		influxDbFiltersTable, exchange, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return retval, err
+	}
+	if len(res) == 0 {
+		return retval, nil
+	}
+	for _, series := range res[0].Series {
+		for _, row := range series.Values {
+			fp := dia.FilterPoint{Name: series.Tags["filter"], Symbol: series.Tags["symbol"]}
+			fp.Time, err = time.Parse(time.RFC3339, row[0].(string))
+			if err != nil {
+				return retval, err
+			}
+			fp.Value, err = row[1].(json.Number).Float64()
+			if err != nil {
+				return retval, err
+			}
+			retval = append(retval, fp)
+		}
+	}
+	return retval, nil
+}
@@ -0,0 +1,219 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// futuresTradeColumns are the columns of the futures trades table parsed by parseFuturesTrade, in this order after time.
+const futuresTradeColumns = "price,size,expiry,foreignTradeID,\"underlying\",\"side\",\"perpetual\""
+
+// SaveFuturesTradeInflux adds a futures trade to the influx batch, which is written on Flush.
+func (db *DB) SaveFuturesTradeInflux(t *dia.FuturesTrade) error {
+	tags := map[string]string{
+		"exchange":   t.Exchange,
+		"contract":   t.Contract,
+		"underlying": t.Underlying,
+		"side":       t.Side,
+		"perpetual":  strconv.FormatBool(t.Perpetual),
+	}
+	var expiry int64
+	if !t.Perpetual && !t.Expiry.IsZero() {
+		expiry = t.Expiry.Unix()
+	}
+	fields := map[string]interface{}{
+		"price":          t.Price,
+		"size":           t.Size,
+		"expiry":         expiry,
+		"foreignTradeID": t.ForeignTradeID,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbFuturesTradesTable, tags, fields, t.Time)
+	if err != nil {
+		log.Errorln("NewFuturesTradeInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// GetFuturesTrades returns the trades of @contract on @exchange in the time range (@starttime, @endtime], latest first.
+func (db *DB) GetFuturesTrades(exchange string, contract string, starttime time.Time, endtime time.Time) ([]dia.FuturesTrade, error) {
+	trades := []dia.FuturesTrade{}
+	query := "SELECT %s FROM %s WHERE \"exchange\"='%s' and \"contract\"='%s' and time>%d and time<=%d order by time desc"
+	q := fmt.Sprintf(query, futuresTradeColumns, influxDbFuturesTradesTable, exchange, contract, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return trades, err
+	}
+	if len(res) > 0 && len(res[0].Series) > 0 {
+		for _, row := range res[0].Series[0].Values {
+			t, err := parseFuturesTrade(row)
+			if err != nil {
+				return trades, err
+			}
+			t.Exchange = exchange
+			t.Contract = contract
+			trades = append(trades, t)
+		}
+	}
+	return trades, nil
+}
+
+func parseFuturesTrade(row []interface{}) (t dia.FuturesTrade, err error) {
+	if len(row) < 8 {
+		err = fmt.Errorf("futures trade row %v too short", row)
+		return
+	}
+	t.Time, err = time.Parse(time.RFC3339, row[0].(string))
+	if err != nil {
+		return
+	}
+	for i, f := range []*float64{&t.Price, &t.Size} {
+		if *f, err = row[i+1].(json.Number).Float64(); err != nil {
+			return
+		}
+	}
+	expiry, err := row[3].(json.Number).Int64()
+	if err != nil {
+		return
+	}
+	if expiry > 0 {
+		t.Expiry = time.Unix(expiry, 0).UTC()
+	}
+	t.ForeignTradeID, _ = row[4].(string)
+	t.Underlying, _ = row[5].(string)
+	t.Side, _ = row[6].(string)
+	perpetual, _ := row[7].(string)
+	t.Perpetual = perpetual == "true"
+	return
+}
+
+// fundingRateColumns are the columns of the funding rates table parsed by parseFundingRate, in this order after time.
+const fundingRateColumns = "fundingRate,predictedFundingRate,fundingTime,fundingInterval,openInterest,openInterestUSD,markPrice,\"underlying\",\"exchange\",\"contract\""
+
+// fundingIndexMaxAge is the maximal age of a funding rate to be part of a funding index.
+const fundingIndexMaxAge = 10 * time.Minute
+
+// SaveFundingRateInflux writes the funding of a perpetual to influx.
+func (db *DB) SaveFundingRateInflux(f *dia.FundingRate) error {
+	tags := map[string]string{
+		"exchange":   f.Exchange,
+		"contract":   f.Contract,
+		"underlying": f.Underlying,
+	}
+	var fundingTime int64
+	if !f.FundingTime.IsZero() {
+		fundingTime = f.FundingTime.Unix()
+	}
+	fields := map[string]interface{}{
+		"fundingRate":          f.FundingRate,
+		"predictedFundingRate": f.PredictedFundingRate,
+		"fundingTime":          fundingTime,
+		"fundingInterval":      int64(f.FundingInterval / time.Second),
+		"openInterest":         f.OpenInterest,
+		"openInterestUSD":      f.OpenInterestUSD,
+		"markPrice":            f.MarkPrice,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbFundingRatesTable, tags, fields, f.Time)
+	if err != nil {
+		log.Errorln("NewFundingRateInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	err = db.WriteBatchInflux()
+	if err != nil {
+		log.Errorln("Write influx batch: ", err)
+	}
+	return err
+}
+
+// GetFundingRates returns the funding of @contract on @exchange in the time range (@starttime, @endtime], latest first.
+func (db *DB) GetFundingRates(exchange string, contract string, starttime time.Time, endtime time.Time) ([]dia.FundingRate, error) {
+	query := "SELECT %s FROM %s WHERE \"exchange\"='%s' and \"contract\"='%s' and time>%d and time<=%d order by time desc"
+	q := fmt.Sprintf(query, fundingRateColumns, influxDbFundingRatesTable, exchange, contract, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return []dia.FundingRate{}, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return []dia.FundingRate{}, nil
+	}
+	return parseFundingRates(res[0].Series[0].Values)
+}
+
+// GetFundingIndex returns the funding index of @underlying at @t, computed from the latest
+// funding of each perpetual of @underlying in the ten minutes before @t.
+func (db *DB) GetFundingIndex(underlying string, t time.Time) (*dia.FundingIndex, error) {
+	query := "SELECT %s FROM %s WHERE \"underlying\"='%s' and time>%d and time<=%d group by \"exchange\",\"contract\" order by time desc limit 1"
+	q := fmt.Sprintf(query, fundingRateColumns, influxDbFundingRatesTable, underlying, t.Add(-fundingIndexMaxAge).UnixNano(), t.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return nil, err
+	}
+	rates := []dia.FundingRate{}
+	if len(res) > 0 {
+		for _, series := range res[0].Series {
+			latest, err := parseFundingRates(series.Values)
+			if err != nil {
+				return nil, err
+			}
+			rates = append(rates, latest...)
+		}
+	}
+	return dia.NewFundingIndex(underlying, rates, t)
+}
+
+func parseFundingRates(rows [][]interface{}) ([]dia.FundingRate, error) {
+	rates := []dia.FundingRate{}
+	for _, row := range rows {
+		f, err := parseFundingRate(row)
+		if err != nil {
+			return rates, err
+		}
+		rates = append(rates, f)
+	}
+	return rates, nil
+}
+
+func parseFundingRate(row []interface{}) (f dia.FundingRate, err error) {
+	if len(row) < 11 {
+		err = fmt.Errorf("funding rate row %v too short", row)
+		return
+	}
+	f.Time, err = time.Parse(time.RFC3339, row[0].(string))
+	if err != nil {
+		return
+	}
+	for i, v := range []*float64{&f.FundingRate, &f.PredictedFundingRate} {
+		if *v, err = row[i+1].(json.Number).Float64(); err != nil {
+			return
+		}
+	}
+	fundingTime, err := row[3].(json.Number).Int64()
+	if err != nil {
+		return
+	}
+	if fundingTime > 0 {
+		f.FundingTime = time.Unix(fundingTime, 0).UTC()
+	}
+	interval, err := row[4].(json.Number).Int64()
+	if err != nil {
+		return
+	}
+	f.FundingInterval = time.Duration(interval) * time.Second
+	for i, v := range []*float64{&f.OpenInterest, &f.OpenInterestUSD, &f.MarkPrice} {
+		if *v, err = row[i+5].(json.Number).Float64(); err != nil {
+			return
+		}
+	}
+	f.Underlying, _ = row[8].(string)
+	f.Exchange, _ = row[9].(string)
+	f.Contract, _ = row[10].(string)
+	return
+}
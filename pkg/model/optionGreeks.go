@@ -0,0 +1,138 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// optionGreeksColumns are the columns of the option greeks table parsed by parseOptionGreeks, in this order after time.
+const optionGreeksColumns = "strikePrice,expirationTime,spotPrice,riskFreeRate,bidIV,askIV,midIV,delta,gamma,vega,theta,\"instrumentName\",\"baseCurrency\",\"optionType\""
+
+// optionGreeksMaxAge is the maximal age of the greeks returned as latest greeks of an option.
+const optionGreeksMaxAge = time.Hour
+
+// SaveOptionGreeksInflux adds the greeks of an option to the influx batch, which is written on Flush.
+func (db *DB) SaveOptionGreeksInflux(g dia.OptionGreeks) error {
+	tags := map[string]string{
+		"instrumentName": g.InstrumentName,
+		"baseCurrency":   g.BaseCurrency,
+		"optionType":     strconv.Itoa(int(g.OptionType)),
+	}
+	fields := map[string]interface{}{
+		"strikePrice":    g.StrikePrice,
+		"expirationTime": g.ExpirationTime.Unix(),
+		"spotPrice":      g.SpotPrice,
+		"riskFreeRate":   g.RiskFreeRate,
+		"bidIV":          g.BidIV,
+		"askIV":          g.AskIV,
+		"midIV":          g.MidIV,
+		"delta":          g.Delta,
+		"gamma":          g.Gamma,
+		"vega":           g.Vega,
+		"theta":          g.Theta,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbOptionGreeksTable, tags, fields, g.ObservationTime)
+	if err != nil {
+		log.Errorln("NewOptionGreeksInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+	return err
+}
+
+// GetOptionGreeks returns the greeks of the option @instrumentName in the time range (@starttime, @endtime], latest first.
+func (db *DB) GetOptionGreeks(instrumentName string, starttime time.Time, endtime time.Time) ([]dia.OptionGreeks, error) {
+	query := "SELECT %s FROM %s WHERE \"instrumentName\"='%s' and time>%d and time<=%d order by time desc"
+	q := fmt.Sprintf(query, optionGreeksColumns, influxDbOptionGreeksTable, instrumentName, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return []dia.OptionGreeks{}, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return []dia.OptionGreeks{}, nil
+	}
+	return parseOptionGreeksRows(res[0].Series[0].Values)
+}
+
+// GetLatestOptionGreeks returns the latest greeks of all options on @baseCurrency in the hour before @t.
+func (db *DB) GetLatestOptionGreeks(baseCurrency string, t time.Time) ([]dia.OptionGreeks, error) {
+	query := "SELECT %s FROM %s WHERE \"baseCurrency\"='%s' and time>%d and time<=%d group by \"instrumentName\" order by time desc limit 1"
+	q := fmt.Sprintf(query, optionGreeksColumns, influxDbOptionGreeksTable, baseCurrency, t.Add(-optionGreeksMaxAge).UnixNano(), t.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return []dia.OptionGreeks{}, err
+	}
+	greeks := []dia.OptionGreeks{}
+	if len(res) > 0 {
+		for _, series := range res[0].Series {
+			latest, err := parseOptionGreeksRows(series.Values)
+			if err != nil {
+				return greeks, err
+			}
+			greeks = append(greeks, latest...)
+		}
+	}
+	return greeks, nil
+}
+
+func parseOptionGreeksRows(rows [][]interface{}) ([]dia.OptionGreeks, error) {
+	greeks := []dia.OptionGreeks{}
+	for _, row := range rows {
+		g, err := parseOptionGreeks(row)
+		if err != nil {
+			return greeks, err
+		}
+		greeks = append(greeks, g)
+	}
+	return greeks, nil
+}
+
+func parseOptionGreeks(row []interface{}) (g dia.OptionGreeks, err error) {
+	if len(row) < 15 {
+		err = fmt.Errorf("option greeks row %v too short", row)
+		return
+	}
+	g.ObservationTime, err = time.Parse(time.RFC3339, row[0].(string))
+	if err != nil {
+		return
+	}
+	g.StrikePrice, err = row[1].(json.Number).Float64()
+	if err != nil {
+		return
+	}
+	expiration, err := row[2].(json.Number).Int64()
+	if err != nil {
+		return
+	}
+	g.ExpirationTime = time.Unix(expiration, 0).UTC()
+	for i, v := range []*float64{&g.SpotPrice, &g.RiskFreeRate, &g.BidIV, &g.AskIV, &g.MidIV, &g.Delta, &g.Gamma, &g.Vega, &g.Theta} {
+		if *v, err = row[i+3].(json.Number).Float64(); err != nil {
+			return
+		}
+	}
+	g.InstrumentName, _ = row[12].(string)
+	g.BaseCurrency, _ = row[13].(string)
+	optionType, _ := row[14].(string)
+	t, err := strconv.Atoi(optionType)
+	if err != nil {
+		return
+	}
+	g.OptionType = dia.OptionType(t)
+	return
+}
+
+// GetVolSurface returns the volatility surface of the options on @baseCurrency at @t, built
+// from their latest implied volatilities in the hour before @t.
+func (db *DB) GetVolSurface(baseCurrency string, t time.Time) (*dia.VolSurface, error) {
+	greeks, err := db.GetLatestOptionGreeks(baseCurrency, t)
+	if err != nil {
+		return nil, err
+	}
+	return dia.NewVolSurface(baseCurrency, greeks, t)
+}
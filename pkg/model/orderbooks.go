@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// Depth of an order book is measured within this fraction around the mid price.
+const orderBookDepthBand = 0.02
+
+// SaveOrderBookSnapshotInflux stores the metrics and levels of an order book snapshot to influx.
+func (db *DB) SaveOrderBookSnapshotInflux(ob *dia.OrderBookSnapshot) error {
+	midPrice, err := ob.MidPrice()
+	if err != nil {
+		return err
+	}
+	spread, err := ob.Spread()
+	if err != nil {
+		return err
+	}
+	bidDepth, askDepth, err := ob.Depth(orderBookDepthBand)
+	if err != nil {
+		return err
+	}
+	bids, err := json.Marshal(ob.Bids)
+	if err != nil {
+		return err
+	}
+	asks, err := json.Marshal(ob.Asks)
+	if err != nil {
+		return err
+	}
+
+	fields := map[string]interface{}{
+		"bestBid":  ob.Bids[0].Price,
+		"bestAsk":  ob.Asks[0].Price,
+		"midPrice": midPrice,
+		"spread":   spread,
+		"bidDepth": bidDepth,
+		"askDepth": askDepth,
+		"bids":     string(bids),
+		"asks":     string(asks),
+	}
+	tags := map[string]string{
+		"exchange": ob.Source,
+		"pair":     ob.Pair,
+		"symbol":   ob.Symbol,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbOrderBooksTable, tags, fields, ob.Time)
+	if err != nil {
+		log.Errorln("NewOrderBookInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+
+	err = db.WriteBatchInflux()
+	if err != nil {
+		log.Errorln("SaveOrderBookSnapshotInflux", err)
+	}
+	return err
+}
+
+// GetOrderBookMetrics returns spread and depth of the order book of @pair on @exchange
+// for all snapshots in the time range (@starttime, @endtime], latest first.
+func (db *DB) GetOrderBookMetrics(exchange string, pair string, starttime time.Time, endtime time.Time) ([]OrderBookMetrics, error) {
+	metrics := []OrderBookMetrics{}
+
+	query := "SELECT bestBid,bestAsk,midPrice,spread,bidDepth,askDepth,\"symbol\" FROM %s WHERE \"exchange\"='%s' and \"pair\"='%s' and time>%d and time<=%d order by time desc"
+	q := fmt.Sprintf(query, influxDbOrderBooksTable, exchange, pair, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return metrics, err
+	}
+
+	if len(res) > 0 && len(res[0].Series) > 0 {
+		for _, val := range res[0].Series[0].Values {
+			m := OrderBookMetrics{
+				Exchange: exchange,
+				Pair:     pair,
+			}
+			m.Time, err = time.Parse(time.RFC3339, val[0].(string))
+			if err != nil {
+				return metrics, err
+			}
+			floats := []*float64{&m.BestBid, &m.BestAsk, &m.MidPrice, &m.Spread, &m.BidDepth, &m.AskDepth}
+			for i, f := range floats {
+				*f, err = val[i+1].(json.Number).Float64()
+				if err != nil {
+					return metrics, err
+				}
+			}
+			if symbol, ok := val[7].(string); ok {
+				m.Symbol = symbol
+			}
+			metrics = append(metrics, m)
+		}
+	}
+	return metrics, nil
+}
@@ -121,11 +121,78 @@ func (db *DB) GetQuotation(symbol string) (*Quotation, error) {
 	return value, nil
 }
 
+// SetAssetPriceUSD stores the USD price of @asset. Assets without an address are stored by symbol.
+func (db *DB) SetAssetPriceUSD(asset dia.Asset, price float64) error {
+	if !asset.HasAddress() {
+		return db.SetPriceUSD(asset.Symbol, price)
+	}
+	name := asset.Name
+	if name == "" {
+		name = helpers.NameForSymbol(asset.Symbol)
+	}
+	return db.setQuotation(asset.Identifier(), &Quotation{
+		Symbol:     asset.Symbol,
+		Name:       name,
+		Address:    asset.Address,
+		Blockchain: asset.Blockchain,
+		Price:      price,
+		Source:     dia.Diadata,
+		Time:       time.Now(),
+	})
+}
+
+// GetAssetPriceUSD returns the USD price of @asset. Assets without a quotation of their
+// own yet, and assets without an address, are priced by their symbol.
+func (db *DB) GetAssetPriceUSD(asset dia.Asset) (float64, error) {
+	if asset.HasAddress() {
+		price, err := db.GetPriceUSD(asset.Identifier())
+		if err == nil {
+			return price, nil
+		}
+	}
+	return db.GetPriceUSD(asset.Symbol)
+}
+
+// GetAssetQuotation returns the quotation of the token @asset, looked up by blockchain and
+// address. Assets without an address are looked up by symbol.
+func (db *DB) GetAssetQuotation(asset dia.Asset) (*Quotation, error) {
+	if !asset.HasAddress() {
+		return db.GetQuotation(asset.Symbol)
+	}
+	key := getKeyQuotation(asset.Identifier())
+	value := &Quotation{}
+	err := db.redisClient.Get(key).Scan(value)
+	if err != nil {
+		if err != redis.Nil {
+			log.Errorf("Error: %v on GetAssetQuotation %v\n", err, key)
+		}
+		return nil, err
+	}
+	v, err2 := db.GetPriceYesterday(asset.Identifier(), "")
+	if err2 == nil {
+		value.PriceYesterday = &v
+	}
+	v2, _ := db.GetVolume(asset.Identifier())
+	value.VolumeYesterdayUSD = v2
+	itin, err := db.GetItinBySymbol(value.Symbol)
+	if err != nil {
+		value.ITIN = "undefined"
+	} else {
+		value.ITIN = itin.Itin
+	}
+	return value, nil
+}
+
 func (db *DB) SetQuotation(quotation *Quotation) error {
+	return db.setQuotation(quotation.Symbol, quotation)
+}
+
+// setQuotation stores @quotation under the asset identifier @key.
+func (db *DB) setQuotation(key string, quotation *Quotation) error {
 	if db.redisClient == nil {
 		return nil
 	}
-	key := getKeyQuotation(quotation.Symbol)
+	key = getKeyQuotation(key)
 	log.Debug("setting ", key, quotation)
 	err := db.redisClient.Set(key, quotation, TimeOutRedis).Err()
 	if err != nil {
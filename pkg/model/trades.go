@@ -2,13 +2,18 @@ package models
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	log "github.com/sirupsen/logrus"
 )
 
+// tradeColumns are the columns of the trades table parsed by parseTrade, in this order after time.
+const tradeColumns = "estimatedUSDPrice,exchange,foreignTradeID,pair,price,symbol,volume,baseAddress,baseBlockchain,quoteAddress,quoteBlockchain"
+
 func parseTrade(row []interface{}) *dia.Trade {
 	if len(row) > 7 {
 		t, err := time.Parse(time.RFC3339, row[0].(string))
@@ -67,6 +72,19 @@ func parseTrade(row []interface{}) *dia.Trade {
 				Volume:            volume,
 				ForeignTradeID:    foreignTradeID,
 			}
+			// assets are only tagged on trades of tokens with an address
+			if len(row) > 11 {
+				trade.BaseAsset.Address, _ = row[8].(string)
+				trade.BaseAsset.Blockchain, _ = row[9].(string)
+				trade.QuoteAsset.Address, _ = row[10].(string)
+				trade.QuoteAsset.Blockchain, _ = row[11].(string)
+				if trade.QuoteAsset.HasAddress() {
+					trade.QuoteAsset.Symbol = symbol
+				}
+				if trade.BaseAsset.HasAddress() {
+					trade.BaseAsset.Symbol = trade.BaseToken()
+				}
+			}
 			return &trade
 		}
 		log.Errorln("Parsing ", t)
@@ -78,8 +96,7 @@ func parseTrade(row []interface{}) *dia.Trade {
 // GetAllTrades returns at most @maxTrades trades from influx with timestamp > @t. Only used by replayInflux option.
 func (db *DB) GetAllTrades(t time.Time, maxTrades int) ([]dia.Trade, error) {
 	r := []dia.Trade{}
-	// TO DO: Substitute select * with precise statment select estimatedUSDPrice, source,...
-	q := fmt.Sprintf("SELECT * FROM %s WHERE time > %d LIMIT %d", influxDbTradesTable, t.Unix()*1000000000, maxTrades)
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE time > %d LIMIT %d", tradeColumns, influxDbTradesTable, t.Unix()*1000000000, maxTrades)
 	log.Debug(q)
 	res, err := queryInfluxDB(db.influxClient, q)
 	if err != nil {
@@ -99,9 +116,32 @@ func (db *DB) GetAllTrades(t time.Time, maxTrades int) ([]dia.Trade, error) {
 	return r, nil
 }
 
+// GetTradesInflux returns the trades of @symbols, or of all symbols if empty, in the time range [@starttime, @endtime) ordered by time.
+func (db *DB) GetTradesInflux(symbols []string, starttime time.Time, endtime time.Time) ([]dia.Trade, error) {
+	r := []dia.Trade{}
+	symbolQuery := ""
+	if len(symbols) > 0 {
+		symbolQuery = "and (symbol='" + strings.Join(symbols, "' or symbol='") + "') "
+	}
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE time>=%d and time<%d %sORDER BY ASC", tradeColumns, influxDbTradesTable, starttime.UnixNano(), endtime.UnixNano(), symbolQuery)
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return r, err
+	}
+	if len(res) > 0 && len(res[0].Series) > 0 {
+		for _, row := range res[0].Series[0].Values {
+			t := parseTrade(row)
+			if t != nil {
+				r = append(r, *t)
+			}
+		}
+	}
+	return r, nil
+}
+
 func (db *DB) GetLastTrades(symbol string, exchange string, maxTrades int) ([]dia.Trade, error) {
 	r := []dia.Trade{}
-	q := fmt.Sprintf("SELECT * FROM %s WHERE exchange='%s' and symbol='%s' ORDER BY DESC LIMIT %d", influxDbTradesTable, exchange, symbol, maxTrades)
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE exchange='%s' and symbol='%s' ORDER BY DESC LIMIT %d", tradeColumns, influxDbTradesTable, exchange, symbol, maxTrades)
 	res, err := queryInfluxDB(db.influxClient, q)
 	if err != nil {
 		log.Errorln("GetLastTrades", err)
@@ -123,7 +163,7 @@ func (db *DB) GetLastTrades(symbol string, exchange string, maxTrades int) ([]di
 
 func (db *DB) GetLastTradesAllExchanges(symbol string, maxTrades int) ([]dia.Trade, error) {
 	r := []dia.Trade{}
-	q := fmt.Sprintf("SELECT * FROM %s WHERE symbol='%s' ORDER BY DESC LIMIT %d", influxDbTradesTable, symbol, maxTrades)
+	q := fmt.Sprintf("SELECT %s FROM %s WHERE symbol='%s' ORDER BY DESC LIMIT %d", tradeColumns, influxDbTradesTable, symbol, maxTrades)
 	res, err := queryInfluxDB(db.influxClient, q)
 	if err != nil {
 		log.Errorln("GetLastTrades", err)
@@ -142,3 +182,16 @@ func (db *DB) GetLastTradesAllExchanges(symbol string, maxTrades int) ([]dia.Tra
 	}
 	return r, nil
 }
+
+func getKeyTradeSeen(t *dia.Trade) string {
+	return "dia_trade_seen_" + t.Source + "_" + t.Pair + "_" + t.ForeignTradeID
+}
+
+// MarkTradeSeen records @t for @window. It returns false if a trade with the same
+// ForeignTradeID on the same pair and exchange was recorded within the window before.
+func (db *DB) MarkTradeSeen(t *dia.Trade, window time.Duration) (bool, error) {
+	if db.redisClient == nil {
+		return true, errors.New("no redis client")
+	}
+	return db.redisClient.SetNX(getKeyTradeSeen(t), 1, window).Result()
+}
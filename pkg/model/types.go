@@ -30,6 +30,8 @@ type SymbolShort struct {
 type Quotation struct {
 	Symbol             string
 	Name               string
+	Address            string `json:",omitempty"`
+	Blockchain         string `json:",omitempty"`
 	Price              float64
 	PriceYesterday     *float64
 	VolumeYesterdayUSD *float64
@@ -50,6 +52,21 @@ type StockQuotation struct {
 	ISIN       string
 }
 
+// OrderBookMetrics contains liquidity figures derived from an order book snapshot.
+// Spread is relative to MidPrice, depths are given in units of Symbol.
+type OrderBookMetrics struct {
+	Exchange string
+	Pair     string
+	Symbol   string
+	BestBid  float64
+	BestAsk  float64
+	MidPrice float64
+	Spread   float64
+	BidDepth float64
+	AskDepth float64
+	Time     time.Time
+}
+
 type Stock struct {
 	Symbol string
 	Name   string
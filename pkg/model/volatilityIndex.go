@@ -0,0 +1,99 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+	log "github.com/sirupsen/logrus"
+)
+
+// GetOptionOrderbookData returns the latest order book datum of each option in the time range (@starttime, @endtime].
+func (db *DB) GetOptionOrderbookData(starttime time.Time, endtime time.Time) ([]dia.OptionOrderbookDatum, error) {
+	retval := []dia.OptionOrderbookDatum{}
+	query := "SELECT askPrice,bidPrice,askSize,bidSize FROM %s WHERE time>%d and time<=%d group by \"instrumentName\" order by time desc limit 1"
+	q := fmt.Sprintf(query, influxDbOptionsTable, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return retval, err
+	}
+	if len(res) == 0 {
+		return retval, nil
+	}
+	for _, series := range res[0].Series {
+		if len(series.Values) == 0 {
+			continue
+		}
+		row := series.Values[0]
+		datum := dia.OptionOrderbookDatum{InstrumentName: series.Tags["instrumentName"]}
+		datum.ObservationTime, err = time.Parse(time.RFC3339, row[0].(string))
+		if err != nil {
+			return retval, err
+		}
+		for i, v := range []*float64{&datum.AskPrice, &datum.BidPrice, &datum.AskSize, &datum.BidSize} {
+			if row[i+1] == nil {
+				continue
+			}
+			if *v, err = row[i+1].(json.Number).Float64(); err != nil {
+				return retval, err
+			}
+		}
+		retval = append(retval, datum)
+	}
+	return retval, nil
+}
+
+// SaveVolatilityIndexInflux writes a value of a volatility index to influx.
+func (db *DB) SaveVolatilityIndexInflux(v dia.VolatilityIndexPoint) error {
+	tags := map[string]string{
+		"underlying": v.Underlying,
+		"maturity":   strconv.Itoa(v.Maturity),
+		"label":      v.Label,
+	}
+	fields := map[string]interface{}{
+		"value": v.Value,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbVolatilityIndexTable, tags, fields, v.Timestamp)
+	if err != nil {
+		log.Errorln("NewVolatilityIndexInflux:", err)
+	} else {
+		db.addPoint(pt)
+	}
+
+	err = db.WriteBatchInflux()
+	if err != nil {
+		log.Errorln("SaveVolatilityIndexInflux", err)
+	}
+	return err
+}
+
+// GetVolatilityIndexInflux returns the values of the volatility index of @underlying at a maturity of @maturity days,
+// computed with methodology @label, in the time range (@starttime, @endtime].
+func (db *DB) GetVolatilityIndexInflux(underlying string, maturity int, label string, starttime time.Time, endtime time.Time) ([]dia.VolatilityIndexPoint, error) {
+	retval := []dia.VolatilityIndexPoint{}
+	query := "SELECT value FROM %s WHERE underlying='%s' and maturity='%d' and label='%s' and time>%d and time<=%d"
+	q := fmt.Sprintf(query, influxDbVolatilityIndexTable, underlying, maturity, label, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(db.influxClient, q)
+	if err != nil {
+		return retval, err
+	}
+	if len(res) == 0 || len(res[0].Series) == 0 {
+		return retval, nil
+	}
+	for _, row := range res[0].Series[0].Values {
+		point := dia.VolatilityIndexPoint{Underlying: underlying, Maturity: maturity, Label: label}
+		point.Timestamp, err = time.Parse(time.RFC3339, row[0].(string))
+		if err != nil {
+			return retval, err
+		}
+		point.Value, err = row[1].(json.Number).Float64()
+		if err != nil {
+			return retval, err
+		}
+		retval = append(retval, point)
+	}
+	return retval, nil
+}
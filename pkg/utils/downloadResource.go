@@ -21,11 +21,14 @@ func DownloadResource(filepath, url string) error {
 
 	log.Printf("Downloading data")
 
-	resp, err := http.Get(url)
+	resp, err := SharedHTTPClient.Get(url)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("HTTP Response Error %d\n", resp.StatusCode)
+	}
 
 	// Create the file
 	out, err := os.Create(filepath)
@@ -44,7 +47,7 @@ func DownloadResource(filepath, url string) error {
 func GetRequest(url string) ([]byte, error) {
 
 	// Get url
-	response, err := http.Get(url)
+	response, err := SharedHTTPClient.Get(url)
 
 	// Check, whether the request was successful
 	if err != nil {
@@ -72,17 +75,17 @@ func GetRequest(url string) ([]byte, error) {
 	return XMLdata, err
 }
 
-// GetRequest performs a get request on @url and returns the response body
-// as a slice of byte data.
+// GetRequestWithStatus performs a get request on @url and returns the response body
+// as a slice of byte data and the status code.
 func GetRequestWithStatus(url string) ([]byte, int, error) {
 
 	// Get url
-	response, err := http.Get(url)
+	response, err := SharedHTTPClient.Get(url)
 
 	// Check, whether the request was successful
 	if err != nil {
 		log.Error(err)
-		return []byte{}, response.StatusCode, err
+		return []byte{}, 0, err
 	}
 
 	// Close response body after function
@@ -104,7 +107,7 @@ func GetRequestWithStatus(url string) ([]byte, int, error) {
 func PostRequest(url string, body io.Reader) ([]byte, error) {
 
 	// Get url
-	response, err := http.Post(url, "", body)
+	response, err := SharedHTTPClient.Post(url, "", body)
 
 	// Check, whether the request was successful
 	if err != nil {
@@ -148,12 +151,12 @@ func GraphQLGet(url string, query []byte, bearer string) ([]byte, error) {
 	// Add authorization bearer to header
 	req.Header.Add("Authorization", bearer)
 
-	// Send request using http Client
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// Send request using the shared client
+	resp, err := SharedHTTPClient.Do(req)
 	if err != nil {
 		return []byte{}, err
 	}
+	defer resp.Body.Close()
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return []byte{}, err
@@ -187,7 +190,6 @@ func OpenseaGetRequest(OpenseaURL string) ([]byte, int, error) {
 		log.Fatal("Secrets file for opensea API key should have exactly one line")
 	}
 	apiKey := lines[0]
-	client := &http.Client{}
 	req, err := http.NewRequest("GET", OpenseaURL, nil)
 	if err != nil {
 		log.Print(err)
@@ -199,10 +201,10 @@ func OpenseaGetRequest(OpenseaURL string) ([]byte, int, error) {
 	req.Header.Add("X-API-KEY", apiKey)
 	req.URL.RawQuery = q.Encode()
 
-	resp, err := client.Do(req)
+	resp, err := SharedHTTPClient.Do(req)
 	if err != nil {
-		fmt.Println("Error sending request to server")
-		os.Exit(1)
+		log.Error("send request to opensea: ", err)
+		return []byte{}, 0, err
 	}
 	defer resp.Body.Close()
 	respData, err := ioutil.ReadAll(resp.Body)
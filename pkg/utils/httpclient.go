@@ -0,0 +1,388 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+// ErrCircuitOpen is returned for requests to a host which failed too often recently.
+var ErrCircuitOpen = errors.New("circuit open after repeated failures")
+
+// HostPolicy configures the requests to a host.
+type HostPolicy struct {
+	// token bucket refilled with RequestsPerSecond tokens, at most Burst. 0 disables the limit.
+	RequestsPerSecond float64
+	Burst             int
+	// maximal number of requests waiting for a response. 0 disables the limit.
+	MaxConcurrent int
+	// failed requests, i.e. network errors, 429 and 5xx responses, are retried MaxRetries
+	// times after an exponential backoff starting at BackoffBase. Retry-After is respected.
+	MaxRetries  int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// after BreakerThreshold consecutive failures, requests fail with ErrCircuitOpen
+	// for BreakerCooldown. 0 disables the circuit breaker.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+	Timeout          time.Duration
+}
+
+// DefaultHostPolicy applies to all hosts without a policy of their own. It only retries failed
+// requests, as limits depend on the host; they are set with SetHostPolicy or HTTP_HOST_POLICIES.
+var DefaultHostPolicy = HostPolicy{
+	MaxRetries:  3,
+	BackoffBase: 500 * time.Millisecond,
+	BackoffMax:  30 * time.Second,
+}
+
+// HostStats are the request metrics of a host. The metrics of all clients are also
+// exported to prometheus, see pkg/dia/helpers/metrics.
+type HostStats struct {
+	Requests    int64 // requests sent, including retries
+	Retries     int64
+	Failures    int64 // network errors, 429 and 5xx responses
+	RateLimited int64 // 429 responses
+	Rejected    int64 // requests failed with ErrCircuitOpen
+	Latency     time.Duration
+}
+
+// HTTPClient is an http client which limits, retries and circuit breaks the
+// requests to each host according to its HostPolicy. It is safe for concurrent use.
+type HTTPClient struct {
+	client        *http.Client
+	defaultPolicy HostPolicy
+
+	mu       sync.Mutex
+	policies map[string]HostPolicy
+	hosts    map[string]*hostState
+}
+
+type hostState struct {
+	name    string
+	policy  HostPolicy
+	limiter *rate.Limiter
+	slots   chan struct{}
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	// no requests are sent before pauseUntil, set by Retry-After
+	pauseUntil time.Time
+	stats      HostStats
+}
+
+// SharedHTTPClient is used by the request functions of this package, so that all
+// scrapers of a process share the limits of a host. Policies of hosts can be set in a
+// json file mapping hosts to HostPolicy, given by the environment variable HTTP_HOST_POLICIES.
+var SharedHTTPClient = NewHTTPClient(DefaultHostPolicy)
+
+func init() {
+	if filename := os.Getenv("HTTP_HOST_POLICIES"); filename != "" {
+		err := SharedHTTPClient.LoadHostPolicies(filename)
+		if err != nil {
+			log.Errorf("load host policies from %s: %v", filename, err)
+		}
+	}
+}
+
+// NewHTTPClient returns a client applying @defaultPolicy to all hosts without a policy of their own.
+func NewHTTPClient(defaultPolicy HostPolicy) *HTTPClient {
+	return &HTTPClient{
+		client:        &http.Client{},
+		defaultPolicy: defaultPolicy,
+		policies:      make(map[string]HostPolicy),
+		hosts:         make(map[string]*hostState),
+	}
+}
+
+// SetHostPolicy sets the policy of @host, e.g. "api.coingecko.com".
+func (c *HTTPClient) SetHostPolicy(host string, policy HostPolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.policies[host] = policy
+	delete(c.hosts, host)
+}
+
+// LoadHostPolicies sets the policies of the json file @filename, which maps hosts to policies.
+func (c *HTTPClient) LoadHostPolicies(filename string) error {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	policies := make(map[string]HostPolicy)
+	err = json.Unmarshal(data, &policies)
+	if err != nil {
+		return err
+	}
+	for host, policy := range policies {
+		c.SetHostPolicy(host, policy)
+	}
+	return nil
+}
+
+// Stats returns the request metrics per host.
+func (c *HTTPClient) Stats() map[string]HostStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := make(map[string]HostStats, len(c.hosts))
+	for host, h := range c.hosts {
+		h.mu.Lock()
+		stats[host] = h.stats
+		h.mu.Unlock()
+	}
+	return stats
+}
+
+func (c *HTTPClient) host(name string) *hostState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	h, ok := c.hosts[name]
+	if ok {
+		return h
+	}
+	policy, ok := c.policies[name]
+	if !ok {
+		policy = c.defaultPolicy
+	}
+	h = &hostState{name: name, policy: policy, limiter: rate.NewLimiter(rate.Inf, 0)}
+	if policy.RequestsPerSecond > 0 {
+		burst := policy.Burst
+		if burst < 1 {
+			burst = 1
+		}
+		h.limiter = rate.NewLimiter(rate.Limit(policy.RequestsPerSecond), burst)
+	}
+	if policy.MaxConcurrent > 0 {
+		h.slots = make(chan struct{}, policy.MaxConcurrent)
+	}
+	c.hosts[name] = h
+	return h
+}
+
+// Get performs a GET request on @url.
+func (c *HTTPClient) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+// Post performs a POST request on @url. Failed requests are sent again, so @body is read in advance.
+func (c *HTTPClient) Post(url string, contentType string, body io.Reader) (*http.Response, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = ioutil.ReadAll(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequest("POST", url, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.Do(req)
+}
+
+// Do sends @req according to the policy of its host. Like http.Client.Do, responses with
+// an error status are returned without error once the retries are exhausted. Requests
+// with a body are only retried if the body can be read again, see http.Request.GetBody.
+func (c *HTTPClient) Do(req *http.Request) (*http.Response, error) {
+	h := c.host(req.URL.Host)
+	ctx := req.Context()
+	retryable := req.Body == nil || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		if err := h.allow(); err != nil {
+			return nil, err
+		}
+		if wait := h.pause(); wait > 0 {
+			if err := sleepContext(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
+		if err := h.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := h.send(c.client, req)
+		failed := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		h.record(resp, err, failed)
+		if !failed {
+			return resp, nil
+		}
+		if attempt >= h.policy.MaxRetries || !retryable {
+			return resp, err
+		}
+
+		wait := backoff(h.policy, attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+				h.pauseFor(wait)
+			}
+			resp.Body.Close()
+		}
+		log.Warnf("request to %s failed, retrying in %v: %v", req.URL.Host, wait, failure(resp, err))
+		h.mu.Lock()
+		h.stats.Retries++
+		h.mu.Unlock()
+		metrics.HTTPRetries.WithLabelValues(h.name).Inc()
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send performs a single request, holding one of the host's slots until the response headers arrive.
+func (h *hostState) send(client *http.Client, req *http.Request) (*http.Response, error) {
+	if h.slots != nil {
+		select {
+		case h.slots <- struct{}{}:
+			defer func() { <-h.slots }()
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		}
+	}
+	c := client
+	if h.policy.Timeout > 0 {
+		c = &http.Client{Transport: client.Transport, Timeout: h.policy.Timeout}
+	}
+	start := time.Now()
+	resp, err := c.Do(req)
+	latency := time.Since(start)
+	h.mu.Lock()
+	h.stats.Requests++
+	h.stats.Latency += latency
+	h.mu.Unlock()
+	metrics.HTTPRequests.WithLabelValues(h.name).Inc()
+	metrics.HTTPLatency.WithLabelValues(h.name).Observe(latency.Seconds())
+	return resp, err
+}
+
+// allow returns ErrCircuitOpen while the circuit of the host is open.
+func (h *hostState) allow() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if time.Now().Before(h.openUntil) {
+		h.stats.Rejected++
+		metrics.HTTPFailures.WithLabelValues(h.name, metrics.HTTPCircuitOpen).Inc()
+		return ErrCircuitOpen
+	}
+	return nil
+}
+
+// record updates the failure count and the metrics of the host after a request.
+func (h *hostState) record(resp *http.Response, err error, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+		h.stats.RateLimited++
+	}
+	if !failed {
+		h.failures = 0
+		return
+	}
+	switch {
+	case err != nil:
+		metrics.HTTPFailures.WithLabelValues(h.name, metrics.HTTPError).Inc()
+	case resp.StatusCode == http.StatusTooManyRequests:
+		metrics.HTTPFailures.WithLabelValues(h.name, metrics.HTTPRateLimited).Inc()
+	default:
+		metrics.HTTPFailures.WithLabelValues(h.name, metrics.HTTPServerError).Inc()
+	}
+	h.stats.Failures++
+	h.failures++
+	if h.policy.BreakerThreshold > 0 && h.failures >= h.policy.BreakerThreshold {
+		h.openUntil = time.Now().Add(h.policy.BreakerCooldown)
+		log.Errorf("%d consecutive failed requests, pausing for %v: %v", h.failures, h.policy.BreakerCooldown, failure(resp, err))
+	}
+}
+
+func (h *hostState) pause() time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return time.Until(h.pauseUntil)
+}
+
+func (h *hostState) pauseFor(d time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if until := time.Now().Add(d); until.After(h.pauseUntil) {
+		h.pauseUntil = until
+	}
+}
+
+// backoff returns the wait before retry number @attempt+1, with full jitter.
+func backoff(policy HostPolicy, attempt int) time.Duration {
+	d := policy.BackoffBase << uint(attempt)
+	if d <= 0 || (policy.BackoffMax > 0 && d > policy.BackoffMax) {
+		d = policy.BackoffMax
+	}
+	if d <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(d))) + 1
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as http date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t), true
+	}
+	return 0, false
+}
+
+func failure(resp *http.Response, err error) interface{} {
+	if err != nil {
+		return err
+	}
+	return resp.Status
+}
+
+func sleepContext(ctx interface {
+	Done() <-chan struct{}
+	Err() error
+}, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,106 @@
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia/helpers/metrics"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func testPolicy() HostPolicy {
+	return HostPolicy{
+		MaxRetries:  2,
+		BackoffBase: time.Millisecond,
+		BackoffMax:  10 * time.Millisecond,
+	}
+}
+
+func TestHTTPClientRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	c := NewHTTPClient(testPolicy())
+	start := time.Now()
+	resp, err := c.Post(server.URL, "text/plain", strings.NewReader("body"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("got status %d, want 200", resp.StatusCode)
+	}
+	if time.Since(start) < time.Second {
+		t.Error("Retry-After not respected")
+	}
+
+	u, _ := url.Parse(server.URL)
+	stats := c.Stats()[u.Host]
+	if stats.Requests != 3 || stats.Retries != 2 || stats.Failures != 2 || stats.RateLimited != 1 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+	if n := testutil.ToFloat64(metrics.HTTPRetries.WithLabelValues(u.Host)); n != 2 {
+		t.Errorf("exported %v retries, want 2", n)
+	}
+	if n := testutil.ToFloat64(metrics.HTTPFailures.WithLabelValues(u.Host, metrics.HTTPRateLimited)); n != 1 {
+		t.Errorf("exported %v rate limited requests, want 1", n)
+	}
+}
+
+func TestHTTPClientCircuitBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	policy := testPolicy()
+	policy.BreakerThreshold = 3
+	policy.BreakerCooldown = time.Hour
+	c := NewHTTPClient(policy)
+
+	resp, err := c.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("got status %d after exhausted retries, want 500", resp.StatusCode)
+	}
+	if _, err = c.Get(server.URL); err != ErrCircuitOpen {
+		t.Errorf("got error %v, want ErrCircuitOpen", err)
+	}
+	if calls != 3 {
+		t.Errorf("server called %d times, want 3", calls)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if d, ok := parseRetryAfter("120"); !ok || d != 2*time.Minute {
+		t.Errorf("got %v, %v for seconds", d, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, ok := parseRetryAfter(date); !ok || d < 59*time.Minute || d > time.Hour {
+		t.Errorf("got %v, %v for http date", d, ok)
+	}
+	if _, ok := parseRetryAfter("soon"); ok {
+		t.Error("invalid header parsed")
+	}
+}